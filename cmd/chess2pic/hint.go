@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xopoww/chess2pic/internal/chess2pic"
+	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/chess/engine"
+)
+
+// hintMain suggests a move in the position with the built-in engine and draws it as an arrow.
+func hintMain(cmdArgs []string) {
+	fs := flag.NewFlagSet("hint", flag.ExitOnError)
+	fen := fs.String("fen", "", "position in FEN notation (the side to move is taken into account)")
+	output := fs.String("out", defaultOutName+".png", "output file name")
+	fromName := fs.String("from", "", "from which player's perspective (\"white\" or \"black\") to draw (default: the side to move)")
+	depth := fs.Int("depth", 0, "maximum search depth in plies (0 means no limit)")
+	moveTime := fs.Duration("movetime", engine.DefaultMoveTime, "maximum search time")
+	var images collectionArgs
+	images.register(fs)
+	fs.BoolVar(&chess2pic.DEBUG, "debug", false, "enable debug output")
+	fs.Parse(cmdArgs)
+	col := images.load()
+
+	if *fen == "" {
+		chess2pic.Fatalf("--fen is required")
+	}
+	st, err := chess.ParseFEN(strings.NewReader(*fen))
+	if err != nil {
+		var perr chess.ParseError
+		if errors.As(err, &perr) {
+			printParseError("<fen>", perr, false)
+			os.Exit(1)
+		}
+		chess2pic.Fatalf("%s", err)
+	}
+	if len(st.LegalMoves()) == 0 {
+		chess2pic.Fatalf("no legal moves in the position")
+	}
+	from := st.ToMove
+	if *fromName != "" {
+		if from, err = parseColor(*fromName); err != nil {
+			chess2pic.Fatalf("invalid --from value: %q", *fromName)
+		}
+	}
+
+	out, err := os.Create(*output)
+	if err != nil {
+		chess2pic.Fatalf("error creating %q: %s", *output, err)
+	}
+	defer out.Close()
+
+	start := time.Now()
+	hint, err := chess2pic.HandleHint(context.Background(), strings.NewReader(*fen), out, col, from,
+		engine.Limits{Depth: *depth, MoveTime: *moveTime})
+	if err != nil {
+		chess2pic.Fatalf("%s", err)
+	}
+	chess2pic.Debugf("Search took %s", time.Since(start))
+	fmt.Printf("%s (%s)\n", hint.SAN, hint.Eval)
+}
@@ -2,14 +2,19 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/xopoww/chess2pic/internal/chess2pic"
 	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/chess/polyglot"
+	"github.com/xopoww/chess2pic/pkg/chess/uci"
 	"github.com/xopoww/chess2pic/pkg/pic"
 )
 
@@ -23,6 +28,51 @@ var args struct {
 	output string
 
 	from string
+
+	book     string
+	fastBook bool
+
+	evalBar     bool
+	evalGraph   string
+	engine      string
+	engineDepth int
+	annotate    bool
+
+	motifs  bool
+	heatmap bool
+
+	header  bool
+	caption bool
+
+	narration string
+	transform string
+	coords    string
+
+	highlight      bool
+	lastMove       string
+	highlightColor string
+	checkColor     string
+
+	format   string
+	pieceURL string
+	images   collectionArgs
+
+	arrows  listFlag
+	circles listFlag
+	fills   listFlag
+	badges  listFlag
+}
+
+// listFlag collects the values of a flag that may be repeated.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
 }
 
 func init() {
@@ -41,23 +91,91 @@ func init() {
 		"from which player's perspective (\"white\" or \"black\") to draw",
 	)
 
+	flag.StringVar(&args.book, "book", "", "Polyglot opening book file to detect book moves in PGN games")
+	flag.BoolVar(&args.fastBook, "fast-book", false, "speed through book moves in PGN animations (requires -book)")
+
+	flag.BoolVar(&args.evalBar, "eval-bar", false, "draw an evaluation bar beside the board in PGN animations")
+	flag.StringVar(&args.evalGraph, "eval-graph", "", "output file name for a PNG chart of the evaluation over a PGN game")
+	flag.StringVar(&args.engine, "engine", "",
+		"UCI engine binary to evaluate the positions (by default evaluations are taken from [%eval] comments)",
+	)
+	flag.IntVar(&args.engineDepth, "engine-depth", chess2pic.DefaultEngineDepth, "search depth of the engine")
+	flag.BoolVar(&args.annotate, "annotate", false,
+		"mark inaccuracies, mistakes and blunders in PGN animations and print them (uses -engine or the built-in engine)",
+	)
+
+	flag.BoolVar(&args.motifs, "motifs", false,
+		"mark tactical motifs of the side to move (pins, forks, skewers, discovered attacks, hanging pieces) in FEN images",
+	)
+
+	flag.BoolVar(&args.heatmap, "heatmap", false,
+		"tint the squares by the side that controls them (the more attackers, the stronger the tint)",
+	)
+
+	flag.BoolVar(&args.header, "header", false,
+		"draw a band over the board with the players, their ratings, the event and the date in PGN animations",
+	)
+	flag.BoolVar(&args.caption, "caption", false, "draw a band under the board with the last move in PGN animations")
+
+	flag.StringVar(&args.narration, "narration", "", "output file name for an English description of every move of a PGN game")
+
+	flag.StringVar(&args.transform, "transform", "identity",
+		"transform the position or the game before drawing (\"identity\", \"mirror\", \"flip\" or \"rotate\")",
+	)
+
+	flag.StringVar(&args.coords, "coords", "none",
+		"draw the file letters and the rank numbers (\"none\", \"inside\" the edge squares or in a \"margin\" around the board)",
+	)
+
+	flag.BoolVar(&args.highlight, "highlight", false, "highlight the last move and the king in check")
+	flag.StringVar(&args.lastMove, "last-move", "",
+		"squares of the move that led to the FEN position (e.g. \"e2e4\"), highlighted as with -highlight",
+	)
+	flag.StringVar(&args.highlightColor, "highlight-color", "",
+		"color of the last move highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of -theme)",
+	)
+	flag.StringVar(&args.checkColor, "check-color", "",
+		"color of the king in check highlight, a name or \"#rrggbb[aa]\" (by default the one of -theme)",
+	)
+
+	flag.StringVar(&args.format, "format", "png", "format of FEN images (\"png\" or \"svg\")")
+	flag.StringVar(&args.pieceURL, "piece-url", "",
+		"reference the piece images from SVG images by this URL template instead of embedding them, "+
+			"{color}, {kind} and {code} are replaced with e.g. \"white\", \"king\" and \"wK\"",
+	)
+	args.images.register(flag.CommandLine)
+
+	flag.Var(&args.arrows, "arrow", "draw an arrow in the FEN image (e.g. \"e2e4\" or \"g1f3:red\", may be repeated)")
+	flag.Var(&args.circles, "mark", "draw a circle around a square in the FEN image (e.g. \"d5:red\", may be repeated)")
+	flag.Var(&args.fills, "fill", "fill a square in the FEN image (e.g. \"d5:yellow\", may be repeated)")
+	flag.Var(&args.badges, "badge", "draw a text badge on a square in the FEN image (e.g. \"e4:!!:blue\", may be repeated)")
+
 	flag.BoolVar(&chess2pic.DEBUG, "debug", false, "enable debug output")
 }
 
+// commands are run as "chess2pic <command> [flags]", without a command the notation is converted to a picture
+var commands = map[string]func(args []string){
+	"hint":   hintMain,
+	"solve":  solveMain,
+	"random": randomMain,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if args.notation == "" {
 		chess2pic.Fatalf("--notation is required")
 	}
 
-	var from chess.PieceColor
-	switch args.from {
-	case "white":
-		from = chess.White
-	case "black":
-		from = chess.Black
-	default:
+	from, err := parseColor(args.from)
+	if err != nil {
 		chess2pic.Fatalf("invalid --from value: %q", args.from)
 	}
 
@@ -73,10 +191,89 @@ func main() {
 		in = strings.NewReader(args.data)
 	}
 
+	transform, err := chess.ParseTransform(args.transform)
+	if err != nil {
+		chess2pic.Fatalf("invalid --transform value: %q", args.transform)
+	}
+
+	coords, err := pic.ParseCoordinates(args.coords)
+	if err != nil {
+		chess2pic.Fatalf("invalid --coords value: %q", args.coords)
+	}
+
+	col := args.images.load()
+	if args.highlightColor != "" || args.checkColor != "" {
+		if !args.highlight && args.lastMove == "" {
+			chess2pic.Fatalf("--highlight-color and --check-color require --highlight or --last-move")
+		}
+		h, err := chess2pic.ParseHighlights(args.highlightColor, args.checkColor)
+		if err != nil {
+			chess2pic.Fatalf("%s", err)
+		}
+		col = pic.WithHighlights(col, h)
+	}
+
+	format, err := chess2pic.ParseFormat(args.format)
+	if err != nil {
+		chess2pic.Fatalf("invalid --format value: %q", args.format)
+	}
+	if format != chess2pic.PNG && args.notation != "fen" {
+		chess2pic.Fatalf("--format %s is supported only for FEN", format)
+	}
+
+	fenOpts := chess2pic.FENOptions{
+		Format:      format,
+		Motifs:      args.motifs,
+		Heatmap:     args.heatmap,
+		Transform:   transform,
+		Coordinates: coords,
+		Highlight:   args.highlight || args.lastMove != "",
+	}
+	fenOpts.Annotations, err = chess2pic.ParseAnnotations(args.arrows, args.circles, args.fills, args.badges)
+	if err != nil {
+		chess2pic.Fatalf("%s", err)
+	}
+	if args.pieceURL != "" {
+		if format != chess2pic.SVG {
+			chess2pic.Fatalf("--piece-url requires --format svg")
+		}
+		if fenOpts.PieceURL, err = pic.PieceURLTemplate(args.pieceURL); err != nil {
+			chess2pic.Fatalf("invalid --piece-url value: %s", err)
+		}
+	}
+	if args.lastMove != "" {
+		if fenOpts.LastMove, err = chess2pic.ParseLastMove(args.lastMove); err != nil {
+			chess2pic.Fatalf("invalid --last-move value: %q", args.lastMove)
+		}
+	}
+
+	pgnOpts := chess2pic.PGNOptions{FastBook: args.fastBook, Transform: transform, Coordinates: coords, Highlight: args.highlight}
+	if args.book != "" {
+		f, err := os.Open(args.book)
+		if err != nil {
+			chess2pic.Fatalf("error opening %q: %s", args.book, err)
+		}
+		pgnOpts.Book, err = polyglot.ReadBook(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			chess2pic.Fatalf("error reading book %q: %s", args.book, err)
+		}
+	}
+
+	pgnOpts.EvalBar = args.evalBar
+	pgnOpts.Heatmap = args.heatmap
+	pgnOpts.Header = args.header
+	pgnOpts.Caption = args.caption
+	pgnOpts.Annotate = args.annotate
+	if args.annotate {
+		pgnOpts.Annotations = os.Stdout
+	}
+	pgnOpts.EngineLimits = uci.Limits{Depth: args.engineDepth}
+
 	if args.output == "" {
 		switch args.notation {
 		case "fen":
-			args.output = defaultOutName + ".png"
+			args.output = defaultOutName + "." + format.String()
 		case "pgn":
 			args.output = defaultOutName + ".gif"
 		}
@@ -88,13 +285,189 @@ func main() {
 
 	switch args.notation {
 	case "fen":
-		err = chess2pic.HandleFEN(in, out, pic.DefaultCollection, from)
+		err = chess2pic.HandleFEN(in, out, col, from, fenOpts)
 	case "pgn":
-		err = chess2pic.HandlePGN(in, out, pic.DefaultCollection, from)
+		err = handlePGN(in, out, col, from, pgnOpts)
 	default:
 		err = fmt.Errorf("unknown notation: %q", args.notation)
 	}
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("error writing %q: %w", args.output, cerr)
+	}
 	if err != nil {
-		chess2pic.Fatalf(err.Error())
+		var partial chess2pic.PartialError
+		isPartial := errors.As(err, &partial)
+
+		var perr chess.ParseError
+		if errors.As(err, &perr) {
+			name := args.input
+			if name == "" {
+				name = "<data>"
+			}
+			printParseError(name, perr, isPartial)
+		} else {
+			chess2pic.Infof(err.Error())
+		}
+
+		if !isPartial {
+			os.Exit(1)
+		}
+		chess2pic.Infof("rendered only %d moves to %q", partial.Moves, args.output)
+	}
+}
+
+// handlePGN starts the engine and creates the narration and the evaluation graph files if they are requested,
+// renders the game and then releases them, so that they are closed even if it fails.
+func handlePGN(in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor, opts chess2pic.PGNOptions) error {
+	if args.engine != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		engine, err := uci.Start(ctx, args.engine)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error starting engine %q: %w", args.engine, err)
+		}
+		defer engine.Close()
+		opts.Engine = engine
+		chess2pic.Debugf("Engine: %s", opts.Engine.Name)
+	}
+	if args.narration != "" {
+		f, err := os.Create(args.narration)
+		if err != nil {
+			return fmt.Errorf("error creating %q: %w", args.narration, err)
+		}
+		defer f.Close()
+		opts.Narration = f
+	}
+	if args.evalGraph != "" {
+		f, err := os.Create(args.evalGraph)
+		if err != nil {
+			return fmt.Errorf("error creating %q: %w", args.evalGraph, err)
+		}
+		defer f.Close()
+		opts.EvalGraph = f
+	}
+	return chess2pic.HandlePGN(in, out, col, from, opts)
+}
+
+// collectionArgs are the flags that select the images of the board and the pieces.
+type collectionArgs struct {
+	collection  string
+	pieces      string
+	pieceSet    string
+	spriteOrder string
+	theme       string
+	size        int
+	fitBoard    bool
+}
+
+func (a *collectionArgs) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.collection, "collection", "",
+		"directory or zip archive with the board and the piece images (board_white.png, board_black.png, white/pawn.png, ...)",
+	)
+	fs.StringVar(&a.pieces, "pieces", "",
+		"directory or zip archive with the piece images (white/pawn.png, ..., black/king.png) or PNG sprite sheet "+
+			"of the pieces to draw on the board of -collection",
+	)
+	fs.StringVar(&a.spriteOrder, "sprite-order", "",
+		"order of the pieces on the sprite sheet of -pieces by their FEN letters, row by row (default \"KQBNRPkqbnrp\")",
+	)
+	fs.StringVar(&a.pieceSet, "piece-set", "", fmt.Sprintf(
+		"built-in piece set (one of %s) to draw on the board of -collection", strings.Join(pic.PieceSetNames, ", "),
+	))
+	fs.StringVar(&a.theme, "theme", "", fmt.Sprintf(
+		"board theme (one of %s) drawn instead of the board image", strings.Join(pic.ThemeNames(), ", "),
+	))
+	fs.IntVar(&a.size, "size", 0, "board size in pixels, rounded down to a multiple of 8 (0 means the size of the board image)")
+	fs.BoolVar(&a.fitBoard, "fit-board", false,
+		"scale the board up if the pieces of -pieces or -piece-set do not fit in its squares (an error otherwise)",
+	)
+}
+
+// fit returns the pieces returned by open with board, which is scaled up if they do not fit and -fit-board is set.
+func (a collectionArgs) fit(board pic.Collection, open func(board pic.Collection) (pic.Collection, error)) (pic.Collection, error) {
+	if a.fitBoard {
+		return chess2pic.FitPieces(board, open)
+	}
+	col, err := open(board)
+	var serr pic.PieceSizeError
+	if errors.As(err, &serr) {
+		return nil, fmt.Errorf("%w (use --fit-board to scale the board up to %dpx)", err, serr.MinSize())
+	}
+	return col, err
+}
+
+// load returns the collection selected by the flags or exits if it cannot be loaded.
+func (a collectionArgs) load() pic.Collection {
+	col := pic.DefaultCollection
+	var err error
+	if a.collection != "" {
+		if col, err = chess2pic.LoadCollection(a.collection); err != nil {
+			chess2pic.Fatalf("failed to load --collection: %s", err)
+		}
+	}
+	if a.pieceSet != "" {
+		if a.pieces != "" {
+			chess2pic.Fatalf("--piece-set and --pieces are mutually exclusive")
+		}
+		col, err = a.fit(col, func(board pic.Collection) (pic.Collection, error) {
+			return pic.WithPieceSet(board, a.pieceSet)
+		})
+		if err != nil {
+			chess2pic.Fatalf("invalid --piece-set value: %s", err)
+		}
+	}
+	if a.spriteOrder != "" {
+		if a.pieces == "" {
+			chess2pic.Fatalf("--sprite-order requires --pieces")
+		}
+		order, err := pic.ParseSpriteOrder(a.spriteOrder)
+		if err != nil {
+			chess2pic.Fatalf("invalid --sprite-order value: %s", err)
+		}
+		col, err = a.fit(col, func(board pic.Collection) (pic.Collection, error) {
+			return chess2pic.LoadSprite(a.pieces, pic.SpriteLayout{Order: order, Board: board})
+		})
+		if err != nil {
+			chess2pic.Fatalf("failed to load --pieces: %s", err)
+		}
+	} else if a.pieces != "" {
+		col, err = a.fit(col, func(board pic.Collection) (pic.Collection, error) {
+			return chess2pic.LoadPieces(a.pieces, board)
+		})
+		if err != nil {
+			chess2pic.Fatalf("failed to load --pieces: %s", err)
+		}
+	}
+	if a.theme != "" {
+		t, err := pic.ParseTheme(a.theme)
+		if err != nil {
+			chess2pic.Fatalf("invalid --theme value: %s", err)
+		}
+		col = t.Apply(col)
+	}
+	if a.size != 0 {
+		if col, err = pic.ScaleCollection(col, a.size); err != nil {
+			chess2pic.Fatalf("invalid --size value: %s", err)
+		}
+	}
+	return col
+}
+
+func parseColor(s string) (chess.PieceColor, error) {
+	switch s {
+	case "white":
+		return chess.White, nil
+	case "black":
+		return chess.Black, nil
+	}
+	return chess.White, fmt.Errorf("unknown color %q", s)
+}
+
+// printParseError prints perr in the input called name to stderr in the format of a compiler diagnostic.
+func printParseError(name string, perr chess.ParseError, warning bool) {
+	severity := "error"
+	if warning {
+		severity = "warning"
 	}
+	fmt.Fprintf(os.Stderr, "%s:%d:%d: %s: %s\n%s\n", name, perr.Line, perr.Column, severity, perr.Err, perr.Caret())
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xopoww/chess2pic/internal/chess2pic"
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// randomMain draws random legal positions with the given material and prints their FENs.
+func randomMain(cmdArgs []string) {
+	fs := flag.NewFlagSet("random", flag.ExitOnError)
+	material := fs.String("material", "KQ vs K", "pieces of both sides, white first (e.g. \"KRP vs KR\")")
+	n := fs.Int("n", 1, "number of positions")
+	toMove := fs.String("to-move", "random", "side to move (\"white\", \"black\" or \"random\")")
+	allowCheck := fs.Bool("allow-check", false, "allow the side to move to be in check")
+	maxPawnRank := fs.Int("max-pawn-rank", 7, "most advanced rank of the pawns counted from their side")
+	noDoubled := fs.Bool("no-doubled-pawns", false, "forbid doubled pawns")
+	seed := fs.Int64("seed", 0, "seed of the random generator (0 means a random seed)")
+	output := fs.String("out", defaultOutName, "output file name prefix (files are named <prefix>_<i>.png)")
+	fromName := fs.String("from", "", "from which player's perspective (\"white\" or \"black\") to draw (default: the side to move)")
+	var images collectionArgs
+	images.register(fs)
+	fs.BoolVar(&chess2pic.DEBUG, "debug", false, "enable debug output")
+	fs.Parse(cmdArgs)
+	col := images.load()
+
+	mat, err := chess.ParseMaterial(*material)
+	if err != nil {
+		chess2pic.Fatalf("invalid --material value: %s", err)
+	}
+	opts := chess.RandomOptions{
+		Material:       mat,
+		AllowCheck:     *allowCheck,
+		MaxPawnRank:    *maxPawnRank,
+		NoDoubledPawns: *noDoubled,
+	}
+	if *toMove == "random" {
+		opts.RandomToMove = true
+	} else if opts.ToMove, err = parseColor(*toMove); err != nil {
+		chess2pic.Fatalf("invalid --to-move value: %q", *toMove)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+		chess2pic.Infof("Seed: %d", *seed)
+	}
+
+	for i := 1; i <= *n; i++ {
+		// every position has its own seed, so that a position can be reproduced alone
+		opts.Seed = *seed + int64(i-1)
+		st, err := chess.RandomPosition(opts)
+		if err != nil {
+			chess2pic.Fatalf("%s", err)
+		}
+		from := st.ToMove
+		if *fromName != "" {
+			if from, err = parseColor(*fromName); err != nil {
+				chess2pic.Fatalf("invalid --from value: %q", *fromName)
+			}
+		}
+
+		name := fmt.Sprintf("%s_%d.png", *output, i)
+		out, err := os.Create(name)
+		if err != nil {
+			chess2pic.Fatalf("error creating %q: %s", name, err)
+		}
+		err = chess2pic.HandleFEN(strings.NewReader(st.FEN()), out, col, from, chess2pic.FENOptions{})
+		out.Close()
+		if err != nil {
+			chess2pic.Fatalf("%s", err)
+		}
+		fmt.Printf("%s: %s\n", name, st.FEN())
+	}
+}
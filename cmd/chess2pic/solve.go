@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xopoww/chess2pic/internal/chess2pic"
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// solveMain solves a mate in N problem: it prints the solution tree and draws the key moves as arrows
+// with the variations under the board.
+func solveMain(cmdArgs []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	fen := fs.String("fen", "", "problem position in FEN notation (the side to move is the attacking side)")
+	n := fs.Int("n", 2, "number of moves to mate in")
+	output := fs.String("out", defaultOutName+".png", "output file name")
+	fromName := fs.String("from", "", "from which player's perspective (\"white\" or \"black\") to draw (default: the side to move)")
+	var images collectionArgs
+	images.register(fs)
+	fs.BoolVar(&chess2pic.DEBUG, "debug", false, "enable debug output")
+	fs.Parse(cmdArgs)
+	col := images.load()
+
+	if *fen == "" {
+		chess2pic.Fatalf("--fen is required")
+	}
+	if *n < 1 {
+		chess2pic.Fatalf("invalid --n value: %d", *n)
+	}
+	st, err := chess.ParseFEN(strings.NewReader(*fen))
+	if err != nil {
+		var perr chess.ParseError
+		if errors.As(err, &perr) {
+			printParseError("<fen>", perr, false)
+			os.Exit(1)
+		}
+		chess2pic.Fatalf("%s", err)
+	}
+	from := st.ToMove
+	if *fromName != "" {
+		if from, err = parseColor(*fromName); err != nil {
+			chess2pic.Fatalf("invalid --from value: %q", *fromName)
+		}
+	}
+
+	start := time.Now()
+	buf := &bytes.Buffer{}
+	sol, err := chess2pic.HandleSolve(strings.NewReader(*fen), buf, col, from, *n)
+	chess2pic.Debugf("Solving took %s", time.Since(start))
+	printTries(os.Stdout, st, sol.Tries)
+	if errors.Is(err, chess2pic.ErrNoMate) {
+		chess2pic.Fatalf("no mate in %d", *n)
+	}
+	if err != nil {
+		chess2pic.Fatalf("%s", err)
+	}
+
+	if len(sol.Keys) > 1 {
+		chess2pic.Infof("warning: the problem is cooked, there are %d keys", len(sol.Keys))
+	}
+	for _, key := range sol.Keys {
+		printMateLine(os.Stdout, st, key, "", "!")
+	}
+
+	if err := os.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		chess2pic.Fatalf("error writing %q: %s", *output, err)
+	}
+}
+
+func printTries(w io.Writer, st chess.State, tries []chess.MateTry) {
+	for _, try := range tries {
+		next := st.Apply(try.Move)
+		fmt.Fprintf(w, "try: %s%s? %s%s!\n", st.MoveNumber(), st.SAN(try.Move),
+			next.MoveNumber(), next.SAN(try.Refutation))
+	}
+}
+
+// printMateLine prints the solution tree of line with one defense per line.
+// Mating moves after a defense are printed on the same line, alternatives (duals) separated by " / ".
+func printMateLine(w io.Writer, st chess.State, line chess.MateLine, indent, mark string) {
+	fmt.Fprintf(w, "%s%s%s%s\n", indent, st.MoveNumber(), st.SAN(line.Move), mark)
+	next := st.Apply(line.Move)
+	for _, def := range line.Defenses {
+		after := next.Apply(def.Move)
+		defense := fmt.Sprintf("%s    %s%s", indent, next.MoveNumber(), next.SAN(def.Move))
+
+		final := true
+		mates := make([]string, 0, len(def.Mates))
+		for _, mate := range def.Mates {
+			final = final && len(mate.Defenses) == 0
+			mates = append(mates, after.MoveNumber()+after.SAN(mate.Move))
+		}
+		if final {
+			fmt.Fprintf(w, "%s %s\n", defense, strings.Join(mates, " / "))
+			continue
+		}
+		fmt.Fprintln(w, defense)
+		for _, mate := range def.Mates {
+			printMateLine(w, after, mate, indent+"        ", "")
+		}
+	}
+}
@@ -2,13 +2,24 @@ package chess2pic
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
 	"image/gif"
 	"image/png"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/andybons/gogif"
 	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/chess/engine"
+	"github.com/xopoww/chess2pic/pkg/chess/polyglot"
+	"github.com/xopoww/chess2pic/pkg/chess/uci"
 	"github.com/xopoww/chess2pic/pkg/pic"
 )
 
@@ -20,43 +31,892 @@ func readerToRuneReader(r io.Reader) io.RuneReader {
 	}
 }
 
-func HandleFEN(in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor) error {
+// Format is the format of the image of a FEN position.
+type Format int
+
+const (
+	PNG Format = iota
+	SVG
+)
+
+var formatNames = map[Format]string{
+	PNG: "png",
+	SVG: "svg",
+}
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// ParseFormat returns the format by its name ("png" or "svg").
+func ParseFormat(s string) (Format, error) {
+	for f, name := range formatNames {
+		if name == s {
+			return f, nil
+		}
+	}
+	return PNG, fmt.Errorf("unknown format %q", s)
+}
+
+// FENOptions are optional settings of HandleFEN.
+type FENOptions struct {
+	// Format is the format of the image.
+	Format Format
+	// PieceURL references the piece images from SVG documents instead of embedding them (may be nil,
+	// see pic.SVGDiagram).
+	PieceURL func(chess.Piece) string
+	// Motifs marks the tactical motifs of the side to move (pins, forks, skewers, discovered attacks
+	// and hanging pieces) with arrows.
+	Motifs bool
+	// Heatmap tints the squares by the side that controls them.
+	Heatmap bool
+	// AltText receives a description of the position in English (may be nil).
+	// The side to move is left out if the fields after the piece placement are invalid.
+	AltText io.Writer
+	// Transform is applied to the position before drawing.
+	Transform chess.Transform
+	// Coordinates selects where the file letters and the rank numbers are drawn.
+	Coordinates pic.Coordinates
+	// Highlight marks the squares of LastMove and the king of the side to move if it is in check
+	// with the colors of the collection.
+	Highlight bool
+	// LastMove is the move that led to the position (may be the zero Move).
+	LastMove chess.Move
+	// Annotations are drawn on the position. Their squares are given before the transform
+	// and are transformed along with the position.
+	Annotations pic.Annotations
+}
+
+// ParseAnnotations parses the annotations of a FEN position: the arrows (e.g. "e2e4:green"),
+// the circles around the squares (e.g. "d5:red"), the fills of the squares (e.g. "d5:yellow")
+// and the badges (e.g. "e4:!!:blue"). The colors may be omitted.
+func ParseAnnotations(arrows, circles, fills, badges []string) (pic.Annotations, error) {
+	var a pic.Annotations
+	for _, s := range arrows {
+		arrow, err := pic.ParseArrow(s)
+		if err != nil {
+			return a, err
+		}
+		a.Arrows = append(a.Arrows, arrow)
+	}
+	for _, s := range circles {
+		m, err := pic.ParseMark(s)
+		if err != nil {
+			return a, err
+		}
+		a.Circles = append(a.Circles, m)
+	}
+	for _, s := range fills {
+		m, err := pic.ParseMark(s)
+		if err != nil {
+			return a, err
+		}
+		a.Fills = append(a.Fills, m)
+	}
+	for _, s := range badges {
+		b, err := pic.ParseBadge(s)
+		if err != nil {
+			return a, err
+		}
+		a.Badges = append(a.Badges, b)
+	}
+	return a, nil
+}
+
+// ParseLastMove parses the squares of a move (e.g. "e2e4") for FENOptions.LastMove.
+func ParseLastMove(s string) (chess.Move, error) {
+	if len(s) != 4 {
+		return chess.Move{}, fmt.Errorf("invalid last move %q", s)
+	}
+	from, err := chess.NewSquareFromString(s[:2])
+	if err != nil {
+		return chess.Move{}, fmt.Errorf("invalid last move %q", s)
+	}
+	to, err := chess.NewSquareFromString(s[2:])
+	if err != nil {
+		return chess.Move{}, fmt.Errorf("invalid last move %q", s)
+	}
+	return chess.Move{From: from, To: to}, nil
+}
+
+// ParseHighlights parses the colors of the last move and the check highlights (see pic.ParseColor)
+// for pic.WithHighlights. The empty colors are nil.
+func ParseHighlights(lastMove, check string) (pic.Highlights, error) {
+	var (
+		h   pic.Highlights
+		err error
+	)
+	if lastMove != "" {
+		if h.LastMove, err = pic.ParseColor(lastMove); err != nil {
+			return h, fmt.Errorf("invalid last move color: %w", err)
+		}
+	}
+	if check != "" {
+		if h.Check, err = pic.ParseColor(check); err != nil {
+			return h, fmt.Errorf("invalid check color: %w", err)
+		}
+	}
+	return h, nil
+}
+
+func HandleFEN(in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor, opts FENOptions) error {
 	rs := readerToRuneReader(in)
 
-	pos, err := chess.FEN().Parse(rs)
+	// the side to move is needed only for the motifs, the check and the description, the rest of the fields is ignored otherwise
+	var (
+		st  chess.State
+		err error
+		// hasState is false if only the placement is parsed
+		hasState = true
+	)
+	switch {
+	case opts.Motifs || opts.Highlight:
+		st, err = chess.ParseFEN(rs)
+	case opts.AltText != nil:
+		// the description does not make the rest of the fields required, it leaves out the side to move if they are invalid
+		var data []byte
+		if data, err = io.ReadAll(in); err != nil {
+			return err
+		}
+		if st, err = chess.ParseFEN(bytes.NewReader(data)); err != nil {
+			st, hasState = chess.State{}, false
+			st.Position, err = chess.FEN().Parse(bytes.NewReader(data))
+		}
+	default:
+		st.Position, err = chess.FEN().Parse(rs)
+	}
 	if err != nil {
 		return err
 	}
+	st = opts.Transform.State(st)
+	last := opts.LastMove
+	if last != (chess.Move{}) {
+		// the squares of the last move are transformed with the position
+		last.From, last.To = opts.Transform.Square(last.From), opts.Transform.Square(last.To)
+		if st.Position.Get(last.To).Kind == chess.None {
+			return fmt.Errorf("invalid last move: no piece on %s", last.To)
+		}
+	}
+	if opts.AltText != nil {
+		desc := st.Describe()
+		if !hasState {
+			desc = st.Position.Describe()
+		}
+		if _, err := io.WriteString(opts.AltText, desc); err != nil {
+			return err
+		}
+	}
 
-	img := pic.DrawPosition(col, pos, from)
+	annotations := opts.Annotations.Transform(opts.Transform)
+	if opts.Format == SVG {
+		d := pic.SVGDiagram{
+			State:       st,
+			From:        from,
+			Coordinates: opts.Coordinates,
+			Highlight:   opts.Highlight,
+			LastMove:    last,
+			Heatmap:     opts.Heatmap,
+			Annotations: annotations,
+			PieceURL:    opts.PieceURL,
+		}
+		if opts.Motifs {
+			d.Motifs = st.Position.Motifs(st.ToMove)
+		}
+		return pic.WriteSVG(out, col, d)
+	}
+
+	f := frame{coords: opts.Coordinates}
+	if opts.Highlight {
+		f.layers = append(f.layers, pic.HighlightLayer(pic.CollectionHighlights(col), st, last, from))
+	}
+	if opts.Heatmap {
+		f.layers = append(f.layers, pic.HeatmapLayer(st.Position, from))
+	}
+	f.layers = append(f.layers, annotations.Layer(from))
+	img, board := drawBoard(col, st.Position, from, f)
+	if opts.Motifs {
+		motifs := st.Position.Motifs(st.ToMove)
+		for _, m := range motifs {
+			Debugf("%s by %s on %v", m.Kind, m.Attacker, m.Targets)
+		}
+		pic.DrawMotifs(img, board, motifs, from)
+	}
+	annotations.Draw(img, board, from)
 	return png.Encode(out, img)
 }
 
-func HandlePGN(in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor) error {
-	res, err := chess.ParsePGN(in)
+// frame is what is drawn around the board and between the board and the pieces.
+type frame struct {
+	layers []pic.Layer
+	coords pic.Coordinates
+	// evalBar is drawn to the left of the board if it is not nil.
+	evalBar *chess.Eval
+	// header adds a band with the title and the subtitle over the board (see pic.DrawHeader).
+	header          bool
+	title, subtitle string
+	// captions add a band with each of the texts under the board (see pic.DrawCaption).
+	captions []string
+}
+
+// drawBoard draws the position with the elements of f and returns the image and the rectangle of the board in it.
+func drawBoard(col pic.Collection, pos chess.Position, from chess.PieceColor, f frame) (draw.Image, image.Rectangle) {
+	layers := f.layers
+	if f.coords == pic.InsideCoordinates {
+		// the labels take the colors of the squares, so they go before anything else
+		layers = append([]pic.Layer{pic.CoordinatesLayer(from)}, layers...)
+	}
+	br := col.Board(from).Bounds()
+	br = br.Sub(br.Min)
+	if f.evalBar == nil && f.coords != pic.MarginCoordinates && !f.header && len(f.captions) == 0 {
+		img := pic.DrawPosition(col, pos, from, layers...)
+		return img, br.Add(img.Bounds().Min)
+	}
+
+	margin := 0
+	if f.coords == pic.MarginCoordinates {
+		margin = pic.CoordinatesMargin(br)
+	}
+	bw := 0
+	if f.evalBar != nil {
+		bw = br.Dx() / evalBarRatio
+	}
+	hh, ch := 0, 0
+	if f.header {
+		hh = pic.HeaderHeight(br)
+	}
+	ch = pic.CaptionHeight(br) * len(f.captions)
+	// the bands span the whole width, the eval bar is as high as the board with the margin
+	width, height := bw+br.Dx()+2*margin, br.Dy()+2*margin
+	img := image.NewRGBA(image.Rect(0, 0, width, hh+height+ch))
+	if f.header {
+		pic.DrawHeader(img, image.Rect(0, 0, width, hh), f.title, f.subtitle)
+	}
+	for i, text := range f.captions {
+		y := hh + height + i*pic.CaptionHeight(br)
+		pic.DrawCaption(img, image.Rect(0, y, width, y+pic.CaptionHeight(br)), text)
+	}
+	if f.evalBar != nil {
+		pic.DrawEvalBar(img, image.Rect(0, hh, bw, hh+height), *f.evalBar, from)
+	}
+	br = br.Add(image.Pt(bw+margin, hh+margin))
+	if margin > 0 {
+		pic.DrawCoordinatesMargin(img, br, from)
+	}
+	pic.DrawPositionOn(img, br.Min, col, pos, from, layers...)
+	return img, br
+}
+
+// Hint is the move suggested by HandleHint.
+type Hint struct {
+	Move chess.Move
+	SAN  string
+	Eval chess.Eval
+	// AltText describes the position and the move in English.
+	AltText string
+}
+
+// HandleHint finds the best move in the FEN position with the built-in engine
+// and draws the position with an arrow for the move. If ctx is done, the search is stopped and ctx.Err() is returned.
+func HandleHint(ctx context.Context, in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor, limits engine.Limits) (Hint, error) {
+	st, err := chess.ParseFEN(readerToRuneReader(in))
 	if err != nil {
-		return err
+		return Hint{}, err
+	}
+
+	res, err := engine.New().Search(ctx, st, limits)
+	if err != nil {
+		return Hint{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Hint{}, err
+	}
+	Debugf("Searched %d nodes to depth %d", res.Nodes, res.Depth)
+	hint := Hint{
+		Move:    res.Move,
+		SAN:     st.SAN(res.Move),
+		Eval:    res.Eval,
+		AltText: fmt.Sprintf("%s Suggested move: %s.", st.Describe(), st.DescribeMove(res.Move)),
+	}
+
+	img, board := drawBoard(col, st.Position, from, frame{})
+	pic.DrawArrow(img, board, res.Move.From, res.Move.To, from, pic.HintColor)
+	return hint, png.Encode(out, img)
+}
+
+// maxSolutionLines limits the variations drawn by HandleSolve.
+const maxSolutionLines = 8
+
+// solutionLines returns the variations of the solution of the problem in st, one per line and every key
+// marked with "!" (e.g. "1. Qg7! Kxg7 2. Rf7#"). If there are more than maxSolutionLines variations,
+// the last line tells how many are left out.
+func solutionLines(st chess.State, sol chess.MateSolution) []string {
+	var lines []string
+	for _, key := range sol.Keys {
+		for _, movs := range mateVariations(key) {
+			parts := make([]string, 0, len(movs))
+			next := st
+			for i, mov := range movs {
+				san := next.SAN(mov)
+				if i == 0 || next.ToMove == chess.White {
+					san = next.MoveNumber() + " " + san
+				}
+				if i == 0 {
+					san += "!"
+				}
+				parts = append(parts, san)
+				next = next.Apply(mov)
+			}
+			lines = append(lines, strings.Join(parts, " "))
+		}
+	}
+	if len(lines) > maxSolutionLines {
+		more := len(lines) - maxSolutionLines + 1
+		lines = append(lines[:maxSolutionLines-1], fmt.Sprintf("and %d more variations", more))
+	}
+	return lines
+}
+
+// mateVariations returns the moves of every variation of the solution tree of line.
+func mateVariations(line chess.MateLine) [][]chess.Move {
+	if len(line.Defenses) == 0 {
+		return [][]chess.Move{{line.Move}}
+	}
+	var res [][]chess.Move
+	for _, def := range line.Defenses {
+		for _, mate := range def.Mates {
+			for _, movs := range mateVariations(mate) {
+				res = append(res, append([]chess.Move{line.Move, def.Move}, movs...))
+			}
+		}
+	}
+	return res
+}
+
+// ErrNoMate is returned by HandleSolve if there is no forced mate in the position.
+var ErrNoMate = errors.New("no forced mate")
+
+// HandleSolve solves the FEN position as a mate in n problem and draws it with arrows for the key moves
+// and the variations of the solution in the bands under the board (see solutionLines).
+// If there are no keys, the picture is not drawn and ErrNoMate is returned with the tries.
+func HandleSolve(in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor, n int) (chess.MateSolution, error) {
+	st, err := chess.ParseFEN(readerToRuneReader(in))
+	if err != nil {
+		return chess.MateSolution{}, err
+	}
+
+	sol := chess.SolveMate(st, n)
+	if len(sol.Keys) == 0 {
+		return sol, ErrNoMate
+	}
+
+	img, board := drawBoard(col, st.Position, from, frame{captions: solutionLines(st, sol)})
+	for _, key := range sol.Keys {
+		pic.DrawArrow(img, board, key.Move.From, key.Move.To, from, pic.HintColor)
+	}
+	return sol, png.Encode(out, img)
+}
+
+// gameHeader returns the players with their ratings (e.g. "Carlsen (2882) – Caruana (2820)")
+// and the event with the date from the PGN tags followed by the opening (e.g. "Norway Chess · 2023.05.30 · C42 Petrov's Defense").
+// The unknown values are omitted.
+// If t swaps the colors, the players are swapped too, so that they are next to the colors they play in the animation.
+func gameHeader(tags chess.Tags, opening string, t chess.Transform) (title, subtitle string) {
+	player := func(name string, elo int) string {
+		if name == "?" {
+			name = ""
+		}
+		if elo > 0 {
+			if name == "" {
+				name = "?"
+			}
+			name += fmt.Sprintf(" (%d)", elo)
+		}
+		return name
+	}
+	white, black := player(tags.White(), tags.WhiteElo()), player(tags.Black(), tags.BlackElo())
+	if t.SwapsColors() {
+		white, black = black, white
+	}
+	if white != "" || black != "" {
+		if white == "" {
+			white = "?"
+		}
+		if black == "" {
+			black = "?"
+		}
+		title = white + " – " + black
+	}
+
+	var parts []string
+	if event := tags.Event(); event != "" && event != "?" {
+		parts = append(parts, event)
+	}
+	if date := tags.Date(); date.Year != 0 {
+		// the unknown month and day are not shown
+		s := date.String()
+		s = strings.TrimSuffix(strings.TrimSuffix(s, ".??"), ".??")
+		parts = append(parts, s)
+	}
+	if opening != "" {
+		parts = append(parts, opening)
+	}
+	return title, strings.Join(parts, " · ")
+}
+
+// moveCaption returns the move that led to the i-th frame of the game in SAN with its number (e.g. "23. Rxe6+"
+// or "23... Nxe4"), followed by the result of the game in the last frame if the game is complete.
+// The first frame has no move. If t swaps the colors, the result is swapped too, so that it matches the colors of the moves.
+func moveCaption(res chess.PGNResult, sts []chess.State, i int, complete bool, t chess.Transform) string {
+	if i == 0 {
+		return ""
+	}
+	st := sts[i-1]
+	caption := st.MoveNumber() + " " + st.SAN(res.Moves[i-1])
+	if r := res.Tags.Result(); complete && i == len(res.Moves) && r != "" && r != "*" {
+		if t.SwapsColors() {
+			switch r {
+			case "1-0":
+				r = "0-1"
+			case "0-1":
+				r = "1-0"
+			}
+		}
+		caption += "  " + r
+	}
+	return caption
+}
+
+// openingName returns the name of the game opening from the PGN tags.
+// If the tags are missing, the opening is classified by the moves.
+func openingName(res chess.PGNResult) (string, bool) {
+	if name, ok := res.Tags.Get("Opening"); ok {
+		if eco := res.Tags.ECO(); eco != "" {
+			return eco + " " + name, true
+		}
+		return name, true
+	}
+	if _, custom := res.Tags.Get("FEN"); custom {
+		return "", false
+	}
+	op, ok := chess.ClassifyOpening(res.Moves)
+	if !ok {
+		return "", false
+	}
+	return op.String(), true
+}
+
+// PartialError is returned by HandlePGN if the movetext contains an error. In that case
+// the game is rendered up to the move before the error, followed by a frame marking the failure.
+type PartialError struct {
+	// Moves is the number of rendered moves
+	Moves int
+	Err   error
+}
+
+func (err PartialError) Error() string {
+	return fmt.Sprintf("rendered only %d moves: %s", err.Moves, err.Err)
+}
+
+func (err PartialError) Unwrap() error {
+	return err.Err
+}
+
+// failureTint is blended over the last frame of a partially rendered game.
+var failureTint = color.NRGBA{R: 0xff, A: 0x60}
+
+// PGNOptions are optional settings of HandlePGN.
+type PGNOptions struct {
+	// Book is used to find the leading moves of the game that are "by the book" (may be nil).
+	// The frames of these moves are labelled "book".
+	Book *polyglot.Book
+	// FastBook makes the frames of book moves shorter.
+	FastBook bool
+
+	// EvalBar draws an evaluation bar beside the board.
+	EvalBar bool
+	// EvalGraph receives a PNG chart of the evaluation over the whole game (may be nil).
+	EvalGraph io.Writer
+	// Engine evaluates the positions of the game (may be nil). If it is nil, the evaluations
+	// are taken from [%eval] commands in the comments.
+	Engine *uci.Engine
+	// EngineLimits restrict the search in every position (defaults to DefaultEngineDepth).
+	EngineLimits uci.Limits
+
+	// Annotate classifies the moves by the drop of the evaluation. Inaccuracies, mistakes and blunders
+	// are marked on the frames with the best move and shown longer. The positions are evaluated by Engine
+	// or, if it is nil, by the built-in engine (the comments are not used).
+	Annotate bool
+	// Annotations receives a line for every marked move (may be nil).
+	Annotations io.Writer
+	// AnalysisTime bounds the total time of the analysis by the built-in engine (defaults to DefaultAnalysisTime).
+	AnalysisTime time.Duration
+	// Result receives the game as it is drawn (after Transform), with the NAGs and the best moves
+	// if it is annotated (may be nil).
+	Result *chess.PGNResult
+	// Context stops the analysis by the engines when it is done (defaults to context.Background()).
+	Context context.Context
+
+	// Heatmap tints the squares by the side that controls them in every frame.
+	Heatmap bool
+
+	// Narration receives a description of every move in English, one per line (may be nil).
+	Narration io.Writer
+	// AltText receives a description of the animation in English: the last move and the final position (may be nil).
+	AltText io.Writer
+
+	// Transform is applied to the game before drawing (castling moves cannot be mirrored).
+	Transform chess.Transform
+	// Coordinates selects where the file letters and the rank numbers are drawn.
+	Coordinates pic.Coordinates
+	// Highlight marks the squares of the last move and the king in check in every frame
+	// with the colors of the collection.
+	Highlight bool
+	// Header draws a band over the board with the players, their ratings, the event and the date from the tags,
+	// and the opening (from the tags or classified by the moves).
+	Header bool
+	// Caption draws a band under the board with the last move in SAN (e.g. "23. Rxe6+")
+	// and the result of the game in the last frame.
+	Caption bool
+}
+
+const DefaultEngineDepth = 12
+
+// DefaultAnalysisTime bounds the analysis of an annotated game by the built-in engine.
+const DefaultAnalysisTime = 30 * time.Second
+
+// errNoEvals is returned by gameEvals if the evaluations are not available.
+var errNoEvals = errors.New("no evaluations in the game comments")
+
+// bookLabel is drawn on the frames of the book moves.
+const bookLabel = "book"
+
+// builtinMoveTime limits the search of the built-in engine in every position of an annotated game
+// (less if the game is too long for PGNOptions.AnalysisTime, see analysisMoveTime).
+const builtinMoveTime = 200 * time.Millisecond
+
+// analysisMoveTime returns the search time of the built-in engine in each of the positions of an annotated game
+// so that the analysis takes about total (DefaultAnalysisTime if it is 0).
+func analysisMoveTime(total time.Duration, positions int) time.Duration {
+	if total == 0 {
+		total = DefaultAnalysisTime
+	}
+	// every position gets an equal share of the total time
+	moveTime := total / time.Duration(positions)
+	if moveTime > builtinMoveTime {
+		return builtinMoveTime
+	}
+	if moveTime < time.Millisecond {
+		// a zero time means the default of the engine, the first iteration is searched anyway
+		return time.Millisecond
+	}
+	return moveTime
+}
+
+const (
+	// evalBarRatio is the ratio of the board width to the evaluation bar width.
+	evalBarRatio = 16
+
+	evalGraphWidth  = 640
+	evalGraphHeight = 160
+)
+
+// gameEvals returns the evaluation of the start position and of the position after every move,
+// and the best moves in these positions if they were evaluated by an engine (otherwise best is nil).
+// Missing evaluations are copied from the previous position (the start position defaults to equal).
+func gameEvals(res chess.PGNResult, opts PGNOptions) (evals []chess.Eval, best []chess.Move, err error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	evals = make([]chess.Eval, len(res.Moves)+1)
+	if opts.Engine != nil {
+		best = make([]chess.Move, len(evals))
+		limits := opts.EngineLimits
+		if limits == (uci.Limits{}) {
+			limits.Depth = DefaultEngineDepth
+		}
+		if err := opts.Engine.NewGame(ctx); err != nil {
+			return nil, nil, fmt.Errorf("engine: %w", err)
+		}
+		st := res.StartState
+		for i := range evals {
+			if i > 0 {
+				evals[i] = evals[i-1]
+				st = st.Apply(res.Moves[i-1])
+			}
+			if err := opts.Engine.Position(res.StartState, res.Moves[:i]); err != nil {
+				return nil, nil, fmt.Errorf("engine: %w", err)
+			}
+			// there is no best move in a final position, but there may still be a score
+			sr, err := opts.Engine.Go(ctx, limits)
+			if err != nil && !errors.Is(err, uci.ErrNoBestMove) {
+				return nil, nil, fmt.Errorf("engine: %w", err)
+			}
+			best[i] = sr.BestMove
+			if !sr.Info.HasScore {
+				continue
+			}
+			if eval, ok := sr.Info.Score.Eval(st.ToMove); ok {
+				evals[i] = eval
+			}
+		}
+		return evals, best, nil
+	}
+
+	if opts.Annotate {
+		best = make([]chess.Move, len(evals))
+		moveTime := analysisMoveTime(opts.AnalysisTime, len(evals))
+		Infof("Analysing %d positions with the built-in engine (%s per position)", len(evals), moveTime)
+		eng := engine.New()
+		st := res.StartState
+		for i := range evals {
+			if i > 0 {
+				evals[i] = evals[i-1]
+				st = st.Apply(res.Moves[i-1])
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			sr, err := eng.Search(ctx, st, engine.Limits{MoveTime: moveTime})
+			if errors.Is(err, engine.ErrNoMoves) {
+				// the evaluation before checkmate stays, stalemate is a draw
+				if st.IsStalemate() {
+					evals[i] = chess.Eval{}
+				}
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			evals[i], best[i] = sr.Eval, sr.Move
+		}
+		return evals, best, nil
+	}
+
+	found := false
+	for i, comment := range res.Comments {
+		evals[i+1] = evals[i]
+		eval, err := chess.CommentEval(comment)
+		if errors.Is(err, chess.ErrNoEval) {
+			continue
+		}
+		if err != nil {
+			Debugf("ply %d: %s", i+1, err)
+			continue
+		}
+		// the comments are written for the game before the transform
+		if opts.Transform.SwapsColors() {
+			eval.Centipawns, eval.Mate = -eval.Centipawns, -eval.Mate
+		}
+		evals[i+1] = eval
+		found = true
+	}
+	if !found {
+		return nil, nil, errNoEvals
+	}
+	return evals, nil, nil
+}
+
+// narrateGame writes the narration and the alt text of the game if they are requested.
+func narrateGame(res chess.PGNResult, opts PGNOptions) error {
+	if opts.Narration == nil && opts.AltText == nil {
+		return nil
+	}
+	st := res.StartState
+	last := ""
+	for _, mov := range res.Moves {
+		last = st.DescribeMove(mov)
+		if opts.Narration != nil {
+			if _, err := fmt.Fprintf(opts.Narration, "%s %s.\n", st.MoveNumber(), last); err != nil {
+				return err
+			}
+		}
+		st = st.Apply(mov)
+	}
+	if opts.AltText == nil {
+		return nil
+	}
+	alt := "Animation of a chess game."
+	if last != "" {
+		alt += fmt.Sprintf(" The last move: %s.", last)
+	}
+	_, err := fmt.Fprintf(opts.AltText, "%s Final position: %s", alt, st.Describe())
+	return err
+}
+
+// markColors are blended over the squares of the marked moves.
+var markColors = map[chess.NAG]color.Color{
+	chess.Inaccuracy: color.NRGBA{R: 0xe8, G: 0xc0, B: 0x20, A: 0xa0},
+	chess.Mistake:    color.NRGBA{R: 0xf0, G: 0x80, B: 0x20, A: 0xa0},
+	chess.Blunder:    color.NRGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xa0},
+}
+
+// markedDelay is the delay of the frames of marked moves in hundredths of a second.
+const markedDelay = 300
+
+// annotateMoves judges every move of the game by the evaluations and writes the marked moves to w (if it is not nil).
+// nags[i] is the NAG of i-th move.
+func annotateMoves(res chess.PGNResult, evals []chess.Eval, best []chess.Move, w io.Writer) []chess.NAG {
+	nags := make([]chess.NAG, len(res.Moves))
+	st := res.StartState
+	for i, mov := range res.Moves {
+		// a move without the best move to compare is not judged
+		if best[i] != (chess.Move{}) && mov != best[i] {
+			nags[i] = chess.JudgeMove(evals[i], evals[i+1], st.ToMove)
+		}
+		if nags[i] != chess.NoNAG && w != nil {
+			fmt.Fprintf(w, "%s %s%s (%s -> %s), best is %s\n", st.MoveNumber(), st.SAN(mov), nags[i], evals[i], evals[i+1], st.SAN(best[i]))
+		}
+		st = st.Apply(mov)
+	}
+	return nags
+}
+
+func HandlePGN(in io.Reader, out io.Writer, col pic.Collection, from chess.PieceColor, opts PGNOptions) error {
+	res, perr := chess.ParsePGNPartial(in)
+	if perr != nil && !res.Partial {
+		return perr
 	}
 
 	Debugf("Parsed PGN with %d moves", len(res.Moves))
+	// the opening is classified by the moves as they were played
+	opening, ok := openingName(res)
+	if ok {
+		Debugf("Opening: %s", opening)
+	}
+	if opts.Transform != chess.Identity {
+		movs, err := opts.Transform.Moves(res.Moves)
+		if err != nil {
+			return err
+		}
+		res.Moves = movs
+		res.StartState = opts.Transform.State(res.StartState)
+		res.Start = res.StartState.Position
+	}
 	Debugf("PGN tags: %#v", res.Tags)
+	for _, w := range res.Warnings {
+		Infof("warning: %s", w)
+	}
+	if err := narrateGame(res, opts); err != nil {
+		return err
+	}
 
-	poss := make([]chess.Position, 0, len(res.Moves)+1)
-	poss = append(poss, res.Start)
+	bookPlies := 0
+	if opts.Book != nil {
+		bookPlies = opts.Book.Depth(res.StartState, res.Moves)
+		Infof("First %d plies are in book", bookPlies)
+	}
+
+	sts := make([]chess.State, 0, len(res.Moves)+1)
+	sts = append(sts, res.StartState)
 	for i, mov := range res.Moves {
-		poss = append(poss, chess.Apply(poss[i], mov))
+		sts = append(sts, sts[i].Apply(mov))
+	}
+
+	var (
+		evals []chess.Eval
+		best  []chess.Move
+		nags  []chess.NAG
+	)
+	if opts.EvalBar || opts.EvalGraph != nil || opts.Annotate {
+		var err error
+		evals, best, err = gameEvals(res, opts)
+		if errors.Is(err, errNoEvals) {
+			Infof("warning: %s, evaluation is not drawn", err)
+		} else if err != nil {
+			return err
+		}
+	}
+	if opts.Annotate {
+		nags = annotateMoves(res, evals, best, opts.Annotations)
+		res.NAGs, res.BestMoves = nags, best[:len(res.Moves)]
+	}
+	if opts.Result != nil {
+		*opts.Result = res
+	}
+	if opts.EvalGraph != nil && evals != nil {
+		if err := png.Encode(opts.EvalGraph, pic.DrawEvalGraph(evals, evalGraphWidth, evalGraphHeight)); err != nil {
+			return err
+		}
+	}
+	var title, subtitle string
+	if opts.Header {
+		title, subtitle = gameHeader(res.Tags, opening, opts.Transform)
+	}
+	highlights := pic.CollectionHighlights(col)
+	drawFrame := func(i int) draw.Image {
+		f := frame{coords: opts.Coordinates}
+		// frame i shows the position after i-th ply
+		if opts.Highlight {
+			var last chess.Move
+			if i > 0 {
+				last = res.Moves[i-1]
+			}
+			f.layers = append(f.layers, pic.HighlightLayer(highlights, sts[i], last, from))
+		}
+		if opts.Heatmap {
+			f.layers = append(f.layers, pic.HeatmapLayer(sts[i].Position, from))
+		}
+		marked := i > 0 && nags != nil && nags[i-1] != chess.NoNAG
+		if marked {
+			mov, c := res.Moves[i-1], markColors[nags[i-1]]
+			f.layers = append(f.layers, func(dst draw.Image, board image.Rectangle) {
+				pic.FillSquare(dst, board, mov.From, from, c)
+				pic.FillSquare(dst, board, mov.To, from, c)
+			})
+		}
+		if opts.EvalBar && evals != nil {
+			f.evalBar = &evals[i]
+		}
+		if opts.Header {
+			f.header = true
+			f.title, f.subtitle = title, subtitle
+		}
+		if opts.Caption {
+			// the moves of a partially parsed game do not end with the result
+			f.captions = []string{moveCaption(res, sts, i, perr == nil, opts.Transform)}
+		}
+
+		img, br := drawBoard(col, sts[i].Position, from, f)
+		if marked {
+			pic.DrawArrow(img, br, best[i-1].From, best[i-1].To, from, pic.HintColor)
+		}
+		if i > 0 && i <= bookPlies {
+			pic.DrawLabel(img, br, bookLabel)
+		}
+		return img
 	}
 
 	dst := &gif.GIF{}
 	quantizer := gogif.MedianCutQuantizer{NumColor: 64}
-	for _, pos := range poss {
-		img := pic.DrawPosition(col, pos, from)
+	addFrame := func(img image.Image) {
 		pimg := image.NewPaletted(img.Bounds(), nil)
 		quantizer.Quantize(pimg, img.Bounds(), img, image.Point{})
 		dst.Image = append(dst.Image, pimg)
 		dst.Delay = append(dst.Delay, 100)
 	}
+	for i := range sts {
+		addFrame(drawFrame(i))
+		// frame i shows the position after i-th ply
+		if opts.FastBook && i > 0 && i <= bookPlies {
+			dst.Delay[i] = 30
+		}
+		if i > 0 && nags != nil && nags[i-1] != chess.NoNAG {
+			dst.Delay[i] = markedDelay
+		}
+	}
+	if perr != nil {
+		img := drawFrame(len(sts) - 1)
+		pic.Tint(img, failureTint)
+		addFrame(img)
+	}
 	dst.Delay[len(dst.Delay)-1] = 500
 
-	return gif.EncodeAll(out, dst)
+	if err := gif.EncodeAll(out, dst); err != nil {
+		return err
+	}
+	if perr != nil {
+		return PartialError{Moves: len(res.Moves), Err: perr}
+	}
+	return nil
 }
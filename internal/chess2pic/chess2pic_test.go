@@ -0,0 +1,394 @@
+package chess2pic
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/chess/engine"
+	"github.com/xopoww/chess2pic/pkg/pic"
+)
+
+// annotatedGame has a few bad moves of both sides and ends with a checkmate.
+const annotatedGame = "1. e4 e5 2. Nf3 Nc6 3. Bc4 Nd4 4. Nxe5 Qg5 5. Nxf7 Qxg2 6. Rf1 Qxe4+ 7. Be2 Nf3#"
+
+func TestAnalysisMoveTime(t *testing.T) {
+	tcs := []struct {
+		name      string
+		total     time.Duration
+		positions int
+		want      time.Duration
+	}{
+		{"share", 300 * time.Millisecond, 15, 20 * time.Millisecond},
+		{"capped", time.Minute, 15, builtinMoveTime},
+		{"default total", 0, 300, DefaultAnalysisTime / 300},
+		{"at least a millisecond", 10 * time.Millisecond, 100, time.Millisecond},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			if got := analysisMoveTime(tc.total, tc.positions); got != tc.want {
+				tt.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGameEvalsAnalysis(t *testing.T) {
+	res, err := chess.ParsePGN(strings.NewReader(annotatedGame))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	evals, best, err := gameEvals(res, PGNOptions{Annotate: true, AnalysisTime: 15 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(evals) != 15 || len(best) != 15 {
+		t.Fatalf("want 15 evaluations and best moves, got %d and %d", len(evals), len(best))
+	}
+	if best[13] != res.Moves[13] {
+		t.Errorf("the mate in one must be found, got %v", best[13])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := gameEvals(res, PGNOptions{Annotate: true, Context: ctx}); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestHandleHintCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := HandleHint(ctx, strings.NewReader("4k3/8/8/8/8/8/8/R3K3 w - - 0 1"), ioutil.Discard, pic.DefaultCollection, chess.White, engine.Limits{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestHandlePGNResult(t *testing.T) {
+	// a small board keeps the encoding of the animation fast
+	col, err := pic.ScaleCollection(pic.DefaultCollection, 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var res chess.PGNResult
+	opts := PGNOptions{Annotate: true, AnalysisTime: 15 * time.Millisecond, Result: &res}
+	if err := HandlePGN(strings.NewReader(annotatedGame), ioutil.Discard, col, chess.White, opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(res.Moves) != 14 || len(res.NAGs) != 14 || len(res.BestMoves) != 14 {
+		t.Fatalf("want 14 moves with NAGs and best moves, got %d, %d and %d", len(res.Moves), len(res.NAGs), len(res.BestMoves))
+	}
+	marked := 0
+	for _, nag := range res.NAGs {
+		if nag != chess.NoNAG {
+			marked++
+		}
+	}
+	if marked == 0 {
+		t.Errorf("want marked moves")
+	}
+	// the best move is played, so it is not judged
+	if res.NAGs[13] != chess.NoNAG {
+		t.Errorf("7... Nf3#: want no NAG, got %v", res.NAGs[13])
+	}
+
+	// the game is returned as it is drawn
+	opts = PGNOptions{Transform: chess.Flip, Result: &res}
+	if err := HandlePGN(strings.NewReader("1. e4 e5"), ioutil.Discard, col, chess.White, opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(res.Moves) != 2 || res.Moves[0].From != chess.MustNewSquareFromString("e7") || res.NAGs != nil {
+		t.Errorf("want the flipped game without NAGs, got %v and %v", res.Moves, res.NAGs)
+	}
+}
+
+func TestGameEvalsComments(t *testing.T) {
+	res, err := chess.ParsePGN(strings.NewReader("1. e4 { [%eval 0.3] } e5 { [%eval #-2] } 2. Nf3 { no evaluation }"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tcs := []struct {
+		name      string
+		transform chess.Transform
+		want      []chess.Eval
+	}{
+		{
+			name:      "identity",
+			transform: chess.Identity,
+			want:      []chess.Eval{{}, {Centipawns: 30}, {Mate: -2}, {Mate: -2}},
+		},
+		{
+			name:      "mirror",
+			transform: chess.Mirror,
+			want:      []chess.Eval{{}, {Centipawns: 30}, {Mate: -2}, {Mate: -2}},
+		},
+		{
+			name:      "flip",
+			transform: chess.Flip,
+			want:      []chess.Eval{{}, {Centipawns: -30}, {Mate: 2}, {Mate: 2}},
+		},
+		{
+			name:      "rotate",
+			transform: chess.Rotate,
+			want:      []chess.Eval{{}, {Centipawns: -30}, {Mate: 2}, {Mate: 2}},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			evals, _, err := gameEvals(res, PGNOptions{Transform: tc.transform})
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if len(evals) != len(tc.want) {
+				tt.Fatalf("want %d evaluations, got %d", len(tc.want), len(evals))
+			}
+			for i := range evals {
+				if evals[i] != tc.want[i] {
+					tt.Errorf("ply %d: want %v, got %v", i, tc.want[i], evals[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGameHeader(t *testing.T) {
+	tags := chess.Tags{}.Set("White", "Carlsen").Set("Black", "Caruana").Set("WhiteElo", "2882").Set("Event", "Norway Chess")
+	tcs := []struct {
+		name      string
+		transform chess.Transform
+		wantTitle string
+	}{
+		{
+			name:      "identity",
+			transform: chess.Identity,
+			wantTitle: "Carlsen (2882) – Caruana",
+		},
+		{
+			name:      "flip",
+			transform: chess.Flip,
+			wantTitle: "Caruana – Carlsen (2882)",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			title, subtitle := gameHeader(tags, "C42 Petrov's Defense", tc.transform)
+			if title != tc.wantTitle {
+				tt.Errorf("title: want %q, got %q", tc.wantTitle, title)
+			}
+			if want := "Norway Chess · C42 Petrov's Defense"; subtitle != want {
+				tt.Errorf("subtitle: want %q, got %q", want, subtitle)
+			}
+		})
+	}
+}
+
+func TestMoveCaption(t *testing.T) {
+	res, err := chess.ParsePGN(strings.NewReader("[Result \"1-0\"]\n\n1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sts := []chess.State{res.StartState}
+	for _, mov := range res.Moves {
+		sts = append(sts, sts[len(sts)-1].Apply(mov))
+	}
+	tcs := []struct {
+		name      string
+		i         int
+		complete  bool
+		transform chess.Transform
+		want      string
+	}{
+		{
+			name: "first frame",
+		},
+		{
+			name: "white move",
+			i:    3,
+			want: "2. Qh5",
+		},
+		{
+			name: "black move",
+			i:    4,
+			want: "2... Nc6",
+		},
+		{
+			name:     "last move",
+			i:        7,
+			complete: true,
+			want:     "4. Qxf7#  1-0",
+		},
+		{
+			name: "partial game",
+			i:    7,
+			want: "4. Qxf7#",
+		},
+		{
+			name:      "last move mirrored",
+			i:         7,
+			complete:  true,
+			transform: chess.Mirror,
+			want:      "4. Qxf7#  1-0",
+		},
+		{
+			name:      "last move flipped",
+			i:         7,
+			complete:  true,
+			transform: chess.Flip,
+			want:      "4. Qxf7#  0-1",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			if got := moveCaption(res, sts, tc.i, tc.complete, tc.transform); got != tc.want {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHandlePGNPartial(t *testing.T) {
+	col, err := pic.ScaleCollection(pic.DefaultCollection, 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tcs := []struct {
+		name        string
+		notation    string
+		wantPartial bool
+		wantMoves   int
+	}{
+		{"illegal move", "1. e4 e5 2. Ke3", true, 2},
+		{"illegal first move", "1. e5", true, 0},
+		{"invalid FEN tag", "[FEN \"8/8/8\"]\n\n1. e4", false, 0},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			err := HandlePGN(strings.NewReader(tc.notation), ioutil.Discard, col, chess.White, PGNOptions{})
+			var partial PartialError
+			if got := errors.As(err, &partial); err == nil || got != tc.wantPartial {
+				tt.Fatalf("want partial %v, got %v", tc.wantPartial, err)
+			}
+			if tc.wantPartial && partial.Moves != tc.wantMoves {
+				tt.Errorf("want %d moves, got %d", tc.wantMoves, partial.Moves)
+			}
+		})
+	}
+}
+
+func TestSolutionLines(t *testing.T) {
+	tcs := []struct {
+		name string
+		fen  string
+		n    int
+		want []string
+	}{
+		{
+			name: "mate in one",
+			fen:  "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 0 1",
+			n:    1,
+			want: []string{"1. Qxf7#!"},
+		},
+		{
+			name: "mate in two",
+			fen:  "2bqkbn1/2pppp2/np2N3/r3P1p1/p2N2B1/5Q2/PPPPKPP1/RNB2r2 w - - 0 1",
+			n:    2,
+			want: []string{"1. Qxf7+! Kxf7 2. Bh5#"},
+		},
+		{
+			name: "black to move",
+			fen:  "r5k1/8/8/8/8/8/5PPP/6K1 b - - 0 20",
+			n:    1,
+			want: []string{"20... Ra1#!"},
+		},
+		{
+			name: "too many variations",
+			fen:  "k7/8/2K5/8/8/8/8/1Q6 w - - 0 1",
+			n:    2,
+			want: []string{
+				"1. Qb2! Ka7 2. Qb7#",
+				"1. Qb3! Ka7 2. Qb7#",
+				"1. Qb4! Ka7 2. Qb7#",
+				"1. Qb5! Ka7 2. Qb7#",
+				"1. Qb7#!",
+				"1. Qh7! Kb8 2. Qb7#",
+				"1. Kc7! Ka7 2. Qb7#",
+				"and 2 more variations",
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := chess.ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			got := solutionLines(st, chess.SolveMate(st, tc.n))
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleFENAltText(t *testing.T) {
+	const pieces = "White: King e1, Rook h1. Black: King e8."
+	tcs := []struct {
+		name    string
+		fen     string
+		opts    FENOptions
+		wantAlt string
+		wantErr bool
+	}{
+		{
+			name:    "full FEN",
+			fen:     "4k3/8/8/8/8/8/8/4K2R b K - 0 1",
+			wantAlt: pieces + " Black to move.",
+		},
+		{
+			name:    "placement only",
+			fen:     "4k3/8/8/8/8/8/8/4K2R",
+			wantAlt: pieces + " White to move.",
+		},
+		{
+			name:    "malformed fields",
+			fen:     "4k3/8/8/8/8/8/8/4K2R b KQxx -",
+			wantAlt: pieces,
+		},
+		{
+			name:    "malformed fields with the check highlighted",
+			fen:     "4k3/8/8/8/8/8/8/4K2R b KQxx -",
+			opts:    FENOptions{Highlight: true},
+			wantErr: true,
+		},
+		{
+			name:    "malformed placement",
+			fen:     "4k3/8/8/8/8/8/8/4K2",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			alt := &strings.Builder{}
+			opts := tc.opts
+			opts.AltText = alt
+			err := HandleFEN(strings.NewReader(tc.fen), ioutil.Discard, pic.DefaultCollection, chess.White, opts)
+			if tc.wantErr {
+				if err == nil {
+					tt.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if alt.String() != tc.wantAlt {
+				tt.Errorf("want %q, got %q", tc.wantAlt, alt.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package chess2pic
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/xopoww/chess2pic/pkg/pic"
+)
+
+// LoadCollection opens a collection (see pic.OpenCollection) in the directory or the zip archive at name.
+// The images may also be in a single top directory of the archive.
+func LoadCollection(name string) (pic.Collection, error) {
+	var col pic.Collection
+	err := withFS(name, "board_white.png", func(fsys fs.FS, prefix string) (err error) {
+		col, err = pic.OpenCollection(fsys, prefix)
+		return err
+	})
+	return col, err
+}
+
+// LoadPieces opens the piece images (see pic.OpenPieces) in the directory or the zip archive at name
+// and returns them with the boards of board. If the pieces do not fit in its squares, the pic.PieceSizeError is returned
+// (see FitPieces to scale the board up instead).
+// If name is a PNG file, it is opened as a sprite sheet with the default layout (see LoadSprite).
+func LoadPieces(name string, board pic.Collection) (pic.Collection, error) {
+	if strings.EqualFold(path.Ext(name), ".png") {
+		return LoadSprite(name, pic.SpriteLayout{Board: board})
+	}
+	var col pic.Collection
+	err := withFS(name, path.Join("white", "pawn.png"), func(fsys fs.FS, prefix string) (err error) {
+		col, err = pic.OpenPieces(fsys, prefix, board)
+		return err
+	})
+	return col, err
+}
+
+// LoadSprite opens the sprite sheet of the pieces (see pic.OpenSpriteCollection) in the image file at name.
+// If the pieces do not fit in the squares of the board of the layout, the pic.PieceSizeError is returned.
+func LoadSprite(name string, layout pic.SpriteLayout) (pic.Collection, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	col, err := pic.OpenSpriteCollection(img, layout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return col, nil
+}
+
+// FitPieces returns the pieces returned by open with board. If they do not fit in the squares of board
+// (see pic.PieceSizeError), the board is scaled up to the smallest size they fit in and open is called again.
+// It is for the callers that opt into scaling the board, the scaling is logged.
+func FitPieces(board pic.Collection, open func(board pic.Collection) (pic.Collection, error)) (pic.Collection, error) {
+	col, err := open(board)
+	var serr pic.PieceSizeError
+	if !errors.As(err, &serr) {
+		return col, err
+	}
+	Infof("The board is scaled up from %dpx to %dpx to fit the %dpx pieces", serr.Board, serr.MinSize(), serr.Piece)
+	if board, err = pic.ScaleCollection(board, serr.MinSize()); err != nil {
+		return nil, err
+	}
+	return open(board)
+}
+
+// withFS calls f with the directory or the zip archive at name and the directory in it that contains the file probe.
+// Errors are prefixed with name.
+func withFS(name, probe string, f func(fsys fs.FS, prefix string) error) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	var fsys fs.FS
+	if info.IsDir() {
+		fsys = os.DirFS(name)
+	} else if strings.EqualFold(path.Ext(name), ".zip") {
+		zr, err := zip.OpenReader(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		defer zr.Close()
+		fsys = zr
+	} else {
+		return fmt.Errorf("%s: a directory or a zip archive is required", name)
+	}
+
+	if err := f(fsys, findPrefix(fsys, probe)); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// findPrefix returns "." if probe is in the root of fsys, or the single top directory that contains probe.
+func findPrefix(fsys fs.FS, probe string) string {
+	if _, err := fs.Stat(fsys, probe); err == nil {
+		return "."
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "."
+	}
+	var dirs []string
+	for _, e := range entries {
+		// archives made on macOS have a directory of metadata
+		if e.IsDir() && e.Name() != "__MACOSX" {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	if len(dirs) == 1 {
+		if _, err := fs.Stat(fsys, path.Join(dirs[0], probe)); err == nil {
+			return dirs[0]
+		}
+	}
+	return "."
+}
@@ -0,0 +1,232 @@
+package chess2pic
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/pic"
+	"github.com/xopoww/chess2pic/pkg/pic/pictest"
+)
+
+// writeDir writes the files into a new temporary directory and returns its path.
+func writeDir(t *testing.T, files fstest.MapFS) string {
+	dir := t.TempDir()
+	for name, f := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, f.Data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// writeZip writes the files into a new zip archive in a temporary directory and returns its path.
+// The names ending with a slash are directories.
+func writeZip(t *testing.T, files fstest.MapFS) string {
+	name := filepath.Join(t.TempDir(), "set.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, f := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(f.Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// merge returns the union of the file sets.
+func merge(sets ...fstest.MapFS) fstest.MapFS {
+	res := fstest.MapFS{}
+	for _, files := range sets {
+		for name, f := range files {
+			res[name] = f
+		}
+	}
+	return res
+}
+
+func TestLoadCollection(t *testing.T) {
+	tcs := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr string
+	}{
+		{
+			name: "directory",
+			path: func(t *testing.T) string { return writeDir(t, pictest.CollectionFS(".", 160, 20, nil)) },
+		},
+		{
+			name: "top directory",
+			path: func(t *testing.T) string { return writeDir(t, pictest.CollectionFS("set", 160, 20, nil)) },
+		},
+		{
+			name: "zip",
+			path: func(t *testing.T) string { return writeZip(t, pictest.CollectionFS("", 160, 20, nil)) },
+		},
+		{
+			name: "zip with top directory",
+			path: func(t *testing.T) string {
+				// archives made on macOS have a directory of metadata next to the top one
+				return writeZip(t, merge(pictest.CollectionFS("set", 160, 20, nil), fstest.MapFS{
+					"__MACOSX/":                      &fstest.MapFile{},
+					"__MACOSX/set/._board_white.png": &fstest.MapFile{Data: []byte("metadata")},
+				}))
+			},
+		},
+		{
+			name: "zip with two top directories",
+			path: func(t *testing.T) string {
+				return writeZip(t, merge(pictest.CollectionFS("set", 160, 20, nil), pictest.CollectionFS("other", 160, 20, nil)))
+			},
+			wantErr: "file does not exist",
+		},
+		{
+			name:    "pieces too big",
+			path:    func(t *testing.T) string { return writeZip(t, pictest.CollectionFS("", 160, 24, nil)) },
+			wantErr: "set.zip: board_white.png: piece image too big for the board",
+		},
+		{
+			name: "not an archive",
+			path: func(t *testing.T) string {
+				return filepath.Join(writeDir(t, fstest.MapFS{"set.tar": &fstest.MapFile{Data: []byte("tar")}}), "set.tar")
+			},
+			wantErr: "set.tar: a directory or a zip archive is required",
+		},
+		{
+			name:    "missing",
+			path:    func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing") },
+			wantErr: "missing",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			col, err := LoadCollection(tc.path(tt))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					tt.Fatalf("want error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if got := col.Board(chess.Black).Bounds().Dx(); got != 160 {
+				tt.Errorf("board: want 160, got %d", got)
+			}
+		})
+	}
+}
+
+func TestLoadPieces(t *testing.T) {
+	board := pic.DefaultCollection
+	bs := board.Board(chess.White).Bounds().Dx()
+	sprite := func(ps int) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			return filepath.Join(writeDir(t, fstest.MapFS{"sprite.png": {Data: pictest.PNG(6*ps, 2*ps)}}), "sprite.png")
+		}
+	}
+	tcs := []struct {
+		name      string
+		path      func(t *testing.T) string
+		fit       bool
+		wantBoard int
+		wantPiece int
+		wantErr   string
+	}{
+		{
+			name:      "directory",
+			path:      func(t *testing.T) string { return writeDir(t, pictest.CollectionFS(".", 0, 40, nil)) },
+			wantBoard: bs,
+			wantPiece: 40,
+		},
+		{
+			name:      "zip with top directory",
+			path:      func(t *testing.T) string { return writeZip(t, pictest.CollectionFS("pieces", 0, 40, nil)) },
+			wantBoard: bs,
+			wantPiece: 40,
+		},
+		{
+			name:    "pieces too big",
+			path:    func(t *testing.T) string { return writeZip(t, pictest.CollectionFS("", 0, 60, nil)) },
+			wantErr: "piece image too big for the board",
+		},
+		{
+			name:      "board scaled up",
+			path:      func(t *testing.T) string { return writeZip(t, pictest.CollectionFS("", 0, 60, nil)) },
+			fit:       true,
+			wantBoard: 480,
+			wantPiece: 60,
+		},
+		{
+			name:      "sprite sheet",
+			path:      sprite(30),
+			wantBoard: bs,
+			wantPiece: 30,
+		},
+		{
+			name:    "sprite sheet too big",
+			path:    sprite(60),
+			wantErr: "sprite.png: piece image too big for the board",
+		},
+		{
+			name:      "sprite sheet with board scaled up",
+			path:      sprite(60),
+			fit:       true,
+			wantBoard: 480,
+			wantPiece: 60,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			name := tc.path(tt)
+			open := func(board pic.Collection) (pic.Collection, error) {
+				return LoadPieces(name, board)
+			}
+			var (
+				col pic.Collection
+				err error
+			)
+			if tc.fit {
+				col, err = FitPieces(board, open)
+			} else {
+				col, err = open(board)
+			}
+			if tc.wantErr != "" {
+				var serr pic.PieceSizeError
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) || !errors.As(err, &serr) {
+					tt.Fatalf("want PieceSizeError %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if got := col.Board(chess.White).Bounds().Dx(); got != tc.wantBoard {
+				tt.Errorf("board: want %d, got %d", tc.wantBoard, got)
+			}
+			if got := col.Piece(chess.Piece{Color: chess.Black, Kind: chess.Queen}).Bounds().Dx(); got != tc.wantPiece {
+				tt.Errorf("piece: want %d, got %d", tc.wantPiece, got)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ package models
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -19,13 +20,35 @@ import (
 // swagger:model ApiResult
 type APIResult struct {
 
+	// Description of the image in English for screen readers
+	AltText string `json:"alt-text,omitempty"`
+
+	// Inaccuracies, mistakes and blunders of the game (for annotated PGN requests)
+	Annotations []*MoveAnnotation `json:"annotations,omitempty"`
+
 	// Human-readable description of an error
 	Error string `json:"error,omitempty"`
 
+	// Evaluation of the position from white's point of view, e.g. 0.35 or #-2 (for hint requests)
+	Eval string `json:"eval,omitempty"`
+
+	// PNG chart of the evaluation over the game in base64 encoding (if requested and available)
+	// Format: byte
+	EvalGraph strfmt.Base64 `json:"eval-graph,omitempty"`
+
+	// Suggested move in SAN (for hint requests)
+	Move string `json:"move,omitempty"`
+
 	// If ok is true, result is not empty, otherwise error is not empty
 	// Required: true
 	Ok *bool `json:"ok"`
 
+	// parse error
+	ParseError *ParseError `json:"parse-error,omitempty"`
+
+	// If partial is true, the game was rendered only up to an error in the notation (error is not empty)
+	Partial bool `json:"partial,omitempty"`
+
 	// Result image in base64 encoding
 	// Format: byte
 	Result strfmt.Base64 `json:"result,omitempty"`
@@ -35,16 +58,50 @@ type APIResult struct {
 func (m *APIResult) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAnnotations(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateOk(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateParseError(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *APIResult) validateAnnotations(formats strfmt.Registry) error {
+	if swag.IsZero(m.Annotations) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Annotations); i++ {
+		if swag.IsZero(m.Annotations[i]) { // not required
+			continue
+		}
+
+		if m.Annotations[i] != nil {
+			if err := m.Annotations[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("annotations" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("annotations" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *APIResult) validateOk(formats strfmt.Registry) error {
 
 	if err := validate.Required("ok", "body", m.Ok); err != nil {
@@ -54,8 +111,76 @@ func (m *APIResult) validateOk(formats strfmt.Registry) error {
 	return nil
 }
 
-// ContextValidate validates this Api result based on context it is used
+func (m *APIResult) validateParseError(formats strfmt.Registry) error {
+	if swag.IsZero(m.ParseError) { // not required
+		return nil
+	}
+
+	if m.ParseError != nil {
+		if err := m.ParseError.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("parse-error")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("parse-error")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ContextValidate validate this Api result based on the context it is used
 func (m *APIResult) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateAnnotations(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateParseError(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *APIResult) contextValidateAnnotations(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Annotations); i++ {
+
+		if m.Annotations[i] != nil {
+			if err := m.Annotations[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("annotations" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("annotations" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *APIResult) contextValidateParseError(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.ParseError != nil {
+		if err := m.ParseError.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("parse-error")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("parse-error")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -36,19 +36,35 @@ func (err InvalidSyntaxError) Error() string {
 }
 
 type algParser struct {
+	// partial makes Parse return the moves parsed before an error along with it
+	partial bool
+
 	state   int
 	lastNum int
-	nread   int
+	in      *inputReader
+	tokLoc  location // location of the first rune of the current token
 	pos     Position
 	movs    []Move
+	// comments[i] is the text of the comments following movs[i]
+	comments []string
 }
 
 func Algebraic() MoveParser {
 	return &algParser{}
 }
 
+// fail returns the result of Parse in case of err at loc.
+func (ap *algParser) fail(loc location, err error) ([]Move, error) {
+	err = ap.in.errorAt(loc, err)
+	if ap.partial {
+		return ap.movs, err
+	}
+	return nil, err
+}
+
 func (ap *algParser) addMove(mov Move) {
 	ap.movs = append(ap.movs, mov)
+	ap.comments = append(ap.comments, "")
 	ap.pos = Apply(ap.pos, mov)
 }
 
@@ -61,20 +77,20 @@ func (ap *algParser) handleNumber(cs []rune) error {
 			s = strings.TrimSuffix(s, "...")
 			nextState = black
 		} else {
-			return InvalidSyntaxError{At: ap.nread - len(s), Reason: "unexpected \"...\" in this context"}
+			return InvalidSyntaxError{At: ap.tokLoc.offset, Reason: "unexpected \"...\" in this context"}
 		}
 	} else if strings.HasSuffix(s, ".") {
 		s = strings.TrimSuffix(s, ".")
 	} else {
-		return InvalidSyntaxError{At: ap.nread - len(s), Reason: fmt.Sprintf("invalid move number notation: %q", s)}
+		return InvalidSyntaxError{At: ap.tokLoc.offset, Reason: fmt.Sprintf("invalid move number notation: %q", s)}
 	}
 
 	num, err := strconv.ParseUint(s, 10, 0)
 	if err != nil {
-		return InvalidSyntaxError{At: ap.nread - len(s), Reason: fmt.Sprintf("invalid move number notation: %q", s)}
+		return InvalidSyntaxError{At: ap.tokLoc.offset, Reason: fmt.Sprintf("invalid move number notation: %q", s)}
 	}
 	if ap.lastNum > 0 && ap.lastNum+1 != int(num) {
-		return InvalidSyntaxError{At: ap.nread - len(s), Reason: fmt.Sprintf("expected move #%d, got #%d", ap.lastNum+1, num)}
+		return InvalidSyntaxError{At: ap.tokLoc.offset, Reason: fmt.Sprintf("expected move #%d, got #%d", ap.lastNum+1, num)}
 	}
 
 	ap.lastNum = int(num)
@@ -83,6 +99,19 @@ func (ap *algParser) handleNumber(cs []rune) error {
 	return nil
 }
 
+// handleBlackNumber handles the move number repeated before black's move (e.g. "1. e4 {comment} 1... e5").
+func (ap *algParser) handleBlackNumber(cs []rune) error {
+	s := strings.TrimSuffix(string(cs), "...")
+	num, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return InvalidSyntaxError{At: ap.tokLoc.offset, Reason: fmt.Sprintf("invalid move number notation: %q", string(cs))}
+	}
+	if int(num) != ap.lastNum {
+		return InvalidSyntaxError{At: ap.tokLoc.offset, Reason: fmt.Sprintf("expected move #%d, got #%d", ap.lastNum, num)}
+	}
+	return nil
+}
+
 func pieceByLetter(letter rune) PieceKind {
 	switch letter {
 	case 'R':
@@ -100,233 +129,35 @@ func pieceByLetter(letter rune) PieceKind {
 	}
 }
 
-func findSources(pos Position, p Piece, destination Square, capture bool) []Square {
-	sources := make([]Square, 0)
-
-	// find king for pinned pieces checks
-	var king Square
-findKing:
-	for file := range pos {
-		for rank := range pos[file] {
-			sq := MustNewSquare(file, rank)
-			pp := pos.Get(sq)
-			if pp.Kind == King && pp.Color == p.Color {
-				king = sq
-				break findKing
-			}
-		}
-	}
-
-	isPinned := func(sq Square) bool {
-		if p.Kind == King {
-			return false
-		}
-
-		// check lateral pin (same file)
-		if king.file == sq.file && king.file != destination.file {
-			var dr int
-			if king.rank < sq.rank {
-				dr = 1
-			} else {
-				dr = -1
-			}
-			for rank := sq.rank + dr; rank >= 0 && rank <= 7; rank += dr {
-				pp := pos.Get(MustNewSquare(sq.file, rank))
-				if pp.Color != p.Color && (pp.Kind == Rook || pp.Kind == Queen) {
-					return true
-				} else if pp.Kind != None {
-					break
-				}
-			}
-			// check lateral pin (same rank)
-		} else if king.rank == sq.rank && king.rank != destination.rank {
-			var df int
-			if king.file < sq.file {
-				df = 1
-			} else {
-				df = -1
-			}
-			for file := sq.file + df; file >= 0 && file <= 7; file += df {
-				pp := pos.Get(MustNewSquare(file, sq.rank))
-				if pp.Color != p.Color && (pp.Kind == Rook || pp.Kind == Queen) {
-					return true
-				} else if pp.Kind != None {
-					break
-				}
-			}
-			// check diagonal pin
-		} else if OnDiag(king, sq) {
-			var (
-				dr int
-				df int
-			)
-			if king.rank < sq.rank {
-				dr = 1
-			} else {
-				dr = -1
-			}
-			if king.file < sq.file {
-				df = 1
-			} else {
-				df = -1
-			}
-			// check that the piece leaves the diagonal
-			leftDiag := !OnDiag(king, destination) || ((king.rank-destination.rank)*(king.file-destination.file))*(dr*df) < 0
-			if leftDiag {
-				for d := 1; ; d++ {
-					ssq, err := NewSquare(sq.file+d*df, sq.rank+d*dr)
-					if err != nil {
-						break
-					}
-					pp := pos.Get(ssq)
-					if pp.Color != p.Color && (pp.Kind == Bishop || pp.Kind == Queen) {
-						return true
-					} else if pp.Kind != None {
-						break
-					}
-				}
-			}
-		}
-		return false
-	}
-
-	addSource := func(file, rank int) {
-		sq, err := NewSquare(file, rank)
-		if err != nil {
-			return
-		}
-
-		if pos.Get(sq) != p {
-			return
-		}
-
-		sources = append(sources, sq)
-	}
-
-	addDiagonal := func() {
-		for df := -1; df <= 1; df += 2 {
-			for dr := -1; dr <= 1; dr += 2 {
-				d := 0
-				for {
-					d++
-					source, err := NewSquare(destination.file+df*d, destination.rank+dr*d)
-					if err != nil {
-						break
-					}
-					pp := pos.Get(source)
-					if p == pp {
-						sources = append(sources, source)
-						break
-					} else if pp.Kind != None {
-						break
-					}
-				}
-			}
-		}
-	}
-
-	addLateral := func() {
-		for df := -1; df <= 1; df += 2 {
-			d := 0
-			for {
-				d++
-				source, err := NewSquare(destination.file+df*d, destination.rank)
-				if err != nil {
-					break
-				}
-				pp := pos.Get(source)
-				if p == pp {
-					sources = append(sources, source)
-					break
-				} else if pp.Kind != None {
-					break
-				}
-			}
-		}
-		for dr := -1; dr <= 1; dr += 2 {
-			d := 0
-			for {
-				d++
-				source, err := NewSquare(destination.file, destination.rank+dr*d)
-				if err != nil {
-					break
-				}
-				pp := pos.Get(source)
-				if p == pp {
-					sources = append(sources, source)
-					break
-				} else if pp.Kind != None {
-					break
-				}
+// findSources returns the squares from which the piece p can legally make mov (with the source square unknown).
+func findSources(pos Position, p Piece, mov Move, capture bool) []Square {
+	var candidates []Square
+	if p.Kind == Pawn && !capture {
+		dRank := -pawnDir(p.Color)
+		if sq, ok := mov.To.offset(0, dRank); ok {
+			if pos.Get(sq) == p {
+				candidates = append(candidates, sq)
+			} else if pos.Get(sq).Kind == None && (p.Color == White && mov.To.rank == 3 || p.Color == Black && mov.To.rank == 4) {
+				candidates = append(candidates, MustNewSquare(mov.To.file, mov.To.rank+2*dRank))
 			}
 		}
+	} else {
+		// pawns attack the squares they capture on, so Attackers finds them too
+		candidates = pos.Attackers(mov.To, p.Color)
 	}
 
-	switch p.Kind {
-	case Pawn:
-		if capture {
-			var rank int
-			if p.Color == White {
-				rank = destination.rank - 1
-			} else {
-				rank = destination.rank + 1
-			}
-			for df := -1; df <= 1; df += 2 {
-				addSource(destination.file+df, rank)
-			}
-		} else {
-			var dRank int
-			if p.Color == White {
-				dRank = -1
-			} else {
-				dRank = 1
-			}
-			sq, err := NewSquare(destination.file, destination.rank+dRank)
-			if err != nil {
-				break
-			}
-			pp := pos.Get(sq)
-			if pp == p {
-				sources = append(sources, sq)
-				break
-			} else if pp.Kind == None && (p.Color == White && destination.rank == 3 || p.Color == Black && destination.rank == 4) {
-				addSource(destination.file, destination.rank+dRank*2)
-			}
-		}
-	case Knight:
-		addSource(destination.file-1, destination.rank-2)
-		addSource(destination.file+1, destination.rank-2)
-		addSource(destination.file-1, destination.rank+2)
-		addSource(destination.file+1, destination.rank+2)
-		addSource(destination.file-2, destination.rank-1)
-		addSource(destination.file+2, destination.rank-1)
-		addSource(destination.file-2, destination.rank+1)
-		addSource(destination.file+2, destination.rank+1)
-	case Bishop:
-		addDiagonal()
-	case Rook:
-		addLateral()
-	case Queen:
-		addDiagonal()
-		addLateral()
-	case King:
-		for df := -1; df <= 1; df++ {
-			for dr := -1; dr <= 1; dr++ {
-				if df == 0 && dr == 8 {
-					continue
-				}
-				addSource(destination.file+df, destination.rank+dr)
-			}
-		}
+	st := State{Position: pos, ToMove: p.Color}
+	if mov.EnPassant {
+		st.EnPassant, st.HasEnPassant = mov.To, true
 	}
-
-	filteredSources := make([]Square, 0)
-	for _, sq := range sources {
-		if !isPinned(sq) {
-			filteredSources = append(filteredSources, sq)
+	sources := make([]Square, 0, len(candidates))
+	for _, sq := range candidates {
+		mov.From = sq
+		if pos.Get(sq) == p && st.IsLegal(mov) {
+			sources = append(sources, sq)
 		}
 	}
-	return filteredSources
+	return sources
 }
 
 func (ap *algParser) handleMove(cs []rune) error {
@@ -470,7 +301,7 @@ func (ap *algParser) handleMove(cs []rune) error {
 	}
 
 	// look for potential source squares
-	sources := findSources(ap.pos, p, mov.To, capture)
+	sources := findSources(ap.pos, p, mov, capture)
 	// look for source disambiguation hints
 	var (
 		sFile int = -1
@@ -522,6 +353,19 @@ func (ap *algParser) handleMove(cs []rune) error {
 	return nil
 }
 
+// addComment attaches a comment to the last parsed move. Comments preceding the first move are dropped.
+func (ap *algParser) addComment(text string) {
+	text = strings.TrimSpace(text)
+	if len(ap.comments) == 0 || text == "" {
+		return
+	}
+	if last := &ap.comments[len(ap.comments)-1]; *last == "" {
+		*last = text
+	} else {
+		*last += " " + text
+	}
+}
+
 func (ap *algParser) handle(cs []rune) error {
 	// check for game result
 	s := string(cs)
@@ -533,6 +377,10 @@ func (ap *algParser) handle(cs []rune) error {
 		}
 	}
 
+	if ap.state == black && strings.HasSuffix(s, "...") {
+		return ap.handleBlackNumber(cs)
+	}
+
 	switch ap.state {
 	case number:
 		return ap.handleNumber(cs)
@@ -545,38 +393,53 @@ func (ap *algParser) handle(cs []rune) error {
 }
 
 func (ap *algParser) Parse(start Position, r io.RuneReader) ([]Move, error) {
+	ap.in = newInputReader(r)
 	ap.pos = start
 	ap.movs = make([]Move, 0)
+	ap.comments = nil
 
-	ap.nread = -1
 	ap.state = number
 	ap.lastNum = -1
 	comment := false
+	var text []rune
 
 	var cs []rune
 
 	for {
-		c, _, err := r.ReadRune()
+		c, _, err := ap.in.ReadRune()
 		// handle EOF
 		if errors.Is(err, io.EOF) {
 			// handle leftover string
 			if len(cs) > 0 {
 				if err := ap.handle(cs); err != nil {
-					return nil, err
+					return ap.fail(ap.tokLoc, err)
 				}
 			}
 			return ap.movs, nil
 		}
-		ap.nread++
+		if err != nil {
+			return nil, fmt.Errorf("ReadRune: %w", err)
+		}
 
 		// handle comments
 		if comment {
 			if c == '}' {
 				comment = false
+				ap.addComment(string(text))
+			} else {
+				text = append(text, c)
 			}
 			continue
 		} else if c == '{' {
+			// the comment may follow a token without whitespace
+			if len(cs) > 0 {
+				if err := ap.handle(cs); err != nil {
+					return ap.fail(ap.tokLoc, err)
+				}
+				cs = cs[:0]
+			}
 			comment = true
+			text = text[:0]
 			continue
 		}
 
@@ -585,7 +448,7 @@ func (ap *algParser) Parse(start Position, r io.RuneReader) ([]Move, error) {
 			// if there is a string collected - handle it
 			if len(cs) > 0 {
 				if err := ap.handle(cs); err != nil {
-					return nil, err
+					return ap.fail(ap.tokLoc, err)
 				}
 				cs = cs[:0]
 			}
@@ -593,19 +456,23 @@ func (ap *algParser) Parse(start Position, r io.RuneReader) ([]Move, error) {
 		}
 
 		// handle a character according to the state
-		
+
 		// consider game result characters allowed for every state
 		// because it seems to be the easiest workaround
 		allowedRunes := map[int]string{
 			number: "1234567890." + "/-*",
 			white:  "RNBQK" + "abcdefgh" + "12345678" + "x+#=" + "O-" + "102/-*",
-			black:  "RNBQK" + "abcdefgh" + "12345678" + "x+#=" + "O-" + "102/-*",
+			black:  "RNBQK" + "abcdefgh" + "12345678" + "x+#=" + "O-" + "102/-*" + "0123456789.",
 		}
 
 		if strings.ContainsRune(allowedRunes[ap.state], c) {
+			if len(cs) == 0 {
+				ap.tokLoc = ap.in.lastLocation()
+			}
 			cs = append(cs, c)
 		} else {
-			return nil, InvalidSyntaxError{At: ap.nread, Reason: fmt.Sprintf("unexpected %U in this context", c)}
+			at := ap.in.lastLocation()
+			return ap.fail(at, InvalidSyntaxError{At: at.offset, Reason: fmt.Sprintf("unexpected %U in this context", c)})
 		}
 	}
-}
\ No newline at end of file
+}
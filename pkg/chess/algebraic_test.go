@@ -229,6 +229,59 @@ func TestAlgParserParse(t *testing.T) {
 				{from: "c4", to: "d3"},
 			},
 		},
+		{
+			name:     "diagonal pin (blocked)",
+			start:    "1k6/8/8/8/1b6/2N5/3P4/4K3",
+			notation: "1. Nd5",
+			want: []move{
+				{from: "c3", to: "d5"},
+			},
+		},
+		{
+			name:     "lateral pin (blocked)",
+			start:    "1k2r3/8/8/8/4N3/8/4P3/4K3",
+			notation: "1. Nf6",
+			want: []move{
+				{from: "e4", to: "f6"},
+			},
+		},
+		{
+			name:     "king capture",
+			start:    "1k6/8/8/8/8/8/3r4/2RK4",
+			notation: "1. Kxd2",
+			want: []move{
+				{from: "d1", to: "d2"},
+			},
+		},
+		{
+			name:     "king into check",
+			start:    "1k6/3r4/8/8/8/8/8/4K3",
+			notation: "1. Kd1",
+			wantErr:  true,
+		},
+		{
+			name:     "pinned en passant",
+			start:    "1k6/8/8/r2pP2K/8/8/8/8",
+			notation: "1. exd6",
+			wantErr:  true,
+		},
+		{
+			name:     "repeated move number",
+			start:    "4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3",
+			notation: "1. e4 {comment} 1... e5 2. d4 2... d5",
+			want: []move{
+				{from: "e2", to: "e4"},
+				{from: "e7", to: "e5"},
+				{from: "d2", to: "d4"},
+				{from: "d7", to: "d5"},
+			},
+		},
+		{
+			name:     "wrong repeated move number",
+			start:    "4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3",
+			notation: "1. e4 2... e5",
+			wantErr:  true,
+		},
 	}
 
 	for _, tc := range tcs {
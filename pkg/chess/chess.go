@@ -72,6 +72,16 @@ func (sq Square) String() string {
 	return fmt.Sprintf("%c%d", 'a'+sq.file, sq.rank+1)
 }
 
+// File returns the file of the square from 0 ("A" file) to 7 ("H" file).
+func (sq Square) File() int {
+	return sq.file
+}
+
+// Rank returns the rank of the square from 0 (1st rank) to 7 (8th rank).
+func (sq Square) Rank() int {
+	return sq.rank
+}
+
 // NewSquare creates Square from file and rank coordinates.
 // Both coordinates are represented as integers from 0 ("A" file or 1st rank) to 7 ("H" file or 8th rank).
 // If either of coordinates falls out of this range, an error is returned.
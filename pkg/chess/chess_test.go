@@ -153,3 +153,14 @@ func TestApply(t *testing.T) {
 		})
 	}
 }
+
+func TestSquareFileRank(t *testing.T) {
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			sq := MustNewSquare(file, rank)
+			if sq.File() != file || sq.Rank() != rank {
+				t.Errorf("%s: want (%d, %d), got (%d, %d)", sq, file, rank, sq.File(), sq.Rank())
+			}
+		}
+	}
+}
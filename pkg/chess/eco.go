@@ -0,0 +1,74 @@
+package chess
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Opening is a named opening line from the Encyclopaedia of Chess Openings.
+type Opening struct {
+	ECO  string
+	Name string
+}
+
+func (op Opening) String() string {
+	return op.ECO + " " + op.Name
+}
+
+// ecoTable is a tab-separated list of openings (code, name and moves from the starting position).
+//
+//go:embed assets/eco.tsv
+var ecoTable string
+
+var (
+	ecoOnce      sync.Once
+	ecoPositions map[Key]Opening
+)
+
+func loadECO() {
+	ecoPositions = make(map[Key]Opening)
+	// skip the header
+	lines := strings.Split(strings.TrimSpace(ecoTable), "\n")[1:]
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			panic(fmt.Sprintf("malformed ECO table line: %q", line))
+		}
+		movs, err := Algebraic().Parse(StartingPosition(), strings.NewReader(fields[2]))
+		if err != nil {
+			panic(fmt.Sprintf("malformed ECO table line: %q: %s", line, err))
+		}
+		st := StartingState()
+		for _, mov := range movs {
+			st = st.Apply(mov)
+		}
+		// when several lines lead to the same position, the first one is used
+		if _, exists := ecoPositions[st.Key()]; !exists {
+			ecoPositions[st.Key()] = Opening{ECO: fields[0], Name: fields[1]}
+		}
+	}
+}
+
+// ClassifyOpening finds the deepest position of the game (played from the standard starting position)
+// that is present in the opening table and returns the corresponding opening.
+// Positions are matched regardless of the move order, so transpositions are recognized.
+// If no position of the game is found in the table, false is returned.
+func ClassifyOpening(moves []Move) (Opening, bool) {
+	ecoOnce.Do(loadECO)
+
+	var (
+		op    Opening
+		found bool
+	)
+	st := StartingState()
+	for _, mov := range moves {
+		st = st.Apply(mov)
+		if o, exists := ecoPositions[st.Key()]; exists {
+			op = o
+			found = true
+		}
+	}
+	return op, found
+}
@@ -0,0 +1,83 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyOpening(t *testing.T) {
+	tcs := []struct {
+		name     string
+		notation string
+		want     Opening
+		wantOk   bool
+	}{
+		{
+			name:     "exact line",
+			notation: "1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6",
+			want:     Opening{"B90", "Sicilian Defense: Najdorf Variation"},
+			wantOk:   true,
+		},
+		{
+			name:     "out of book",
+			notation: "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7 11. Kh1 Kh8",
+			want:     Opening{"C95", "Ruy Lopez: Morphy Defense, Breyer Defense, Zaitsev Hybrid"},
+			wantOk:   true,
+		},
+		{
+			name:     "transposition",
+			notation: "1. Nf3 d5 2. d4",
+			want:     Opening{"D02", "Queen's Pawn Game: Zukertort Variation"},
+			wantOk:   true,
+		},
+		{
+			name:     "transposition to a later line",
+			notation: "1. c4 Nf6 2. Nc3 e6 3. d4 Bb4",
+			want:     Opening{"E20", "Nimzo-Indian Defense"},
+			wantOk:   true,
+		},
+		{
+			name:     "leaves the table",
+			notation: "1. e4 e5 2. Ke2 h5",
+			want:     Opening{"C20", "Bongcloud Attack"},
+			wantOk:   true,
+		},
+		{
+			name:     "not found",
+			notation: "",
+			wantOk:   false,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			movs, err := Algebraic().Parse(StartingPosition(), strings.NewReader(tc.notation))
+			if err != nil {
+				panic(err)
+			}
+			got, ok := ClassifyOpening(movs)
+			if ok != tc.wantOk {
+				tt.Fatalf("want ok = %t, got %t", tc.wantOk, ok)
+			}
+			if got != tc.want {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestECOTable(t *testing.T) {
+	ecoOnce.Do(loadECO)
+	// every line of the table must be reachable
+	lines := strings.Split(strings.TrimSpace(ecoTable), "\n")[1:]
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		movs, err := Algebraic().Parse(StartingPosition(), strings.NewReader(fields[2]))
+		if err != nil {
+			t.Fatalf("%q: %s", line, err)
+		}
+		if _, ok := ClassifyOpening(movs); !ok {
+			t.Errorf("%q: not found", line)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package engine implements a small chess engine: alpha-beta search with iterative deepening, quiescence search
+// and a transposition table over an evaluation by material and piece-square tables.
+// It is far weaker than dedicated engines, but is good enough to suggest a reasonable move.
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/chess/polyglot"
+)
+
+const (
+	// MaxDepth is the maximum depth of the search in plies.
+	MaxDepth = 64
+	// DefaultMoveTime limits the search if no limits are set.
+	DefaultMoveTime = time.Second
+
+	infinity  = 1 << 20
+	mateScore = 1 << 16
+	// scores above mateThreshold mean forced checkmate
+	mateThreshold = mateScore - 2*MaxDepth
+
+	// maxTableSize is the number of transposition table entries after which the table is cleared.
+	maxTableSize = 1 << 20
+	// checkInterval is the number of nodes between the checks of the time limit and the context.
+	checkInterval = 1024
+)
+
+// ErrNoMoves is returned by Search if the side to move has no legal moves.
+var ErrNoMoves = errors.New("no legal moves")
+
+// Limits restrict the search. If both are zero, MoveTime defaults to DefaultMoveTime.
+type Limits struct {
+	// Depth is the maximum depth in plies (0 means MaxDepth).
+	Depth int
+	// MoveTime is the maximum duration of the search (0 means no limit).
+	MoveTime time.Duration
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	Move chess.Move
+	// PV is the principal variation starting with Move.
+	PV []chess.Move
+	// Eval is the evaluation of the position from white's point of view.
+	Eval chess.Eval
+	// Depth is the depth of the last completed iteration.
+	Depth int
+	Nodes int
+}
+
+type boundKind uint8
+
+const (
+	exact boundKind = iota
+	lowerBound
+	upperBound
+)
+
+type tableEntry struct {
+	depth int
+	score int
+	bound boundKind
+	move  chess.Move
+}
+
+// Engine searches for the best move. It keeps the transposition table between searches,
+// so searching the positions of the same game is faster. Engine is not safe for concurrent use.
+type Engine struct {
+	table map[uint64]tableEntry
+
+	ctx      context.Context
+	deadline time.Time
+	nodes    int
+	// stopped is set when the search must be aborted
+	stopped bool
+	// canStop is false until the first iteration is completed, so that there is always a move to return
+	canStop bool
+	// path contains the keys of the positions from the root to the current node
+	path     []uint64
+	rootBest chess.Move
+}
+
+func New() *Engine {
+	return &Engine{table: make(map[uint64]tableEntry)}
+}
+
+// Search finds the best move in the state. If ctx is done, the search is stopped and the result
+// of the last completed iteration is returned.
+func (e *Engine) Search(ctx context.Context, st chess.State, limits Limits) (Result, error) {
+	if len(st.LegalMoves()) == 0 {
+		return Result{}, ErrNoMoves
+	}
+	if limits.Depth <= 0 || limits.Depth > MaxDepth {
+		limits.Depth = MaxDepth
+	}
+	if limits.MoveTime == 0 && limits.Depth == MaxDepth {
+		limits.MoveTime = DefaultMoveTime
+	}
+
+	e.ctx = ctx
+	e.deadline = time.Time{}
+	if limits.MoveTime > 0 {
+		e.deadline = time.Now().Add(limits.MoveTime)
+	}
+	e.nodes = 0
+	e.stopped = false
+	e.canStop = false
+	if len(e.table) > maxTableSize {
+		e.table = make(map[uint64]tableEntry)
+	}
+
+	res := Result{}
+	for depth := 1; depth <= limits.Depth; depth++ {
+		if e.canStop && e.expired() {
+			break
+		}
+		e.path = e.path[:0]
+		score := e.negamax(st, depth, 0, -infinity, infinity)
+		if e.stopped {
+			break
+		}
+		e.canStop = true
+
+		res.Move = e.rootBest
+		res.Depth = depth
+		res.Eval = toEval(score, st.ToMove)
+		res.PV = e.principalVariation(st, depth)
+		// there is no need to search deeper if a forced mate is found
+		if score > mateThreshold || score < -mateThreshold {
+			break
+		}
+	}
+	res.Nodes = e.nodes
+	return res, nil
+}
+
+// shouldStop reports whether the search must be aborted (it checks the limits only once in a while).
+func (e *Engine) shouldStop() bool {
+	if e.stopped {
+		return true
+	}
+	if !e.canStop || e.nodes%checkInterval != 0 {
+		return false
+	}
+	e.stopped = e.expired()
+	return e.stopped
+}
+
+func (e *Engine) expired() bool {
+	return e.ctx.Err() != nil || (!e.deadline.IsZero() && time.Now().After(e.deadline))
+}
+
+func (e *Engine) negamax(st chess.State, depth, ply int, alpha, beta int) int {
+	e.nodes++
+	if e.shouldStop() {
+		return 0
+	}
+
+	key := polyglot.Key(st)
+	if ply > 0 && (st.HalfmoveClock >= 100 || e.repeated(key)) {
+		return 0
+	}
+
+	inCheck := st.InCheck()
+	if inCheck {
+		// do not stop the search in the middle of a forcing sequence
+		depth++
+	}
+	if depth <= 0 {
+		return e.quiesce(st, ply, alpha, beta)
+	}
+
+	var ttMove chess.Move
+	if entry, ok := e.table[key]; ok {
+		ttMove = entry.move
+		if entry.depth >= depth && ply > 0 {
+			score := scoreFromTable(entry.score, ply)
+			switch {
+			case entry.bound == exact,
+				entry.bound == lowerBound && score >= beta,
+				entry.bound == upperBound && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	movs := st.LegalMoves()
+	if len(movs) == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+	orderMoves(st, movs, ttMove)
+
+	e.path = append(e.path, key)
+	defer func() { e.path = e.path[:len(e.path)-1] }()
+
+	origAlpha := alpha
+	best, bestMove := -infinity, movs[0]
+	for _, mov := range movs {
+		score := -e.negamax(st.Apply(mov), depth-1, ply+1, -beta, -alpha)
+		if e.stopped {
+			return 0
+		}
+		if score > best {
+			best, bestMove = score, mov
+			if ply == 0 {
+				e.rootBest = mov
+			}
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	entry := tableEntry{depth: depth, score: scoreToTable(best, ply), move: bestMove, bound: exact}
+	if best <= origAlpha {
+		entry.bound = upperBound
+	} else if best >= beta {
+		entry.bound = lowerBound
+	}
+	e.table[key] = entry
+	return best
+}
+
+// quiesce searches only captures and promotions until the position is quiet, so that the static evaluation
+// is not done in the middle of an exchange.
+func (e *Engine) quiesce(st chess.State, ply int, alpha, beta int) int {
+	e.nodes++
+	if e.shouldStop() {
+		return 0
+	}
+
+	standPat := evaluate(st)
+	if standPat >= beta {
+		return standPat
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	var movs []chess.Move
+	for _, mov := range st.LegalMoves() {
+		if isTactical(st, mov) {
+			movs = append(movs, mov)
+		}
+	}
+	orderMoves(st, movs, chess.Move{})
+	for _, mov := range movs {
+		score := -e.quiesce(st.Apply(mov), ply+1, -beta, -alpha)
+		if e.stopped {
+			return 0
+		}
+		if score >= beta {
+			return score
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+func (e *Engine) repeated(key uint64) bool {
+	for _, k := range e.path {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// principalVariation follows the best moves stored in the transposition table.
+func (e *Engine) principalVariation(st chess.State, depth int) []chess.Move {
+	pv := []chess.Move{e.rootBest}
+	seen := map[uint64]bool{polyglot.Key(st): true}
+	st = st.Apply(e.rootBest)
+	for len(pv) < depth {
+		key := polyglot.Key(st)
+		entry, ok := e.table[key]
+		if !ok || seen[key] || !st.IsLegal(entry.move) {
+			break
+		}
+		seen[key] = true
+		pv = append(pv, entry.move)
+		st = st.Apply(entry.move)
+	}
+	return pv
+}
+
+func isTactical(st chess.State, mov chess.Move) bool {
+	return mov.EnPassant || mov.Promotion.Kind != chess.None || st.Position.Get(mov.To).Kind != chess.None
+}
+
+// orderMoves sorts the moves so that the most promising are searched first: the best move from the
+// transposition table, then captures of the most valuable pieces by the least valuable ones and promotions.
+func orderMoves(st chess.State, movs []chess.Move, ttMove chess.Move) {
+	priority := func(mov chess.Move) int {
+		if mov == ttMove {
+			return infinity
+		}
+		p := 0
+		if victim := st.Position.Get(mov.To); victim.Kind != chess.None {
+			p += 10*pieceValues[victim.Kind] - pieceValues[st.Position.Get(mov.From).Kind]/10
+		}
+		if mov.Promotion.Kind != chess.None {
+			p += pieceValues[mov.Promotion.Kind]
+		}
+		return p
+	}
+	sort.SliceStable(movs, func(i, j int) bool {
+		return priority(movs[i]) > priority(movs[j])
+	})
+}
+
+// Mate scores are stored in the table relative to the node, not to the root.
+
+func scoreToTable(score, ply int) int {
+	switch {
+	case score > mateThreshold:
+		return score + ply
+	case score < -mateThreshold:
+		return score - ply
+	}
+	return score
+}
+
+func scoreFromTable(score, ply int) int {
+	switch {
+	case score > mateThreshold:
+		return score - ply
+	case score < -mateThreshold:
+		return score + ply
+	}
+	return score
+}
+
+// toEval converts a score of the side to move to chess.Eval.
+func toEval(score int, toMove chess.PieceColor) chess.Eval {
+	var e chess.Eval
+	switch {
+	case score > mateThreshold:
+		e.Mate = (mateScore - score + 1) / 2
+	case score < -mateThreshold:
+		e.Mate = -(mateScore + score + 1) / 2
+	default:
+		e.Centipawns = score
+	}
+	if toMove == chess.Black {
+		e.Centipawns, e.Mate = -e.Centipawns, -e.Mate
+	}
+	return e
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func parseFEN(tt *testing.T, fen string) chess.State {
+	st, err := chess.ParseFEN(strings.NewReader(fen))
+	if err != nil {
+		tt.Fatalf("parse fen: %s", err)
+	}
+	return st
+}
+
+func TestSearch(t *testing.T) {
+	tcs := []struct {
+		name     string
+		fen      string
+		limits   Limits
+		wantSAN  string // empty if any move is fine
+		notSAN   string
+		wantMate int
+	}{
+		{
+			name:     "mate in 1",
+			fen:      "6k1/5ppp/8/8/8/8/8/R3K3 w Q - 0 1",
+			limits:   Limits{Depth: 3},
+			wantSAN:  "Ra8#",
+			wantMate: 1,
+		},
+		{
+			name:     "mate in 2",
+			fen:      "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1",
+			limits:   Limits{Depth: 5},
+			wantMate: 2,
+		},
+		{
+			name:     "black mates",
+			fen:      "r5k1/8/8/8/8/8/5PPP/6K1 b - - 0 1",
+			limits:   Limits{Depth: 3},
+			wantSAN:  "Ra1#",
+			wantMate: -1,
+		},
+		{
+			name:    "hanging queen",
+			fen:     "4k3/8/8/3q4/8/2N5/8/4K3 w - - 0 1",
+			limits:  Limits{Depth: 3},
+			wantSAN: "Nxd5",
+		},
+		{
+			name:   "poisoned pawn",
+			fen:    "4k3/2p5/3p4/8/8/8/8/3QK3 w - - 0 1",
+			limits: Limits{Depth: 3},
+			notSAN: "Qxd6",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st := parseFEN(tt, tc.fen)
+			res, err := New().Search(context.Background(), st, tc.limits)
+			if err != nil {
+				tt.Fatalf("search: %s", err)
+			}
+			san := st.SAN(res.Move)
+			if tc.wantSAN != "" && san != tc.wantSAN {
+				tt.Errorf("want %s, got %s (%s)", tc.wantSAN, san, res.Eval)
+			}
+			if san == tc.notSAN {
+				tt.Errorf("%s is a mistake", san)
+			}
+			if res.Eval.Mate != tc.wantMate {
+				tt.Errorf("want mate %d, got %s", tc.wantMate, res.Eval)
+			}
+			if len(res.PV) == 0 || res.PV[0] != res.Move {
+				tt.Errorf("principal variation must start with the move, got %v", res.PV)
+			}
+			pst := st
+			for _, mov := range res.PV {
+				if !pst.IsLegal(mov) {
+					tt.Fatalf("illegal move in the principal variation: %s", mov)
+				}
+				pst = pst.Apply(mov)
+			}
+		})
+	}
+}
+
+func TestSearchNoMoves(t *testing.T) {
+	st := parseFEN(t, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	if _, err := New().Search(context.Background(), st, Limits{}); !errors.Is(err, ErrNoMoves) {
+		t.Errorf("want ErrNoMoves, got %v", err)
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	st := chess.StartingState()
+
+	start := time.Now()
+	res, err := New().Search(context.Background(), st, Limits{MoveTime: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("search: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("search took %s", elapsed)
+	}
+	if !st.IsLegal(res.Move) {
+		t.Errorf("illegal move %s", res.Move)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = New().Search(ctx, st, Limits{})
+	if err != nil {
+		t.Fatalf("search: %s", err)
+	}
+	// the first iteration is always completed
+	if res.Depth != 1 || !st.IsLegal(res.Move) {
+		t.Errorf("want a legal move at depth 1, got %s at depth %d", res.Move, res.Depth)
+	}
+}
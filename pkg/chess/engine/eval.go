@@ -0,0 +1,140 @@
+package engine
+
+import "github.com/xopoww/chess2pic/pkg/chess"
+
+// Piece values in centipawns.
+var pieceValues = map[chess.PieceKind]int{
+	chess.Pawn:   100,
+	chess.Knight: 320,
+	chess.Bishop: 330,
+	chess.Rook:   500,
+	chess.Queen:  900,
+	chess.King:   20000,
+}
+
+// Piece-square tables are from "Simplified Evaluation Function" by Tomasz Michniewski.
+// They are written from white's point of view with the 8th rank first.
+var pieceSquareTables = map[chess.PieceKind][64]int{
+	chess.Pawn: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	chess.Knight: {
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	chess.Bishop: {
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	chess.Rook: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	},
+	chess.Queen: {
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	chess.King: {
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	},
+}
+
+// kingEndgameTable replaces the king table when there is little material left on the board.
+var kingEndgameTable = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+// endgameMaterial is the maximum material (without pawns and kings) of each side in the endgame.
+const endgameMaterial = 1300
+
+// evaluate returns the static evaluation of the position from the point of view of the side to move.
+func evaluate(st chess.State) int {
+	var (
+		score    [2]int
+		material [2]int
+		queens   int
+	)
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			p := st.Position.Get(chess.MustNewSquare(file, rank))
+			if p.Kind == chess.None {
+				continue
+			}
+			score[p.Color] += pieceValues[p.Kind]
+			if p.Kind != chess.King {
+				score[p.Color] += pieceSquareTables[p.Kind][tableIndex(p.Color, file, rank)]
+			}
+			if p.Kind != chess.Pawn && p.Kind != chess.King {
+				material[p.Color] += pieceValues[p.Kind]
+			}
+			if p.Kind == chess.Queen {
+				queens++
+			}
+		}
+	}
+
+	kingTable := pieceSquareTables[chess.King]
+	if queens == 0 || (material[chess.White] <= endgameMaterial && material[chess.Black] <= endgameMaterial) {
+		kingTable = kingEndgameTable
+	}
+	for color := chess.White; color <= chess.Black; color++ {
+		if king, ok := st.Position.KingSquare(color); ok {
+			score[color] += kingTable[tableIndex(color, king.File(), king.Rank())]
+		}
+	}
+
+	return score[st.ToMove] - score[1-st.ToMove]
+}
+
+// tableIndex returns the index of the square in a piece-square table for a piece of the color.
+func tableIndex(color chess.PieceColor, file, rank int) int {
+	if color == chess.White {
+		return (7-rank)*8 + file
+	}
+	return rank*8 + file
+}
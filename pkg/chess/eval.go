@@ -0,0 +1,70 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Eval is an evaluation of a position from white's point of view.
+type Eval struct {
+	// Centipawns is the advantage of white in hundredths of a pawn (negative if black is better).
+	// It is meaningful only if Mate is 0.
+	Centipawns int
+	// Mate is the number of moves to a forced checkmate: positive if white mates, negative if black does.
+	Mate int
+}
+
+// String returns the evaluation in the format of [%eval] command (e.g. "0.17", "-1.50" or "#-3").
+func (e Eval) String() string {
+	if e.Mate != 0 {
+		return fmt.Sprintf("#%d", e.Mate)
+	}
+	return strconv.FormatFloat(float64(e.Centipawns)/100, 'f', 2, 64)
+}
+
+// WinningChances maps the evaluation to the range from -1 (black wins) to 1 (white wins).
+// The mapping follows the winning chances model of lichess.
+func (e Eval) WinningChances() float64 {
+	switch {
+	case e.Mate > 0:
+		return 1
+	case e.Mate < 0:
+		return -1
+	}
+	return 2/(1+math.Exp(-0.00368208*float64(e.Centipawns))) - 1
+}
+
+// ParseEval parses an evaluation in pawns (e.g. "0.17" or "+1.5") or a mate in moves (e.g. "#3" or "#-3").
+func ParseEval(s string) (Eval, error) {
+	if strings.HasPrefix(s, "#") {
+		mate, err := strconv.Atoi(s[1:])
+		if err != nil || mate == 0 {
+			return Eval{}, fmt.Errorf("invalid mate evaluation %q", s)
+		}
+		return Eval{Mate: mate}, nil
+	}
+	pawns, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(pawns, 0) || math.IsNaN(pawns) {
+		return Eval{}, fmt.Errorf("invalid evaluation %q", s)
+	}
+	return Eval{Centipawns: int(math.Round(pawns * 100))}, nil
+}
+
+var evalCommand = regexp.MustCompile(`\[%eval\s+([^\s,\]]+)[^\]]*\]`)
+
+// ErrNoEval is returned by CommentEval if the comment does not contain [%eval] command.
+var ErrNoEval = errors.New("no evaluation in the comment")
+
+// CommentEval extracts the evaluation from [%eval] command embedded in a PGN comment
+// (e.g. "{ [%eval 0.17] [%clk 0:03:00] }").
+func CommentEval(comment string) (Eval, error) {
+	m := evalCommand.FindStringSubmatch(comment)
+	if m == nil {
+		return Eval{}, ErrNoEval
+	}
+	return ParseEval(m[1])
+}
@@ -0,0 +1,46 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommentEval(t *testing.T) {
+	tcs := []struct {
+		comment string
+		want    Eval
+		wantErr bool
+	}{
+		{comment: "[%eval 0.17]", want: Eval{Centipawns: 17}},
+		{comment: "good move [%eval -1.5] [%clk 0:03:00]", want: Eval{Centipawns: -150}},
+		{comment: "[%eval +2.05,24]", want: Eval{Centipawns: 205}},
+		{comment: "[%eval #3]", want: Eval{Mate: 3}},
+		{comment: "[%eval #-1]", want: Eval{Mate: -1}},
+		{comment: "[%eval #0]", wantErr: true},
+		{comment: "[%eval good]", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.comment, func(tt *testing.T) {
+			got, err := CommentEval(tc.comment)
+			if tc.wantErr {
+				if err == nil {
+					tt.Errorf("want error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				tt.Errorf("want %s, got %s", tc.want, got)
+			}
+			if back, err := ParseEval(got.String()); err != nil || back != got {
+				tt.Errorf("round trip of %q: got %s (%v)", got.String(), back, err)
+			}
+		})
+	}
+
+	if _, err := CommentEval("[%clk 0:03:00]"); !errors.Is(err, ErrNoEval) {
+		t.Errorf("want ErrNoEval, got %v", err)
+	}
+}
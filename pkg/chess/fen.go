@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
 type fenParser struct {
@@ -30,13 +32,14 @@ func (err InvalidRuneError) Error() string {
 }
 
 func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
+	in := newInputReader(r)
 	pos := Position{}
 
-	at := -1
+	start := in.loc
 	file := 0
 	rank := 7
 	for {
-		c, _, err := r.ReadRune()
+		c, _, err := in.ReadRune()
 		// handle error
 		if errors.Is(err, io.EOF) {
 			break
@@ -44,7 +47,7 @@ func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
 		if err != nil {
 			return pos, fmt.Errorf("ReadRune: %w", err)
 		}
-		at++
+		at := in.lastLocation()
 
 		// throw away metadata
 		if c == ' ' {
@@ -53,7 +56,7 @@ func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
 
 		// check that the ranks are not filled
 		if rank < 0 {
-			return pos, ErrTooManyRanks
+			return pos, in.errorAt(at, ErrTooManyRanks)
 		}
 
 		// move to new rank
@@ -63,7 +66,7 @@ func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
 				rank--
 				continue
 			} else {
-				return pos, ErrTooShortRank
+				return pos, in.errorAt(at, ErrTooShortRank)
 			}
 		}
 
@@ -71,7 +74,7 @@ func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
 		if c >= '1' && c <= '8' {
 			n := int(c - '0')
 			if file+n-1 > 7 {
-				return pos, ErrTooLongRank
+				return pos, in.errorAt(at, ErrTooLongRank)
 			}
 			file += n
 			continue
@@ -95,7 +98,7 @@ func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
 		}
 		if p, exists := pieces[c]; exists {
 			if file > 7 {
-				return pos, ErrTooLongRank
+				return pos, in.errorAt(at, ErrTooLongRank)
 			}
 			pos = pos.Set(MustNewSquare(file, rank), p)
 			file += 1
@@ -103,16 +106,201 @@ func (fp fenParser) Parse(r io.RuneReader) (Position, error) {
 		}
 
 		// bad rune
-		return pos, InvalidRuneError{At: at, Rune: c}
+		return pos, in.errorAt(at, InvalidRuneError{At: at.offset - start.offset, Rune: c})
 	}
 
 	if rank == 0 && file == 8 {
 		return pos, nil
 	} else {
 		if rank > 0 {
-			return pos, ErrTooFewRanks
+			return pos, in.errorAt(in.lastLocation(), ErrTooFewRanks)
 		} else {
-			return pos, ErrTooShortRank
+			return pos, in.errorAt(in.lastLocation(), ErrTooShortRank)
 		}
 	}
 }
+
+// InvalidFieldError is returned by ParseFEN if one of the fields after piece placement is malformed.
+type InvalidFieldError struct {
+	Field string
+	Value string
+}
+
+func (err InvalidFieldError) Error() string {
+	return fmt.Sprintf("invalid %s field %q", err.Field, err.Value)
+}
+
+// ParseFEN parses full FEN notation into State. Only piece placement is required;
+// missing fields default to white to move, no en passant square and zero clocks (fullmove number 1).
+// If castling field is missing, castling rights are inferred from the placement of kings and rooks.
+func ParseFEN(r io.RuneReader) (State, error) {
+	in := newInputReader(r)
+	st := State{}
+
+	pos, err := FEN().Parse(in)
+	if err != nil {
+		return st, err
+	}
+	st.Position = pos
+	st.ToMove = White
+	st.Castling = inferCastling(pos)
+	st.FullmoveNumber = 1
+
+	// collect the remaining fields with their locations
+	type field struct {
+		value string
+		loc   location
+	}
+	var (
+		fields []field
+		cur    []rune
+		curLoc location
+	)
+	for {
+		c, _, err := in.ReadRune()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return st, fmt.Errorf("ReadRune: %w", err)
+		}
+		if err != nil || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			if len(cur) > 0 {
+				fields = append(fields, field{string(cur), curLoc})
+				cur = nil
+			}
+			if err != nil {
+				break
+			}
+			continue
+		}
+		if len(cur) == 0 {
+			curLoc = in.lastLocation()
+		}
+		cur = append(cur, c)
+	}
+
+	names := []string{"side to move", "castling", "en passant", "halfmove clock", "fullmove number"}
+	if len(fields) > len(names) {
+		return st, in.errorAt(fields[len(names)].loc, errors.New("unexpected extra field"))
+	}
+	for i, f := range fields {
+		bad := in.errorAt(f.loc, InvalidFieldError{Field: names[i], Value: f.value})
+		switch i {
+		case 0:
+			switch f.value {
+			case "w":
+				st.ToMove = White
+			case "b":
+				st.ToMove = Black
+			default:
+				return st, bad
+			}
+		case 1:
+			st.Castling = NoCastling
+			if f.value == "-" {
+				continue
+			}
+			for _, c := range f.value {
+				i := strings.IndexRune("KQkq", c)
+				if i < 0 || st.Castling&(1<<i) != 0 {
+					return st, bad
+				}
+				st.Castling |= 1 << i
+			}
+		case 2:
+			if f.value == "-" {
+				continue
+			}
+			sq, err := NewSquareFromString(f.value)
+			if err != nil || (sq.rank != 2 && sq.rank != 5) {
+				return st, bad
+			}
+			st.EnPassant = sq
+			st.HasEnPassant = true
+		case 3, 4:
+			n, err := strconv.Atoi(f.value)
+			if err != nil || n < 0 || (i == 4 && n == 0) {
+				return st, bad
+			}
+			if i == 3 {
+				st.HalfmoveClock = n
+			} else {
+				st.FullmoveNumber = n
+			}
+		}
+	}
+
+	return st, nil
+}
+
+// inferCastling returns castling rights for which the king and the rook are on their original squares.
+func inferCastling(pos Position) CastlingRights {
+	cr := NoCastling
+	checks := []struct {
+		right CastlingRights
+		color PieceColor
+		rank  int
+		rook  int
+	}{
+		{WhiteKingside, White, 0, 7},
+		{WhiteQueenside, White, 0, 0},
+		{BlackKingside, Black, 7, 7},
+		{BlackQueenside, Black, 7, 0},
+	}
+	for _, check := range checks {
+		if pos[4][check.rank] == (Piece{King, check.color}) && pos[check.rook][check.rank] == (Piece{Rook, check.color}) {
+			cr |= check.right
+		}
+	}
+	return cr
+}
+
+var fenLetters = map[Piece]rune{
+	{Pawn, Black}:   'p',
+	{Knight, Black}: 'n',
+	{Bishop, Black}: 'b',
+	{Rook, Black}:   'r',
+	{Queen, Black}:  'q',
+	{King, Black}:   'k',
+
+	{Pawn, White}:   'P',
+	{Knight, White}: 'N',
+	{Bishop, White}: 'B',
+	{Rook, White}:   'R',
+	{Queen, White}:  'Q',
+	{King, White}:   'K',
+}
+
+// FEN returns piece placement field of FEN notation of the position.
+func (pos Position) FEN() string {
+	bldr := strings.Builder{}
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			p := pos[file][rank]
+			if p.Kind == None {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				bldr.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			bldr.WriteRune(fenLetters[p])
+		}
+		if empty > 0 {
+			bldr.WriteByte(byte('0' + empty))
+		}
+		if rank > 0 {
+			bldr.WriteRune('/')
+		}
+	}
+	return bldr.String()
+}
+
+// FEN returns full FEN notation of the state.
+func (st State) FEN() string {
+	ep := "-"
+	if st.HasEnPassant {
+		ep = st.EnPassant.String()
+	}
+	return fmt.Sprintf("%s %s %s %s %d %d", st.Position.FEN(), st.ToMove, st.Castling, ep, st.HalfmoveClock, st.FullmoveNumber)
+}
@@ -1,6 +1,7 @@
 package chess
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -53,7 +54,7 @@ func TestFenParserParse(t *testing.T) {
 		t.Run(tc.name, func(tt *testing.T) {
 			r := strings.NewReader(tc.notation)
 			pos, err := FEN().Parse(r)
-			if tc.wantErr != err {
+			if !errors.Is(err, tc.wantErr) {
 				tt.Fatalf("want error: %v, got error: %v", tc.wantErr, err)
 			}
 			if err == nil && !positionEqual(tc.want, pos) {
@@ -62,3 +63,128 @@ func TestFenParserParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFEN(t *testing.T) {
+	tcs := []struct {
+		name     string
+		notation string
+		want     string // in canonical FEN
+		wantErr  bool
+	}{
+		{
+			name:     "starting position",
+			notation: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			want:     "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		},
+		{
+			name:     "en passant and clocks",
+			notation: "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w Kq f6 0 3",
+			want:     "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w Kq f6 0 3",
+		},
+		{
+			name:     "placement only",
+			notation: "r3k3/8/8/8/8/8/8/4K2R",
+			want:     "r3k3/8/8/8/8/8/8/4K2R w Kq - 0 1",
+		},
+		{
+			name:     "black to move",
+			notation: "4k3/8/8/8/8/8/8/4K3 b",
+			want:     "4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+		},
+		{
+			name:     "bad side to move",
+			notation: "4k3/8/8/8/8/8/8/4K3 x - - 0 1",
+			wantErr:  true,
+		},
+		{
+			name:     "bad castling",
+			notation: "4k3/8/8/8/8/8/8/4K3 w KK - 0 1",
+			wantErr:  true,
+		},
+		{
+			name:     "bad en passant",
+			notation: "4k3/8/8/8/8/8/8/4K3 w - e4 0 1",
+			wantErr:  true,
+		},
+		{
+			name:     "bad fullmove number",
+			notation: "4k3/8/8/8/8/8/8/4K3 w - - 0 0",
+			wantErr:  true,
+		},
+		{
+			name:     "extra field",
+			notation: "4k3/8/8/8/8/8/8/4K3 w - - 0 1 foo",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.notation))
+			if tc.wantErr != (err != nil) {
+				tt.Fatalf("want error: %t, got error: %v", tc.wantErr, err)
+			}
+			if err != nil {
+				var perr ParseError
+				if !errors.As(err, &perr) {
+					tt.Errorf("want ParseError, got %#v", err)
+				}
+				return
+			}
+			if got := st.FEN(); got != tc.want {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPositionFEN(t *testing.T) {
+	for _, placement := range []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+		"8/8/8/8/8/8/8/8",
+		"4k3/8/8/3pP3/8/8/8/4K3",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R",
+	} {
+		pos, err := FEN().Parse(strings.NewReader(placement))
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", placement, err)
+		}
+		if got := pos.FEN(); got != placement {
+			t.Errorf("want %q, got %q", placement, got)
+		}
+	}
+}
+
+func TestInferCastling(t *testing.T) {
+	tcs := []struct {
+		placement string
+		want      CastlingRights
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", WhiteKingside | WhiteQueenside | BlackKingside | BlackQueenside},
+		{"r3k3/8/8/8/8/8/8/4K2R", WhiteKingside | BlackQueenside},
+		// the king is not on its original square
+		{"r4k1r/8/8/8/8/8/8/R2K3R", NoCastling},
+		// the rooks are of the wrong color
+		{"R3k2R/8/8/8/8/8/8/r3K2r", NoCastling},
+	}
+	for _, tc := range tcs {
+		pos, err := FEN().Parse(strings.NewReader(tc.placement))
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", tc.placement, err)
+		}
+		if got := inferCastling(pos); got != tc.want {
+			t.Errorf("%q: want %s, got %s", tc.placement, tc.want, got)
+		}
+	}
+}
+
+func TestStateFEN(t *testing.T) {
+	st := StartingState()
+	if got, want := st.FEN(), "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	st = st.Apply(Move{From: MustNewSquareFromString("e2"), To: MustNewSquareFromString("e4")})
+	if got, want := st.FEN(), "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,189 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseError describes an error in the parsed notation along with its location in the input.
+// All parsers in this package return errors of this type, so the location can be extracted
+// with errors.As. The underlying error (e.g. IllegalMoveError) is available via Unwrap.
+type ParseError struct {
+	// Offset is the number of runes preceding the error in the input.
+	Offset int
+	// Line and Column are 1-based. Column is counted in runes.
+	Line   int
+	Column int
+	// Snippet is the full line of the input that contains the error.
+	Snippet string
+
+	Err error
+}
+
+func (err ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", err.Line, err.Column, err.Err)
+}
+
+func (err ParseError) Unwrap() error {
+	return err.Err
+}
+
+// Caret returns Snippet followed by a line with a caret pointing at the error column.
+func (err ParseError) Caret() string {
+	bldr := strings.Builder{}
+	bldr.WriteString(err.Snippet)
+	bldr.WriteRune('\n')
+	for i, c := range []rune(err.Snippet) {
+		if i >= err.Column-1 {
+			break
+		}
+		// keep tabs so that the caret stays aligned
+		if c == '\t' {
+			bldr.WriteRune('\t')
+		} else {
+			bldr.WriteRune(' ')
+		}
+	}
+	bldr.WriteRune('^')
+	return bldr.String()
+}
+
+// location is a 0-based position in the input.
+type location struct {
+	offset int
+	line   int
+	col    int
+}
+
+// inputReader is an io.RuneScanner that keeps track of the location in the input
+// and remembers the consumed lines, so that errors can be reported with a snippet.
+type inputReader struct {
+	r   io.RuneReader
+	loc location
+
+	lines []string
+	cur   []rune
+
+	// last read rune and location before it (for UnreadRune)
+	last      rune
+	lastSize  int
+	lastLoc   location
+	canUnread bool
+	// pending is true if the last rune was unread and must be returned again
+	pending bool
+}
+
+// newInputReader wraps r into inputReader. If r is already an inputReader, it is returned as is.
+func newInputReader(r io.RuneReader) *inputReader {
+	if in, ok := r.(*inputReader); ok {
+		return in
+	}
+	return &inputReader{r: r}
+}
+
+func (in *inputReader) ReadRune() (rune, int, error) {
+	var (
+		c    rune
+		size int
+	)
+	if in.pending {
+		c, size = in.last, in.lastSize
+		in.pending = false
+	} else {
+		var err error
+		c, size, err = in.r.ReadRune()
+		if err != nil {
+			in.canUnread = false
+			return c, size, err
+		}
+	}
+
+	in.last, in.lastSize, in.lastLoc = c, size, in.loc
+	in.canUnread = true
+
+	in.loc.offset++
+	if c == '\n' {
+		in.lines = append(in.lines, string(in.cur))
+		in.cur = in.cur[:0]
+		in.loc.line++
+		in.loc.col = 0
+	} else {
+		in.cur = append(in.cur, c)
+		in.loc.col++
+	}
+	return c, size, nil
+}
+
+func (in *inputReader) UnreadRune() error {
+	if !in.canUnread {
+		return errors.New("chess: invalid use of UnreadRune")
+	}
+	in.canUnread = false
+	in.pending = true
+
+	if in.last == '\n' {
+		in.cur = []rune(in.lines[len(in.lines)-1])
+		in.lines = in.lines[:len(in.lines)-1]
+	} else {
+		in.cur = in.cur[:len(in.cur)-1]
+	}
+	in.loc = in.lastLoc
+	return nil
+}
+
+// lastLocation returns the location of the last read rune.
+func (in *inputReader) lastLocation() location {
+	return in.lastLoc
+}
+
+// lineText returns the full text of the specified (0-based) line.
+// If the line is not fully read yet, the rest of it is consumed from the input.
+func (in *inputReader) lineText(line int) string {
+	if line < len(in.lines) {
+		return in.lines[line]
+	}
+	for {
+		c, _, err := in.ReadRune()
+		if err != nil || c == '\n' {
+			break
+		}
+	}
+	if line < len(in.lines) {
+		return in.lines[line]
+	}
+	return string(in.cur)
+}
+
+// errorAt wraps err into ParseError at the specified location.
+// If err is already a ParseError, it is returned unchanged.
+func (in *inputReader) errorAt(loc location, err error) error {
+	var perr ParseError
+	if errors.As(err, &perr) {
+		return err
+	}
+	return ParseError{
+		Offset:  loc.offset,
+		Line:    loc.line + 1,
+		Column:  loc.col + 1,
+		Snippet: in.lineText(loc.line),
+		Err:     err,
+	}
+}
+
+// rebase moves ParseError (got from parsing a substring of the input that starts at loc)
+// to the location relative to the whole input. Other errors are wrapped at loc.
+func (in *inputReader) rebase(loc location, err error) error {
+	var perr ParseError
+	if !errors.As(err, &perr) {
+		return in.errorAt(loc, err)
+	}
+	if perr.Line == 1 {
+		perr.Column += loc.col
+	}
+	perr.Line += loc.line
+	perr.Offset += loc.offset
+	perr.Snippet = in.lineText(perr.Line - 1)
+	return perr
+}
@@ -0,0 +1,126 @@
+package chess
+
+// MateLine is a move of the attacking side that forces checkmate, with all defenses against it.
+type MateLine struct {
+	Move Move
+	// Defenses are all replies of the defending side, each with the moves that still force mate in time.
+	// Defenses are empty if Move gives checkmate.
+	Defenses []MateDefense
+}
+
+// MateDefense is a reply of the defending side in a MateLine.
+type MateDefense struct {
+	Move Move
+	// Mates are all moves that force mate after the defense (more than one means a dual).
+	Mates []MateLine
+}
+
+// MateTry is a first move that fails to force mate only because of a single defense.
+type MateTry struct {
+	Move       Move
+	Refutation Move
+}
+
+// MateSolution is the result of SolveMate.
+type MateSolution struct {
+	// Keys are the first moves that force mate. A sound problem has exactly one key,
+	// additional keys are cooks.
+	Keys []MateLine
+	// Tries are the first moves that are refuted by exactly one defense.
+	Tries []MateTry
+}
+
+// SolveMate searches the state exhaustively for the moves of the side to move that force checkmate
+// in at most n moves (so "mate in 2" is n = 2). The search time grows very fast with n.
+func SolveMate(st State, n int) MateSolution {
+	var sol MateSolution
+	if n < 1 {
+		return sol
+	}
+	for _, mov := range st.LegalMoves() {
+		if line, ok := mateLine(st, mov, n); ok {
+			sol.Keys = append(sol.Keys, line)
+			continue
+		}
+		if refutation, ok := singleRefutation(st.Apply(mov), n-1); ok {
+			sol.Tries = append(sol.Tries, MateTry{Move: mov, Refutation: refutation})
+		}
+	}
+	return sol
+}
+
+// mateLine returns the solution tree of mov if it forces mate in at most n moves.
+func mateLine(st State, mov Move, n int) (MateLine, bool) {
+	next := st.Apply(mov)
+	line := MateLine{Move: mov}
+	if next.IsCheckmate() {
+		return line, true
+	}
+	defs := next.LegalMoves()
+	// a stalemate is not a mate
+	if n == 1 || len(defs) == 0 {
+		return line, false
+	}
+	// find a refutation before building the whole tree
+	for _, def := range defs {
+		if !hasMate(next.Apply(def), n-1) {
+			return line, false
+		}
+	}
+	for _, def := range defs {
+		after := next.Apply(def)
+		var mates []MateLine
+		for _, cont := range after.LegalMoves() {
+			if l, ok := mateLine(after, cont, n-1); ok {
+				mates = append(mates, l)
+			}
+		}
+		line.Defenses = append(line.Defenses, MateDefense{Move: def, Mates: mates})
+	}
+	return line, true
+}
+
+// hasMate reports whether the side to move can force mate in at most n moves.
+func hasMate(st State, n int) bool {
+	for _, mov := range st.LegalMoves() {
+		next := st.Apply(mov)
+		if next.IsCheckmate() {
+			return true
+		}
+		if n == 1 {
+			continue
+		}
+		defs := next.LegalMoves()
+		forced := len(defs) > 0
+		for _, def := range defs {
+			if !hasMate(next.Apply(def), n-1) {
+				forced = false
+				break
+			}
+		}
+		if forced {
+			return true
+		}
+	}
+	return false
+}
+
+// singleRefutation returns the defense in st if it is the only one that escapes mate in n moves.
+func singleRefutation(st State, n int) (Move, bool) {
+	if n < 1 {
+		return Move{}, false
+	}
+	var refutations []Move
+	for _, def := range st.LegalMoves() {
+		if !hasMate(st.Apply(def), n) {
+			refutations = append(refutations, def)
+			if len(refutations) > 1 {
+				break
+			}
+		}
+	}
+	if len(refutations) != 1 {
+		return Move{}, false
+	}
+	return refutations[0], true
+}
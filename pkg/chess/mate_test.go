@@ -0,0 +1,106 @@
+package chess
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// checkMateLine verifies that line really forces mate in at most n moves.
+func checkMateLine(tt *testing.T, st State, line MateLine, n int) {
+	next := st.Apply(line.Move)
+	if next.IsCheckmate() {
+		return
+	}
+	if n <= 1 {
+		tt.Fatalf("%s does not mate in time", st.SAN(line.Move))
+	}
+	if len(line.Defenses) != len(next.LegalMoves()) {
+		tt.Fatalf("after %s: want %d defenses, got %d", st.SAN(line.Move), len(next.LegalMoves()), len(line.Defenses))
+	}
+	for _, def := range line.Defenses {
+		after := next.Apply(def.Move)
+		if len(def.Mates) == 0 {
+			tt.Fatalf("after %s %s: no mate", st.SAN(line.Move), next.SAN(def.Move))
+		}
+		for _, mate := range def.Mates {
+			checkMateLine(tt, after, mate, n-1)
+		}
+	}
+}
+
+func TestSolveMate(t *testing.T) {
+	tcs := []struct {
+		name     string
+		fen      string
+		n        int
+		wantKeys []string
+		wantTry  [2]string
+	}{
+		{
+			name:     "mate in 1",
+			fen:      "6k1/5ppp/8/8/8/8/8/R3K3 w Q - 0 1",
+			n:        1,
+			wantKeys: []string{"Ra8#"},
+		},
+		{
+			name:     "mate in 2 with a try",
+			fen:      "kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1",
+			n:        2,
+			wantKeys: []string{"Ra6"},
+			wantTry:  [2]string{"Ra5", "a6"},
+		},
+		{
+			name:     "cooked mate in 2",
+			fen:      "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1",
+			n:        2,
+			wantKeys: []string{"Ra7", "Rb7"},
+			wantTry:  [2]string{"Rg2", "Kh7"},
+		},
+		{
+			name:     "sacrifice",
+			fen:      "r1b2k1r/ppppq3/5N1p/4P2Q/4PP2/1B6/PP5P/n2K2R1 w - - 0 1",
+			n:        2,
+			wantKeys: []string{"Qxh6+"},
+			wantTry:  [2]string{"Rg8+", "Rxg8"},
+		},
+		{
+			name: "no mate",
+			fen:  "1k6/ppp5/8/8/8/8/5PPP/3R2K1 b - - 0 1",
+			n:    2,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			sol := SolveMate(st, tc.n)
+
+			var keys []string
+			for _, key := range sol.Keys {
+				keys = append(keys, st.SAN(key.Move))
+				checkMateLine(tt, st, key, tc.n)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tc.wantKeys) {
+				tt.Errorf("want keys %v, got %v", tc.wantKeys, keys)
+			}
+
+			if tc.wantTry[0] == "" {
+				return
+			}
+			for _, try := range sol.Tries {
+				if st.SAN(try.Move) == tc.wantTry[0] {
+					if got := st.Apply(try.Move).SAN(try.Refutation); got != tc.wantTry[1] {
+						tt.Errorf("want %s to be refuted by %s, got %s", tc.wantTry[0], tc.wantTry[1], got)
+					}
+					return
+				}
+			}
+			tt.Errorf("try %s not found", tc.wantTry[0])
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package chess
+
+import "strings"
+
+var (
+	knightSteps = [...][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps   = [...][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	rookDirs    = [...][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	bishopDirs  = [...][2]int{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+
+	promotionKinds = [...]PieceKind{Queen, Rook, Bishop, Knight}
+)
+
+// offset returns the square shifted by df files and dr ranks and whether it is on the board.
+func (sq Square) offset(df, dr int) (Square, bool) {
+	file, rank := sq.file+df, sq.rank+dr
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return Square{}, false
+	}
+	return Square{file: file, rank: rank}, true
+}
+
+// pawnDir returns the direction in which the pawns of the color move.
+func pawnDir(color PieceColor) int {
+	if color == White {
+		return 1
+	}
+	return -1
+}
+
+// Attackers returns the squares of the pieces of color by that attack sq.
+func (pos Position) Attackers(sq Square, by PieceColor) []Square {
+	var sqs []Square
+	pos.forEachAttacker(sq, by, func(from Square) bool {
+		sqs = append(sqs, from)
+		return true
+	})
+	return sqs
+}
+
+// Attacked reports whether sq is attacked by any piece of color by.
+func (pos Position) Attacked(sq Square, by PieceColor) bool {
+	attacked := false
+	pos.forEachAttacker(sq, by, func(Square) bool {
+		attacked = true
+		return false
+	})
+	return attacked
+}
+
+// forEachAttacker calls f for every piece of color by attacking sq until f returns false.
+func (pos Position) forEachAttacker(sq Square, by PieceColor, f func(from Square) bool) {
+	is := func(from Square, kinds ...PieceKind) bool {
+		p := pos.Get(from)
+		if p.Kind == None || p.Color != by {
+			return false
+		}
+		for _, kind := range kinds {
+			if p.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	// pawns attack diagonally forward, so look backward from sq
+	for df := -1; df <= 1; df += 2 {
+		if from, ok := sq.offset(df, -pawnDir(by)); ok && is(from, Pawn) {
+			if !f(from) {
+				return
+			}
+		}
+	}
+	for _, step := range knightSteps {
+		if from, ok := sq.offset(step[0], step[1]); ok && is(from, Knight) {
+			if !f(from) {
+				return
+			}
+		}
+	}
+	for _, step := range kingSteps {
+		if from, ok := sq.offset(step[0], step[1]); ok && is(from, King) {
+			if !f(from) {
+				return
+			}
+		}
+	}
+	slide := func(dirs [4][2]int, kinds ...PieceKind) bool {
+		for _, dir := range dirs {
+			for d := 1; ; d++ {
+				from, ok := sq.offset(dir[0]*d, dir[1]*d)
+				if !ok {
+					break
+				}
+				if is(from, kinds...) && !f(from) {
+					return false
+				}
+				if pos.Get(from).Kind != None {
+					break
+				}
+			}
+		}
+		return true
+	}
+	if slide(rookDirs, Rook, Queen) {
+		slide(bishopDirs, Bishop, Queen)
+	}
+}
+
+// KingSquare returns the square of the king of the color and false if there is no such king.
+func (pos Position) KingSquare(color PieceColor) (Square, bool) {
+	for file := range pos {
+		for rank := range pos[file] {
+			if pos[file][rank] == (Piece{Kind: King, Color: color}) {
+				return Square{file: file, rank: rank}, true
+			}
+		}
+	}
+	return Square{}, false
+}
+
+// InCheck reports whether the king of the side to move is attacked.
+func (st State) InCheck() bool {
+	king, ok := st.Position.KingSquare(st.ToMove)
+	return ok && st.Position.Attacked(king, 1-st.ToMove)
+}
+
+// LegalMoves returns all legal moves of the side to move.
+func (st State) LegalMoves() []Move {
+	var movs []Move
+	for _, mov := range st.pseudoLegalMoves() {
+		if st.leavesKingSafe(mov) {
+			movs = append(movs, mov)
+		}
+	}
+	return movs
+}
+
+// IsLegal reports whether mov is a legal move of the side to move.
+func (st State) IsLegal(mov Move) bool {
+	for _, pseudo := range st.pseudoLegalMoves() {
+		if pseudo == mov {
+			return st.leavesKingSafe(mov)
+		}
+	}
+	return false
+}
+
+// IsCheckmate reports whether the side to move is checkmated.
+func (st State) IsCheckmate() bool {
+	return st.InCheck() && len(st.LegalMoves()) == 0
+}
+
+// IsStalemate reports whether the side to move has no legal moves while not in check.
+func (st State) IsStalemate() bool {
+	return !st.InCheck() && len(st.LegalMoves()) == 0
+}
+
+func (st State) leavesKingSafe(mov Move) bool {
+	pos := Apply(st.Position, mov)
+	king, ok := pos.KingSquare(st.ToMove)
+	return !ok || !pos.Attacked(king, 1-st.ToMove)
+}
+
+// pseudoLegalMoves returns the moves of the side to move without checking whether the king is left in check.
+// Castling moves are fully checked though.
+func (st State) pseudoLegalMoves() []Move {
+	movs := make([]Move, 0, 48)
+	pos := st.Position
+	us := st.ToMove
+
+	add := func(from, to Square) {
+		movs = append(movs, Move{From: from, To: to})
+	}
+	// target reports whether a piece of the side to move can go to sq and whether it can go further (for sliders)
+	target := func(sq Square) (bool, bool) {
+		p := pos.Get(sq)
+		if p.Kind == None {
+			return true, true
+		}
+		return p.Color != us, false
+	}
+
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			from := Square{file: file, rank: rank}
+			p := pos.Get(from)
+			if p.Kind == None || p.Color != us {
+				continue
+			}
+			switch p.Kind {
+			case Pawn:
+				movs = st.appendPawnMoves(movs, from)
+			case Knight, King:
+				steps := knightSteps
+				if p.Kind == King {
+					steps = kingSteps
+				}
+				for _, step := range steps {
+					if to, ok := from.offset(step[0], step[1]); ok {
+						if can, _ := target(to); can {
+							add(from, to)
+						}
+					}
+				}
+			case Rook, Bishop, Queen:
+				var dirs [][2]int
+				if p.Kind != Bishop {
+					dirs = append(dirs, rookDirs[:]...)
+				}
+				if p.Kind != Rook {
+					dirs = append(dirs, bishopDirs[:]...)
+				}
+				for _, dir := range dirs {
+					for d := 1; ; d++ {
+						to, ok := from.offset(dir[0]*d, dir[1]*d)
+						if !ok {
+							break
+						}
+						can, further := target(to)
+						if can {
+							add(from, to)
+						}
+						if !further {
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return st.appendCastlingMoves(movs)
+}
+
+func (st State) appendPawnMoves(movs []Move, from Square) []Move {
+	pos := st.Position
+	us := st.ToMove
+	dir := pawnDir(us)
+	lastRank := 7
+	startRank := 1
+	if us == Black {
+		lastRank = 0
+		startRank = 6
+	}
+
+	add := func(mov Move) {
+		if mov.To.rank != lastRank {
+			movs = append(movs, mov)
+			return
+		}
+		for _, kind := range promotionKinds {
+			mov.Promotion = Piece{Kind: kind, Color: us}
+			movs = append(movs, mov)
+		}
+	}
+
+	if to, ok := from.offset(0, dir); ok && pos.Get(to).Kind == None {
+		add(Move{From: from, To: to})
+		if to2, ok := from.offset(0, 2*dir); ok && from.rank == startRank && pos.Get(to2).Kind == None {
+			add(Move{From: from, To: to2})
+		}
+	}
+	for df := -1; df <= 1; df += 2 {
+		to, ok := from.offset(df, dir)
+		if !ok {
+			continue
+		}
+		if p := pos.Get(to); p.Kind != None && p.Color != us {
+			add(Move{From: from, To: to})
+		} else if st.HasEnPassant && to == st.EnPassant && p.Kind == None {
+			add(Move{From: from, To: to, EnPassant: true})
+		}
+	}
+	return movs
+}
+
+func (st State) appendCastlingMoves(movs []Move) []Move {
+	pos := st.Position
+	us := st.ToMove
+	rank := 0
+	kingside, queenside := WhiteKingside, WhiteQueenside
+	if us == Black {
+		rank = 7
+		kingside, queenside = BlackKingside, BlackQueenside
+	}
+	king := Square{file: 4, rank: rank}
+	if pos.Get(king) != (Piece{Kind: King, Color: us}) || pos.Attacked(king, 1-us) {
+		return movs
+	}
+
+	castlings := []struct {
+		right    CastlingRights
+		rookFile int
+		toFile   int
+	}{
+		{kingside, 7, 6},
+		{queenside, 0, 2},
+	}
+	for _, c := range castlings {
+		if st.Castling&c.right == 0 || pos.Get(Square{file: c.rookFile, rank: rank}) != (Piece{Kind: Rook, Color: us}) {
+			continue
+		}
+		// the squares between the king and the rook must be empty
+		free := true
+		step := 1
+		if c.rookFile < king.file {
+			step = -1
+		}
+		for file := king.file + step; file != c.rookFile; file += step {
+			if pos.Get(Square{file: file, rank: rank}).Kind != None {
+				free = false
+				break
+			}
+		}
+		// the king must not pass through an attacked square (its destination is checked as usual)
+		if !free || pos.Attacked(Square{file: king.file + step, rank: rank}, 1-us) {
+			continue
+		}
+		movs = append(movs, Move{From: king, To: Square{file: c.toFile, rank: rank}, Castle: true})
+	}
+	return movs
+}
+
+var sanLetters = map[PieceKind]string{Knight: "N", Bishop: "B", Rook: "R", Queen: "Q", King: "K"}
+
+// SAN returns the legal move mov in Standard Algebraic Notation (e.g. "Nbd7", "exd5", "O-O" or "e8=Q+").
+func (st State) SAN(mov Move) string {
+	pos := st.Position
+	p := pos.Get(mov.From)
+
+	bldr := strings.Builder{}
+	switch {
+	case mov.Castle && mov.To.file == 6:
+		bldr.WriteString("O-O")
+	case mov.Castle:
+		bldr.WriteString("O-O-O")
+	case p.Kind == Pawn:
+		if mov.From.file != mov.To.file {
+			bldr.WriteByte(byte('a' + mov.From.file))
+			bldr.WriteByte('x')
+		}
+		bldr.WriteString(mov.To.String())
+		if mov.Promotion.Kind != None {
+			bldr.WriteString("=" + sanLetters[mov.Promotion.Kind])
+		}
+	default:
+		bldr.WriteString(sanLetters[p.Kind])
+		// disambiguate between the pieces of the same kind that can go to the same square
+		sameFile, sameRank, ambiguous := false, false, false
+		for _, other := range st.LegalMoves() {
+			if other.To != mov.To || other.From == mov.From || pos.Get(other.From) != p {
+				continue
+			}
+			ambiguous = true
+			sameFile = sameFile || other.From.file == mov.From.file
+			sameRank = sameRank || other.From.rank == mov.From.rank
+		}
+		if ambiguous {
+			if !sameFile {
+				bldr.WriteByte(byte('a' + mov.From.file))
+			} else if !sameRank {
+				bldr.WriteByte(byte('1' + mov.From.rank))
+			} else {
+				bldr.WriteString(mov.From.String())
+			}
+		}
+		if pos.Get(mov.To).Kind != None {
+			bldr.WriteByte('x')
+		}
+		bldr.WriteString(mov.To.String())
+	}
+
+	next := st.Apply(mov)
+	if next.InCheck() {
+		if len(next.LegalMoves()) == 0 {
+			bldr.WriteByte('#')
+		} else {
+			bldr.WriteByte('+')
+		}
+	}
+	return bldr.String()
+}
@@ -0,0 +1,109 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+func perft(st State, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	movs := st.LegalMoves()
+	if depth == 1 {
+		return len(movs)
+	}
+	n := 0
+	for _, mov := range movs {
+		n += perft(st.Apply(mov), depth-1)
+	}
+	return n
+}
+
+func TestLegalMovesPerft(t *testing.T) {
+	// well-known node counts, see https://www.chessprogramming.org/Perft_Results
+	tcs := []struct {
+		name  string
+		fen   string
+		depth int
+		want  int
+	}{
+		{"initial", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 3, 8902},
+		{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2, 2039},
+		{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 4, 43238},
+		{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 3, 9467},
+		{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", 2, 1486},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if got := perft(st, tc.depth); got != tc.want {
+				tt.Errorf("perft(%d): want %d, got %d", tc.depth, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateSAN(t *testing.T) {
+	tcs := []struct {
+		name string
+		fen  string
+		mov  move
+		want string
+	}{
+		{"pawn push", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", move{from: "e2", to: "e4"}, "e4"},
+		{"knight", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", move{from: "g1", to: "f3"}, "Nf3"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", move{from: "e4", to: "d5"}, "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", move{from: "e5", to: "d6", ep: true}, "exd6"},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/R4RK1 w - - 0 1", move{from: "a1", to: "d1"}, "Rad1"},
+		{"rank disambiguation", "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", move{from: "a5", to: "a3"}, "R5a3"},
+		{"full disambiguation with discovered check", "4k3/8/8/8/2Q1Q3/8/4Q3/2K5 w - - 0 1", move{from: "e4", to: "d3"}, "Qe4d3+"},
+		{"pinned piece needs no disambiguation", "4k3/8/8/8/8/8/8/rN2K1N1 w - - 0 1", move{from: "g1", to: "e2"}, "Ne2"},
+		{"castle", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", move{from: "e1", to: "c1", cs: true}, "O-O-O"},
+		{"promotion with check", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", move{from: "b7", to: "b8", pr: Piece{Queen, White}}, "b8=Q+"},
+		{"capture with check", "4k3/4p3/8/8/8/8/8/4R1K1 w - - 0 1", move{from: "e1", to: "e7"}, "Rxe7+"},
+		{"mate", "6k1/5ppp/8/8/8/8/8/R3K3 w Q - 0 1", move{from: "a1", to: "a8"}, "Ra8#"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			mov := getMove(tc.mov)
+			if !st.IsLegal(mov) {
+				tt.Fatalf("%s is not legal", mov)
+			}
+			if got := st.SAN(mov); got != tc.want {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateGameOver(t *testing.T) {
+	tcs := []struct {
+		fen       string
+		check     bool
+		checkmate bool
+		stalemate bool
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", false, false, false},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", true, true, false},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", false, false, true},
+		{"4k3/8/8/8/8/8/4r3/4K3 w - - 0 1", true, false, false},
+	}
+	for _, tc := range tcs {
+		st, err := ParseFEN(strings.NewReader(tc.fen))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.InCheck() != tc.check || st.IsCheckmate() != tc.checkmate || st.IsStalemate() != tc.stalemate {
+			t.Errorf("%s: want check=%t, checkmate=%t, stalemate=%t; got %t, %t, %t", tc.fen,
+				tc.check, tc.checkmate, tc.stalemate, st.InCheck(), st.IsCheckmate(), st.IsStalemate())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package chess
+
+import "strconv"
+
+// NAG is a Numeric Annotation Glyph of PGN (e.g. $2 is a mistake, written as "?").
+type NAG int
+
+const (
+	NoNAG NAG = iota
+	GoodMove
+	Mistake
+	BrilliantMove
+	Blunder
+	InterestingMove
+	Inaccuracy
+)
+
+var nagSymbols = map[NAG]string{
+	GoodMove:        "!",
+	Mistake:         "?",
+	BrilliantMove:   "!!",
+	Blunder:         "??",
+	InterestingMove: "!?",
+	Inaccuracy:      "?!",
+}
+
+// String returns the move suffix of the NAG (e.g. "?!") or its numeric form (e.g. "$14") if it has no suffix.
+func (nag NAG) String() string {
+	if s, ok := nagSymbols[nag]; ok {
+		return s
+	}
+	return "$" + strconv.Itoa(int(nag))
+}
+
+// Drops of the winning chances of the moving side (see Eval.WinningChances) that make a move
+// an inaccuracy, a mistake or a blunder (the same as lichess uses).
+const (
+	InaccuracyDrop = 0.1
+	MistakeDrop    = 0.2
+	BlunderDrop    = 0.3
+)
+
+// JudgeMove classifies the move of the color mover by the evaluations of the positions before and after it.
+// It returns Inaccuracy, Mistake, Blunder or NoNAG if the move is good enough.
+func JudgeMove(before, after Eval, mover PieceColor) NAG {
+	drop := before.WinningChances() - after.WinningChances()
+	if mover == Black {
+		drop = -drop
+	}
+	switch {
+	case drop >= BlunderDrop:
+		return Blunder
+	case drop >= MistakeDrop:
+		return Mistake
+	case drop >= InaccuracyDrop:
+		return Inaccuracy
+	}
+	return NoNAG
+}
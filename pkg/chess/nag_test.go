@@ -0,0 +1,46 @@
+package chess
+
+import "testing"
+
+func TestJudgeMove(t *testing.T) {
+	tcs := []struct {
+		name          string
+		before, after Eval
+		mover         PieceColor
+		want          NAG
+	}{
+		{"best move", Eval{Centipawns: 30}, Eval{Centipawns: 35}, White, NoNAG},
+		{"small loss", Eval{Centipawns: 30}, Eval{Centipawns: 0}, White, NoNAG},
+		{"inaccuracy", Eval{Centipawns: 50}, Eval{Centipawns: -20}, White, Inaccuracy},
+		{"mistake", Eval{Centipawns: 0}, Eval{Centipawns: 150}, Black, Mistake},
+		{"blunder", Eval{Centipawns: 100}, Eval{Centipawns: -200}, White, Blunder},
+		{"missed mate", Eval{Mate: -3}, Eval{Centipawns: -200}, Black, Blunder},
+		{"still winning", Eval{Centipawns: 1500}, Eval{Centipawns: 900}, White, NoNAG},
+		{"good move of the opponent", Eval{Centipawns: 100}, Eval{Centipawns: -200}, Black, NoNAG},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			if got := JudgeMove(tc.before, tc.after, tc.mover); got != tc.want {
+				tt.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNAGString(t *testing.T) {
+	tcs := []struct {
+		nag  NAG
+		want string
+	}{
+		{Inaccuracy, "?!"},
+		{Mistake, "?"},
+		{Blunder, "??"},
+		{BrilliantMove, "!!"},
+		{NAG(14), "$14"},
+	}
+	for _, tc := range tcs {
+		if got := tc.nag.String(); got != tc.want {
+			t.Errorf("%d: want %q, got %q", int(tc.nag), tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pieceNames are the English names of the pieces in the singular and the plural.
+var pieceNames = map[PieceKind][2]string{
+	Pawn:   {"pawn", "pawns"},
+	Knight: {"knight", "knights"},
+	Bishop: {"bishop", "bishops"},
+	Rook:   {"rook", "rooks"},
+	Queen:  {"queen", "queens"},
+	King:   {"king", "kings"},
+}
+
+// describeOrder is the order in which the pieces are listed in a description of a position.
+var describeOrder = [...]PieceKind{King, Queen, Rook, Bishop, Knight, Pawn}
+
+// DescribeMove describes the move in English prose, e.g. "White's knight takes the bishop on f6, giving check".
+// The move must be legal in the state.
+func (st State) DescribeMove(mov Move) string {
+	player := capitalize(st.ToMove.Name())
+	p := st.Position.Get(mov.From)
+
+	bldr := strings.Builder{}
+	switch {
+	case mov.Castle && mov.To.file == 6:
+		fmt.Fprintf(&bldr, "%s castles kingside", player)
+	case mov.Castle:
+		fmt.Fprintf(&bldr, "%s castles queenside", player)
+	case mov.EnPassant:
+		captured := Square{file: mov.To.file, rank: mov.From.rank}
+		fmt.Fprintf(&bldr, "%s's pawn takes the pawn on %s en passant", player, captured)
+	default:
+		fmt.Fprintf(&bldr, "%s's %s ", player, pieceNames[p.Kind][0])
+		if captured := st.Position.Get(mov.To); captured.Kind != None {
+			fmt.Fprintf(&bldr, "takes the %s on %s", pieceNames[captured.Kind][0], mov.To)
+		} else {
+			fmt.Fprintf(&bldr, "moves to %s", mov.To)
+		}
+		if mov.Promotion.Kind != None {
+			fmt.Fprintf(&bldr, " and promotes to a %s", pieceNames[mov.Promotion.Kind][0])
+		}
+	}
+
+	next := st.Apply(mov)
+	switch {
+	case next.IsCheckmate():
+		bldr.WriteString(", giving checkmate")
+	case next.InCheck():
+		bldr.WriteString(", giving check")
+	case next.IsStalemate():
+		bldr.WriteString(", giving stalemate")
+	}
+	return bldr.String()
+}
+
+// Describe lists the pieces of the position in English, e.g. "White: King g1, Rooks a1 and f1. Black: King g8."
+func (pos Position) Describe() string {
+	var sides []string
+	for color := White; color <= Black; color++ {
+		var groups []string
+		for _, kind := range describeOrder {
+			var sqs []string
+			for file := range pos {
+				for rank := range pos[file] {
+					if pos[file][rank] == (Piece{Kind: kind, Color: color}) {
+						sqs = append(sqs, Square{file: file, rank: rank}.String())
+					}
+				}
+			}
+			if len(sqs) == 0 {
+				continue
+			}
+			name := pieceNames[kind][0]
+			if len(sqs) > 1 {
+				name = pieceNames[kind][1]
+			}
+			groups = append(groups, capitalize(name)+" "+joinAnd(sqs))
+		}
+		if len(groups) == 0 {
+			groups = []string{"no pieces"}
+		}
+		sides = append(sides, fmt.Sprintf("%s: %s.", capitalize(color.Name()), strings.Join(groups, ", ")))
+	}
+	return strings.Join(sides, " ")
+}
+
+// Describe describes the position and the side to move in English,
+// e.g. "White: King g1, Rooks a1 and f1. Black: King g8. Black to move."
+func (st State) Describe() string {
+	return fmt.Sprintf("%s %s to move.", st.Position.Describe(), capitalize(st.ToMove.Name()))
+}
+
+// MoveNumber returns the number of the next move as it is written in the movetext ("12." or "12...").
+func (st State) MoveNumber() string {
+	if st.ToMove == White {
+		return fmt.Sprintf("%d.", st.FullmoveNumber)
+	}
+	return fmt.Sprintf("%d...", st.FullmoveNumber)
+}
+
+// joinAnd joins the items as an English enumeration ("a", "a and b", "a, b and c").
+func joinAnd(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// capitalize makes the first letter of an ASCII string uppercase.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
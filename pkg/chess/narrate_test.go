@@ -0,0 +1,46 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStateDescribeMove(t *testing.T) {
+	tcs := []struct {
+		name string
+		fen  string
+		mov  move
+		want string
+	}{
+		{"move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", move{from: "g1", to: "f3"}, "White's knight moves to f3"},
+		{"capture with check", "4k3/4p3/8/8/8/8/8/4R1K1 w - - 0 1", move{from: "e1", to: "e7"}, "White's rook takes the pawn on e7, giving check"},
+		{"black", "4k3/8/8/8/8/8/3P4/2b1K3 b - - 0 1", move{from: "c1", to: "d2"}, "Black's bishop takes the pawn on d2, giving check"},
+		{"castling", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", move{from: "e1", to: "g1", cs: true}, "White castles kingside"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", move{from: "e5", to: "d6", ep: true}, "White's pawn takes the pawn on d5 en passant"},
+		{"promotion", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", move{from: "b7", to: "b8", pr: Piece{Knight, White}}, "White's pawn moves to b8 and promotes to a knight"},
+		{"checkmate", "6k1/5ppp/8/8/8/8/8/R3K3 w Q - 0 1", move{from: "a1", to: "a8"}, "White's rook moves to a8, giving checkmate"},
+		{"stalemate", "7k/8/6K1/5Q2/8/8/8/8 w - - 0 1", move{from: "f5", to: "f7"}, "White's queen moves to f7, giving stalemate"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if got := st.DescribeMove(getMove(tc.mov)); got != tc.want {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateDescribe(t *testing.T) {
+	st, err := ParseFEN(strings.NewReader("6k1/5ppp/8/8/8/8/8/R3K2R b KQ - 0 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "White: King e1, Rooks a1 and h1. Black: King g8, Pawns f7, g7 and h7. Black to move."
+	if got := st.Describe(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
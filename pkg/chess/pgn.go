@@ -2,6 +2,7 @@ package chess
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -10,11 +11,26 @@ import (
 
 type PGNResult struct {
 	Start Position
-	Moves []Move
-	Tags  map[string]string
+	// StartState is the full state at Start (see ParseFEN for the defaults if FEN tag is incomplete).
+	StartState State
+	Moves      []Move
+	// Comments[i] is the text of the comments following Moves[i] (empty if there are none).
+	Comments []string
+	Tags     Tags
+	// Warnings contains non-fatal problems with the notation (e.g. malformed tag values).
+	// Each warning is a ParseError.
+	Warnings []error
+	// NAGs[i] is the judgement of Moves[i] (see JudgeMove) and BestMoves[i] is the best move in the position
+	// before it if the game is analysed by an engine. They are not set by the parser.
+	NAGs      []NAG
+	BestMoves []Move
+	// Partial is set by ParsePGNPartial if the error is in the movetext, so Moves are the ones preceding it.
+	Partial bool
 }
 
-func parseTag(r io.RuneScanner) (key string, value string, err error) {
+var ErrUnexpectedEOF = errors.New("unexpected end of input")
+
+func parseTag(in *inputReader) (key string, value string, valueLoc location, err error) {
 	var (
 		krs []rune
 		vrs []rune
@@ -30,9 +46,12 @@ func parseTag(r io.RuneScanner) (key string, value string, err error) {
 	escape := false
 Loop:
 	for {
-		c, _, err := r.ReadRune()
+		c, _, err := in.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return key, value, valueLoc, in.errorAt(in.loc, ErrUnexpectedEOF)
+		}
 		if err != nil {
-			return key, value, err
+			return key, value, valueLoc, err
 		}
 		switch state {
 		case KEY:
@@ -44,13 +63,14 @@ Loop:
 		case SPACE:
 			if c == '"' {
 				state = VALUE
+				valueLoc = in.loc
 			} else if !unicode.IsSpace(c) {
-				return key, value, fmt.Errorf("unexpected %U", c)
+				return key, value, valueLoc, in.errorAt(in.lastLocation(), fmt.Errorf("unexpected %U", c))
 			}
 		case VALUE:
 			if escape {
 				if c != '\\' && c != '"' {
-					return key, value, fmt.Errorf("invalid escape sequence \"\\%c\"", c)
+					return key, value, valueLoc, in.errorAt(in.lastLocation(), fmt.Errorf("invalid escape sequence \"\\%c\"", c))
 				}
 				vrs = append(vrs, c)
 				escape = false
@@ -67,69 +87,100 @@ Loop:
 		}
 	}
 
-	c, _, err := r.ReadRune()
-	if err != nil {
-		return key, value, err
+	c, _, err := in.ReadRune()
+	if errors.Is(err, io.EOF) {
+		return key, value, valueLoc, in.errorAt(in.loc, ErrUnexpectedEOF)
+	} else if err != nil {
+		return key, value, valueLoc, err
 	} else if c != ']' {
-		return key, value, fmt.Errorf("expected \"]\" (%U), got %U", ']', c)
+		return key, value, valueLoc, in.errorAt(in.lastLocation(), fmt.Errorf("expected \"]\" (%U), got %U", ']', c))
 	}
 
-	return string(krs), string(vrs), nil
+	return string(krs), string(vrs), valueLoc, nil
 }
 
-func parsePGNTags(r io.RuneScanner) (map[string]string, error) {
-	tags := make(map[string]string)
+// parsePGNTags parses the tag section. For each tag the location of its value is
+// returned, so that errors in the values (e.g. FEN) can be reported.
+func parsePGNTags(in *inputReader) (Tags, map[string]location, error) {
+	var tags Tags
+	locs := make(map[string]location)
 	for {
-		c, _, err := r.ReadRune()
+		c, _, err := in.ReadRune()
+		if errors.Is(err, io.EOF) {
+			// the movetext is required, at least the result of the game
+			return tags, locs, in.errorAt(in.loc, ErrUnexpectedEOF)
+		}
 		if err != nil {
-			return tags, err
+			return tags, locs, err
 		}
 		if unicode.IsSpace(c) {
 			continue
 		}
 		if c == '[' {
-			key, value, err := parseTag(r)
+			key, value, loc, err := parseTag(in)
 			if err != nil {
-				return tags, err
+				return tags, locs, err
 			}
-			tags[key] = value
+			tags = tags.Set(key, value)
+			locs[key] = loc
 		} else {
-			return tags, r.UnreadRune()
+			return tags, locs, in.UnreadRune()
 		}
 	}
 }
 
 func ParsePGN(r io.Reader) (PGNResult, error) {
+	return parsePGN(r, false)
+}
+
+// ParsePGNPartial acts like ParsePGN, but in case of an error in the movetext (e.g. an illegal move)
+// the moves preceding the error are returned in PGNResult along with the error and PGNResult.Partial is set.
+// Errors in the tag section are still fatal and leave PGNResult.Moves empty.
+func ParsePGNPartial(r io.Reader) (PGNResult, error) {
+	return parsePGN(r, true)
+}
+
+func parsePGN(r io.Reader, partial bool) (PGNResult, error) {
 	res := PGNResult{}
 
-	var rs io.RuneScanner
-	if rrs, ok := r.(io.RuneScanner); ok {
-		rs = rrs
+	var rr io.RuneReader
+	if rrr, ok := r.(io.RuneReader); ok {
+		rr = rrr
 	} else {
-		rs = bufio.NewReader(r)
+		rr = bufio.NewReader(r)
 	}
+	in := newInputReader(rr)
 
-	tags, err := parsePGNTags(rs)
+	tags, locs, err := parsePGNTags(in)
 	if err != nil {
 		return res, err
 	}
 	res.Tags = tags
 
-	if notation, exists := tags["FEN"]; exists {
-		pos, err := FEN().Parse(strings.NewReader(notation))
+	if notation, exists := tags.Get("FEN"); exists {
+		st, err := ParseFEN(strings.NewReader(notation))
 		if err != nil {
-			return res, err
+			// escape sequences are not taken into account, but they are not expected in FEN anyway
+			return res, in.rebase(locs["FEN"], err)
 		}
-		res.Start = pos
+		res.StartState = st
 	} else {
-		res.Start = StartingPosition()
+		res.StartState = StartingState()
 	}
+	res.Start = res.StartState.Position
 
-	movs, err := Algebraic().Parse(res.Start, rs)
-	if err != nil {
-		return res, err
-	}
+	ap := &algParser{partial: partial}
+	movs, err := ap.Parse(res.Start, in)
 	res.Moves = movs
+	if movs != nil {
+		res.Comments = ap.comments[:len(movs)]
+	}
+	res.Partial = partial && err != nil
 
-	return res, nil
+	// warnings are collected after the movetext is parsed, because
+	// getting the snippet may consume the rest of the current line
+	for _, terr := range tags.Validate() {
+		res.Warnings = append(res.Warnings, in.errorAt(locs[terr.Name], terr))
+	}
+	return res, err
 }
@@ -1,6 +1,7 @@
 package chess
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -24,9 +25,9 @@ func TestPgnParse(t *testing.T) {
 			notation: "[Foo \"bar\"]\n[Baz \"quux\"]\n\n1. e4 e5 2. Nf3 Nf6 3. Nxe5 Nc6 4. Nxc6 dxc6",
 			want: pgnResult{
 				movs: "1. e4 e5 2. Nf3 Nf6 3. Nxe5 Nc6 4. Nxc6 dxc6",
-				tags: map[string]string{
-					"Foo": "bar",
-					"Baz": "quux",
+				tags: Tags{
+					{"Foo", "bar"},
+					{"Baz", "quux"},
 				},
 			},
 		},
@@ -36,8 +37,8 @@ func TestPgnParse(t *testing.T) {
 			want: pgnResult{
 				start: "k7/1p6/8/8/8/8/6P1/7K",
 				movs:  "1. g4 b5 2. g5 b4",
-				tags: map[string]string{
-					"FEN": "k7/1p6/8/8/8/8/6P1/7K w - - 0 1",
+				tags: Tags{
+					{"FEN", "k7/1p6/8/8/8/8/6P1/7K w - - 0 1"},
 				},
 			},
 		},
@@ -46,9 +47,9 @@ func TestPgnParse(t *testing.T) {
 			notation: "[Foo \"ba\\\"r\"]\n[Baz \"qu\\\\ux\"]\n\n1. e4 e5 2. Nf3 Nf6 3. Nxe5 Nc6 4. Nxc6 dxc6",
 			want: pgnResult{
 				movs: "1. e4 e5 2. Nf3 Nf6 3. Nxe5 Nc6 4. Nxc6 dxc6",
-				tags: map[string]string{
-					"Foo": "ba\"r",
-					"Baz": "qu\\ux",
+				tags: Tags{
+					{"Foo", "ba\"r"},
+					{"Baz", "qu\\ux"},
 				},
 			},
 		},
@@ -101,21 +102,185 @@ func TestPgnParse(t *testing.T) {
 				tt.Fatalf("want:\n%s\ngot:\n%s\n", want.Start, got.Start)
 			}
 			assertMoves(tt, want.Moves, got.Moves)
-			for k, v := range want.Tags {
-				gv, exists := got.Tags[k]
-				if !exists {
-					tt.Errorf("missing tag %q", k)
-					continue
+			for i := 0; i < len(want.Tags) && i < len(got.Tags); i++ {
+				if want.Tags[i] != got.Tags[i] {
+					tt.Errorf("at [%d]: want tag %q, got %q", i, want.Tags[i], got.Tags[i])
 				}
-				if v != gv {
-					tt.Errorf("tag %q: want %q, got %q", k, v, gv)
-				}
-				delete(got.Tags, k)
 			}
-			if len(got.Tags) > 0 {
-				tt.Errorf("extra tags: %#v", got.Tags)
+			for i := len(want.Tags); i < len(got.Tags); i++ {
+				tt.Errorf("extra tag: %q", got.Tags[i])
+			}
+			for i := len(got.Tags); i < len(want.Tags); i++ {
+				tt.Errorf("missing tag: %q", want.Tags[i])
+			}
+		})
+	}
+
+}
+
+func TestPgnParseErrorLocation(t *testing.T) {
+	tcs := []struct {
+		name       string
+		notation   string
+		wantLine   int
+		wantColumn int
+		wantCaret  string
+	}{
+		{
+			name:       "bad tag",
+			notation:   "[Foo \"bar\"]\n[Baz quux]\n\n1. e4 e5",
+			wantLine:   2,
+			wantColumn: 6,
+			wantCaret:  "[Baz quux]\n     ^",
+		},
+		{
+			name:       "bad FEN tag",
+			notation:   "[Foo \"bar\"]\n[FEN \"k7/1p6/8/8/8/8/6P1/7K7 w - - 0 1\"]\n\n1. g4",
+			wantLine:   2,
+			wantColumn: 28,
+			wantCaret:  "[FEN \"k7/1p6/8/8/8/8/6P1/7K7 w - - 0 1\"]\n                           ^",
+		},
+		{
+			name:       "illegal move",
+			notation:   "[Foo \"bar\"]\n\n1. e4 e5\n2. Nf3 Nf5 3. Nxe5",
+			wantLine:   4,
+			wantColumn: 8,
+			wantCaret:  "2. Nf3 Nf5 3. Nxe5\n       ^",
+		},
+		{
+			name:       "invalid syntax",
+			notation:   "1. e4 e5\n2. Nf3 Nc6 3! Nxe5",
+			wantLine:   2,
+			wantColumn: 13,
+			wantCaret:  "2. Nf3 Nc6 3! Nxe5\n            ^",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			_, err := ParsePGN(strings.NewReader(tc.notation))
+			var perr ParseError
+			if !errors.As(err, &perr) {
+				tt.Fatalf("want ParseError, got %v", err)
+			}
+			if perr.Line != tc.wantLine || perr.Column != tc.wantColumn {
+				tt.Errorf("want %d:%d, got %d:%d", tc.wantLine, tc.wantColumn, perr.Line, perr.Column)
+			}
+			if perr.Caret() != tc.wantCaret {
+				tt.Errorf("want caret:\n%s\ngot:\n%s", tc.wantCaret, perr.Caret())
 			}
 		})
 	}
+}
 
+func TestPgnParseNoMovetext(t *testing.T) {
+	tcs := []struct {
+		name       string
+		notation   string
+		wantLine   int
+		wantColumn int
+	}{
+		{name: "empty", notation: "", wantLine: 1, wantColumn: 1},
+		{name: "spaces", notation: "  \n ", wantLine: 2, wantColumn: 2},
+		{name: "tags only", notation: "[Event \"Test\"]\n[Result \"1-0\"]", wantLine: 2, wantColumn: 15},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			_, err := ParsePGN(strings.NewReader(tc.notation))
+			if !errors.Is(err, ErrUnexpectedEOF) {
+				tt.Fatalf("want ErrUnexpectedEOF, got %v", err)
+			}
+			var perr ParseError
+			if !errors.As(err, &perr) {
+				tt.Fatalf("want ParseError, got %v", err)
+			}
+			if perr.Line != tc.wantLine || perr.Column != tc.wantColumn {
+				tt.Errorf("want %d:%d, got %d:%d", tc.wantLine, tc.wantColumn, perr.Line, perr.Column)
+			}
+		})
+	}
+
+	// a game without moves still has the result
+	res, err := ParsePGN(strings.NewReader("[Event \"Test\"]\n\n*"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(res.Moves) != 0 {
+		t.Errorf("want no moves, got %d", len(res.Moves))
+	}
+}
+
+func TestPgnParsePartial(t *testing.T) {
+	notation := "1. e4 e5 2. Nf3 Nf5 3. Nxe5"
+	want := getPgnResult(pgnResult{movs: "1. e4 e5 2. Nf3"})
+
+	got, err := ParsePGNPartial(strings.NewReader(notation))
+	var ierr IllegalMoveError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("want IllegalMoveError, got %v", err)
+	}
+	if ierr.Notation != "Nf5" {
+		t.Errorf("want error at %q, got %q", "Nf5", ierr.Notation)
+	}
+	assertMoves(t, want.Moves, got.Moves)
+	if !got.Partial {
+		t.Errorf("want partial result")
+	}
+
+	got, err = ParsePGN(strings.NewReader(notation))
+	if err == nil {
+		t.Fatalf("want error from ParsePGN")
+	}
+	if len(got.Moves) > 0 || got.Partial {
+		t.Errorf("want no moves from ParsePGN, got %d", len(got.Moves))
+	}
+
+	// the error is in the first move
+	got, err = ParsePGNPartial(strings.NewReader("1. e5"))
+	if err == nil || !got.Partial || len(got.Moves) != 0 {
+		t.Errorf("illegal first move: want partial result without moves, got %v (partial: %v)", err, got.Partial)
+	}
+
+	// errors in the tags are fatal
+	got, err = ParsePGNPartial(strings.NewReader("[FEN \"8/8/8\"]\n\n1. e4"))
+	if err == nil || got.Partial {
+		t.Errorf("invalid FEN tag: want fatal error, got %v (partial: %v)", err, got.Partial)
+	}
+}
+
+func TestPgnParseWarnings(t *testing.T) {
+	notation := "[Event \"Test\"]\n[Date \"2023.13.01\"]\n\n1. e4 e5"
+	res, err := ParsePGN(strings.NewReader(notation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) != 1 {
+		t.Fatalf("want 1 warning, got %v", res.Warnings)
+	}
+	var perr ParseError
+	if !errors.As(res.Warnings[0], &perr) {
+		t.Fatalf("want ParseError, got %v", res.Warnings[0])
+	}
+	if perr.Line != 2 || perr.Column != 8 {
+		t.Errorf("want 2:8, got %d:%d", perr.Line, perr.Column)
+	}
+	var terr TagError
+	if !errors.As(perr, &terr) || terr.Name != "Date" {
+		t.Errorf("want TagError for Date, got %v", perr.Err)
+	}
+	if len(res.Moves) != 2 {
+		t.Errorf("want 2 moves, got %d", len(res.Moves))
+	}
+}
+
+func TestPgnParseComments(t *testing.T) {
+	notation := "{ leading } 1. e4 { [%eval 0.17] } { best } e5{[%eval 0.2]} 2. Nf3 *"
+	res, err := ParsePGN(strings.NewReader(notation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"[%eval 0.17] best", "[%eval 0.2]", ""}
+	if strings.Join(res.Comments, "|") != strings.Join(want, "|") {
+		t.Errorf("want %q, got %q", want, res.Comments)
+	}
 }
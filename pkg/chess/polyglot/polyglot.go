@@ -0,0 +1,198 @@
+// Package polyglot implements reading of opening books in Polyglot (.bin) format.
+package polyglot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+const entrySize = 16
+
+// Key returns Polyglot hash of the game state.
+func Key(st chess.State) uint64 {
+	var key uint64
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			p := st.Position.Get(chess.MustNewSquare(file, rank))
+			if p.Kind == chess.None {
+				continue
+			}
+			key ^= random64[64*pieceIndex(p)+8*rank+file]
+		}
+	}
+
+	for i, cr := range []chess.CastlingRights{chess.WhiteKingside, chess.WhiteQueenside, chess.BlackKingside, chess.BlackQueenside} {
+		if st.Castling&cr != 0 {
+			key ^= random64[768+i]
+		}
+	}
+
+	// the en passant file is hashed only if a pawn can capture (legality is not checked)
+	if st.EnPassantCapturable() {
+		key ^= random64[772+st.EnPassant.File()]
+	}
+
+	if st.ToMove == chess.White {
+		key ^= random64[780]
+	}
+	return key
+}
+
+// pieceIndex returns "kind of piece" as defined by Polyglot (black pawn is 0, white pawn is 1, black knight is 2 etc).
+func pieceIndex(p chess.Piece) int {
+	var kind int
+	switch p.Kind {
+	case chess.Pawn:
+		kind = 0
+	case chess.Knight:
+		kind = 1
+	case chess.Bishop:
+		kind = 2
+	case chess.Rook:
+		kind = 3
+	case chess.Queen:
+		kind = 4
+	case chess.King:
+		kind = 5
+	}
+	if p.Color == chess.White {
+		return kind*2 + 1
+	}
+	return kind * 2
+}
+
+type entry struct {
+	key    uint64
+	move   uint16
+	weight uint16
+	learn  uint32
+}
+
+// Book is a Polyglot opening book loaded in memory.
+type Book struct {
+	entries []entry
+}
+
+// BookMove is a move from the book with its weight (greater weight means better move).
+type BookMove struct {
+	Move   chess.Move
+	Weight int
+}
+
+// ReadBook reads a book in Polyglot format from r.
+func ReadBook(r io.Reader) (*Book, error) {
+	book := &Book{}
+	buf := make([]byte, entrySize)
+	for {
+		_, err := io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("book size must be a multiple of %d bytes", entrySize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		book.entries = append(book.entries, entry{
+			key:    binary.BigEndian.Uint64(buf[0:8]),
+			move:   binary.BigEndian.Uint16(buf[8:10]),
+			weight: binary.BigEndian.Uint16(buf[10:12]),
+			learn:  binary.BigEndian.Uint32(buf[12:16]),
+		})
+	}
+	// books are supposed to be sorted, but make sure that binary search works
+	sort.SliceStable(book.entries, func(i, j int) bool {
+		return book.entries[i].key < book.entries[j].key
+	})
+	return book, nil
+}
+
+// Moves returns book moves for the state sorted by weight in descending order.
+// If the state is not in the book, nil is returned.
+func (book *Book) Moves(st chess.State) []BookMove {
+	key := Key(st)
+	i := sort.Search(len(book.entries), func(i int) bool {
+		return book.entries[i].key >= key
+	})
+
+	var movs []BookMove
+	for ; i < len(book.entries) && book.entries[i].key == key; i++ {
+		mov, err := decodeMove(st.Position, book.entries[i].move)
+		if err != nil {
+			continue
+		}
+		movs = append(movs, BookMove{Move: mov, Weight: int(book.entries[i].weight)})
+	}
+	sort.SliceStable(movs, func(i, j int) bool {
+		return movs[i].Weight > movs[j].Weight
+	})
+	return movs
+}
+
+// Contains reports whether mov is a book move in the state.
+func (book *Book) Contains(st chess.State, mov chess.Move) bool {
+	for _, bm := range book.Moves(st) {
+		if bm.Move == mov {
+			return true
+		}
+	}
+	return false
+}
+
+// Depth returns the number of leading moves of the game (played from start) that are book moves.
+func (book *Book) Depth(start chess.State, movs []chess.Move) int {
+	st := start
+	for i, mov := range movs {
+		if !book.Contains(st, mov) {
+			return i
+		}
+		st = st.Apply(mov)
+	}
+	return len(movs)
+}
+
+var promotions = [...]chess.PieceKind{chess.None, chess.Knight, chess.Bishop, chess.Rook, chess.Queen}
+
+// decodeMove converts a move from Polyglot format (to file, to row, from file, from row and promotion piece
+// packed in 3-bit fields) to chess.Move.
+func decodeMove(pos chess.Position, m uint16) (chess.Move, error) {
+	field := func(i int) int {
+		return int(m>>(3*i)) & 7
+	}
+	if field(4) >= len(promotions) {
+		return chess.Move{}, errors.New("invalid promotion piece")
+	}
+
+	mov := chess.Move{
+		From: chess.MustNewSquare(field(2), field(3)),
+		To:   chess.MustNewSquare(field(0), field(1)),
+	}
+	p := pos.Get(mov.From)
+	if p.Kind == chess.None {
+		return chess.Move{}, errors.New("no piece on the source square")
+	}
+	if kind := promotions[field(4)]; kind != chess.None {
+		mov.Promotion = chess.Piece{Kind: kind, Color: p.Color}
+	}
+
+	// castling is encoded as "king captures own rook"
+	if p.Kind == chess.King && mov.From.File() == 4 && pos.Get(mov.To) == (chess.Piece{Kind: chess.Rook, Color: p.Color}) {
+		mov.Castle = true
+		if mov.To.File() == 7 {
+			mov.To = chess.MustNewSquare(6, mov.From.Rank())
+		} else {
+			mov.To = chess.MustNewSquare(2, mov.From.Rank())
+		}
+	}
+
+	if p.Kind == chess.Pawn && mov.From.File() != mov.To.File() && pos.Get(mov.To).Kind == chess.None {
+		mov.EnPassant = true
+	}
+	return mov, nil
+}
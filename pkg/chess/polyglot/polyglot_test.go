@@ -0,0 +1,198 @@
+package polyglot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+var update = flag.Bool("update", false, "regenerate testdata/book.bin")
+
+func parseMoves(notation string) []chess.Move {
+	movs, err := chess.Algebraic().Parse(chess.StartingPosition(), strings.NewReader(notation))
+	if err != nil {
+		panic(err)
+	}
+	return movs
+}
+
+func stateAfter(notation string) chess.State {
+	st := chess.StartingState()
+	for _, mov := range parseMoves(notation) {
+		st = st.Apply(mov)
+	}
+	return st
+}
+
+func TestKey(t *testing.T) {
+	// test vectors from the Polyglot book format specification
+	tcs := []struct {
+		notation string
+		want     uint64
+	}{
+		{"", 0x463b96181691fc9c},
+		{"1. e4", 0x823c9b50fd114196},
+		{"1. e4 d5", 0x0756b94461c50fb0},
+		{"1. e4 d5 2. e5", 0x662fafb965db29d4},
+		{"1. e4 d5 2. e5 f5", 0x22a48b5a8e47ff78},
+		{"1. e4 d5 2. e5 f5 3. Ke2", 0x652a607ca3f242c1},
+		{"1. e4 d5 2. e5 f5 3. Ke2 Kf7", 0x00fdd303c946bdd9},
+		{"1. a4 b5 2. h4 b4 3. c4", 0x3c8123ea7b067637},
+		{"1. a4 b5 2. h4 b4 3. c4 bxc3 4. Ra3", 0x5c3f9b829b279560},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.notation, func(tt *testing.T) {
+			if got := Key(stateAfter(tc.notation)); got != tc.want {
+				tt.Errorf("want %016x, got %016x", tc.want, got)
+			}
+		})
+	}
+}
+
+// testBook lists the lines of the test book. Every move of a line gets the same weight.
+var testBook = []struct {
+	notation string
+	weight   uint16
+}{
+	{"1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. O-O", 10},
+	{"1. e4 c5 2. Nf3", 5},
+	{"1. d4 d5", 3},
+	{"1. a4 b5 2. h4 b4 3. c4 bxc3", 1},
+}
+
+func TestGenerateBook(t *testing.T) {
+	if !*update {
+		t.SkipNow()
+	}
+	weights := make(map[[2]uint64]uint16)
+	for _, line := range testBook {
+		st := chess.StartingState()
+		for _, mov := range parseMoves(line.notation) {
+			weights[[2]uint64{Key(st), uint64(encodeMove(mov))}] += line.weight
+			st = st.Apply(mov)
+		}
+	}
+	entries := make([]entry, 0, len(weights))
+	for km, w := range weights {
+		entries = append(entries, entry{key: km[0], move: uint16(km[1]), weight: w})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].key != entries[j].key {
+			return entries[i].key < entries[j].key
+		}
+		return entries[i].move < entries[j].move
+	})
+
+	buf := &bytes.Buffer{}
+	for _, e := range entries {
+		b := make([]byte, entrySize)
+		binary.BigEndian.PutUint64(b[0:8], e.key)
+		binary.BigEndian.PutUint16(b[8:10], e.move)
+		binary.BigEndian.PutUint16(b[10:12], e.weight)
+		binary.BigEndian.PutUint32(b[12:16], e.learn)
+		buf.Write(b)
+	}
+	if err := os.WriteFile(filepath.Join("testdata", "book.bin"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openTestBook(t *testing.T) *Book {
+	f, err := os.Open(filepath.Join("testdata", "book.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	book, err := ReadBook(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return book
+}
+
+func TestBookMoves(t *testing.T) {
+	book := openTestBook(t)
+
+	tcs := []struct {
+		name     string
+		notation string
+		want     []BookMove
+	}{
+		{
+			name:     "starting position",
+			notation: "",
+			want: []BookMove{
+				{Move: parseMoves("1. e4")[0], Weight: 15},
+				{Move: parseMoves("1. d4")[0], Weight: 3},
+				{Move: parseMoves("1. a4")[0], Weight: 1},
+			},
+		},
+		{
+			name:     "castling",
+			notation: "1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5",
+			want: []BookMove{
+				{Move: chess.Move{From: chess.MustNewSquareFromString("e1"), To: chess.MustNewSquareFromString("g1"), Castle: true}, Weight: 10},
+			},
+		},
+		{
+			name:     "en passant",
+			notation: "1. a4 b5 2. h4 b4 3. c4",
+			want: []BookMove{
+				{Move: chess.Move{From: chess.MustNewSquareFromString("b4"), To: chess.MustNewSquareFromString("c3"), EnPassant: true}, Weight: 1},
+			},
+		},
+		{
+			name:     "out of book",
+			notation: "1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. O-O",
+			want:     nil,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			got := book.Moves(stateAfter(tc.notation))
+			if len(got) != len(tc.want) {
+				tt.Fatalf("want %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					tt.Errorf("at [%d]: want %v, got %v", i, tc.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBookDepth(t *testing.T) {
+	book := openTestBook(t)
+	movs := parseMoves("1. e4 e5 2. Nf3 Nc6 3. Bc4 Nf6 4. Ng5")
+	if got := book.Depth(chess.StartingState(), movs); got != 5 {
+		t.Errorf("want %d, got %d", 5, got)
+	}
+}
+
+// encodeMove is the reverse of decodeMove.
+func encodeMove(mov chess.Move) uint16 {
+	to := mov.To
+	if mov.Castle {
+		if to.File() == 6 {
+			to = chess.MustNewSquare(7, to.Rank())
+		} else {
+			to = chess.MustNewSquare(0, to.Rank())
+		}
+	}
+	var promotion int
+	for i, kind := range promotions {
+		if mov.Promotion.Kind != chess.None && kind == mov.Promotion.Kind {
+			promotion = i
+		}
+	}
+	return uint16(to.File() | to.Rank()<<3 | mov.From.File()<<6 | mov.From.Rank()<<9 | promotion<<12)
+}
@@ -0,0 +1,139 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+)
+
+// Material is a set of pieces of each color (indexed by PieceColor), including the kings.
+type Material [2][]PieceKind
+
+var (
+	materialPattern = regexp.MustCompile(`^\s*([KQRBNP]+)\s*(?:vs|v|-)\s*([KQRBNP]+)\s*$`)
+	materialKinds   = map[rune]PieceKind{'K': King, 'Q': Queen, 'R': Rook, 'B': Bishop, 'N': Knight, 'P': Pawn}
+)
+
+// ParseMaterial parses a material set in the usual notation of endgames, white first (e.g. "KRP vs KR" or "KQvK").
+// Each side must have exactly one king.
+func ParseMaterial(s string) (Material, error) {
+	var mat Material
+	m := materialPattern.FindStringSubmatch(s)
+	if m == nil {
+		return mat, fmt.Errorf("invalid material %q", s)
+	}
+	for color := White; color <= Black; color++ {
+		count := map[PieceKind]int{}
+		for _, c := range m[color+1] {
+			kind := materialKinds[c]
+			count[kind]++
+			mat[color] = append(mat[color], kind)
+		}
+		if count[King] != 1 {
+			return mat, fmt.Errorf("invalid material %q: %s must have exactly one king", s, color.Name())
+		}
+		if count[Pawn] > 8 || len(mat[color]) > 16 {
+			return mat, fmt.Errorf("invalid material %q: too many pieces of %s", s, color.Name())
+		}
+	}
+	return mat, nil
+}
+
+// RandomOptions are the constraints of RandomPosition.
+type RandomOptions struct {
+	Material Material
+	// ToMove is the side to move unless RandomToMove is set.
+	ToMove       PieceColor
+	RandomToMove bool
+	// AllowCheck allows the side to move to be in check.
+	AllowCheck bool
+	// MaxPawnRank is the most advanced rank of the pawns counted from their side (from 2 to 7, 0 means 7).
+	MaxPawnRank int
+	// NoDoubledPawns forbids two pawns of the same color on a file.
+	NoDoubledPawns bool
+	// Seed initializes the random generator, the same options always give the same position.
+	Seed int64
+}
+
+// maxRandomAttempts is the number of positions that RandomPosition generates before giving up.
+const maxRandomAttempts = 10000
+
+// ErrNoRandomPosition is returned by RandomPosition if it fails to find a position that satisfies the options.
+var ErrNoRandomPosition = errors.New("no legal position satisfies the options")
+
+// RandomPosition places the material randomly on the board so that the position is legal and the side
+// to move has a legal move. There are no castling rights and no en passant square in the position.
+func RandomPosition(opts RandomOptions) (State, error) {
+	maxRank := opts.MaxPawnRank
+	if maxRank == 0 {
+		maxRank = 7
+	}
+	if maxRank < 2 || maxRank > 7 {
+		return State{}, fmt.Errorf("invalid maximum pawn rank %d", maxRank)
+	}
+
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	for attempt := 0; attempt < maxRandomAttempts; attempt++ {
+		st := State{ToMove: opts.ToMove, FullmoveNumber: 1}
+		if opts.RandomToMove {
+			st.ToMove = PieceColor(rnd.Intn(2))
+		}
+		if !placeMaterial(&st.Position, opts, maxRank, rnd) {
+			continue
+		}
+		// the side that has just moved cannot be in check
+		if king, _ := st.Position.KingSquare(1 - st.ToMove); st.Position.Attacked(king, st.ToMove) {
+			continue
+		}
+		if !opts.AllowCheck && st.InCheck() {
+			continue
+		}
+		if len(st.LegalMoves()) == 0 {
+			continue
+		}
+		return st, nil
+	}
+	return State{}, ErrNoRandomPosition
+}
+
+// placeMaterial puts the pieces on random empty squares and reports whether it succeeded.
+func placeMaterial(pos *Position, opts RandomOptions, maxRank int, rnd *rand.Rand) bool {
+	for color := White; color <= Black; color++ {
+		for _, kind := range opts.Material[color] {
+			var sqs []Square
+			for file := 0; file < 8; file++ {
+				for rank := 0; rank < 8; rank++ {
+					sq := Square{file: file, rank: rank}
+					if pos.Get(sq).Kind == None && (kind != Pawn || pawnSquareAllowed(*pos, sq, color, opts, maxRank)) {
+						sqs = append(sqs, sq)
+					}
+				}
+			}
+			if len(sqs) == 0 {
+				return false
+			}
+			sq := sqs[rnd.Intn(len(sqs))]
+			pos[sq.file][sq.rank] = Piece{Kind: kind, Color: color}
+		}
+	}
+	return true
+}
+
+func pawnSquareAllowed(pos Position, sq Square, color PieceColor, opts RandomOptions, maxRank int) bool {
+	rank := sq.rank + 1
+	if color == Black {
+		rank = 8 - sq.rank
+	}
+	if rank < 2 || rank > maxRank {
+		return false
+	}
+	if opts.NoDoubledPawns {
+		for r := 0; r < 8; r++ {
+			if pos[sq.file][r] == (Piece{Kind: Pawn, Color: color}) {
+				return false
+			}
+		}
+	}
+	return true
+}
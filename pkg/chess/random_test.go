@@ -0,0 +1,105 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMaterial(t *testing.T) {
+	tcs := []struct {
+		s       string
+		want    Material
+		wantErr bool
+	}{
+		{s: "KRP vs KR", want: Material{{King, Rook, Pawn}, {King, Rook}}},
+		{s: "KQvK", want: Material{{King, Queen}, {King}}},
+		{s: "KBN - K", want: Material{{King, Bishop, Knight}, {King}}},
+		{s: "KR vs R", wantErr: true},
+		{s: "KPPPPPPPPP vs K", wantErr: true},
+		{s: "KX vs K", wantErr: true},
+	}
+	for _, tc := range tcs {
+		got, err := ParseMaterial(tc.s)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%q: want an error", tc.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tc.s, err)
+			continue
+		}
+		for color := range got {
+			if len(got[color]) != len(tc.want[color]) {
+				t.Errorf("%q: want %v, got %v", tc.s, tc.want, got)
+				break
+			}
+			for i := range got[color] {
+				if got[color][i] != tc.want[color][i] {
+					t.Errorf("%q: want %v, got %v", tc.s, tc.want, got)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestRandomPosition(t *testing.T) {
+	mat, err := ParseMaterial("KRPP vs KRP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		opts := RandomOptions{Material: mat, ToMove: Black, MaxPawnRank: 5, NoDoubledPawns: true, Seed: seed}
+		st, err := RandomPosition(opts)
+		if err != nil {
+			t.Fatalf("seed %d: %s", seed, err)
+		}
+		if again, _ := RandomPosition(opts); again != st {
+			t.Fatalf("seed %d: positions differ", seed)
+		}
+		if st.ToMove != Black || st.InCheck() || len(st.LegalMoves()) == 0 {
+			t.Fatalf("seed %d: bad position %s", seed, st.FEN())
+		}
+		if king, _ := st.Position.KingSquare(White); st.Position.Attacked(king, Black) {
+			t.Fatalf("seed %d: white is in check in %s", seed, st.FEN())
+		}
+
+		count := map[Piece]int{}
+		for file := range st.Position {
+			pawns := 0
+			for rank, p := range st.Position[file] {
+				count[p]++
+				if p.Kind != Pawn {
+					continue
+				}
+				rel := rank + 1
+				if p.Color == Black {
+					rel = 8 - rank
+				}
+				if rel < 2 || rel > 5 {
+					t.Fatalf("seed %d: pawn on rank %d in %s", seed, rel, st.FEN())
+				}
+				if p.Color == White {
+					pawns++
+				}
+			}
+			if pawns > 1 {
+				t.Fatalf("seed %d: doubled pawns in %s", seed, st.FEN())
+			}
+		}
+		if count[Piece{Pawn, White}] != 2 || count[Piece{Rook, Black}] != 1 || count[Piece{King, Black}] != 1 {
+			t.Fatalf("seed %d: wrong material in %s", seed, st.FEN())
+		}
+	}
+
+	if _, err := RandomPosition(RandomOptions{Material: mat, MaxPawnRank: 9}); err == nil {
+		t.Errorf("want an error for invalid pawn rank")
+	}
+	// ParseMaterial would not allow nine pawns
+	ninePawns := Material{{King, Pawn, Pawn, Pawn, Pawn, Pawn, Pawn, Pawn, Pawn, Pawn}, {King}}
+	if _, err := RandomPosition(RandomOptions{Material: ninePawns, NoDoubledPawns: true}); !errors.Is(err, ErrNoRandomPosition) {
+		t.Errorf("want ErrNoRandomPosition, got %v", err)
+	}
+}
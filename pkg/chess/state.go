@@ -0,0 +1,157 @@
+package chess
+
+import "strings"
+
+// CastlingRights is a set of flags for each castling that is still available in the game.
+type CastlingRights uint8
+
+const (
+	WhiteKingside CastlingRights = 1 << iota
+	WhiteQueenside
+	BlackKingside
+	BlackQueenside
+
+	NoCastling  CastlingRights = 0
+	AllCastling                = WhiteKingside | WhiteQueenside | BlackKingside | BlackQueenside
+)
+
+// String returns castling rights in FEN format (e.g. "KQkq" or "-").
+func (cr CastlingRights) String() string {
+	if cr == NoCastling {
+		return "-"
+	}
+	bldr := strings.Builder{}
+	for i, c := range "KQkq" {
+		if cr&(1<<i) != 0 {
+			bldr.WriteRune(c)
+		}
+	}
+	return bldr.String()
+}
+
+// State is the full state of the game: position together with the data from the rest of FEN fields.
+type State struct {
+	Position Position
+	ToMove   PieceColor
+	Castling CastlingRights
+	// EnPassant is the square behind a pawn that has just made a two-square move.
+	// It is meaningful only if HasEnPassant is true.
+	EnPassant    Square
+	HasEnPassant bool
+	// HalfmoveClock is the number of halfmoves since the last capture or pawn move.
+	HalfmoveClock int
+	// FullmoveNumber starts at 1 and is incremented after black's move.
+	FullmoveNumber int
+}
+
+// StartingState returns the state at the beginning of a standard game.
+func StartingState() State {
+	return State{
+		Position:       StartingPosition(),
+		ToMove:         White,
+		Castling:       AllCastling,
+		FullmoveNumber: 1,
+	}
+}
+
+// Apply returns the state after mov is made. The move is not checked for legality.
+func (st State) Apply(mov Move) State {
+	p := st.Position.Get(mov.From)
+	captured := st.Position.Get(mov.To)
+
+	next := st
+	next.Position = Apply(st.Position, mov)
+	next.ToMove = 1 - st.ToMove
+	if st.ToMove == Black {
+		next.FullmoveNumber++
+	}
+
+	if p.Kind == Pawn || captured.Kind != None || mov.EnPassant {
+		next.HalfmoveClock = 0
+	} else {
+		next.HalfmoveClock++
+	}
+
+	next.HasEnPassant = false
+	if p.Kind == Pawn && (mov.To.rank-mov.From.rank == 2 || mov.From.rank-mov.To.rank == 2) {
+		next.EnPassant = Square{file: mov.From.file, rank: (mov.From.rank + mov.To.rank) / 2}
+		next.HasEnPassant = true
+	}
+
+	if p.Kind == King {
+		if p.Color == White {
+			next.Castling &^= WhiteKingside | WhiteQueenside
+		} else {
+			next.Castling &^= BlackKingside | BlackQueenside
+		}
+	}
+	// a rook leaving or being captured on its original square
+	for _, sq := range [...]Square{mov.From, mov.To} {
+		switch sq {
+		case Square{7, 0}:
+			next.Castling &^= WhiteKingside
+		case Square{0, 0}:
+			next.Castling &^= WhiteQueenside
+		case Square{7, 7}:
+			next.Castling &^= BlackKingside
+		case Square{0, 7}:
+			next.Castling &^= BlackQueenside
+		}
+	}
+
+	return next
+}
+
+// Key identifies a state for the purposes of detecting transpositions and repetitions:
+// two states have equal keys if they have the same position, side to move, castling rights
+// and en passant capture possibility. Key is comparable and can be used as a map key.
+type Key struct {
+	position  Position
+	toMove    PieceColor
+	castling  CastlingRights
+	enPassant int // file of the en passant square or -1
+}
+
+func (st State) Key() Key {
+	key := Key{
+		position:  st.Position,
+		toMove:    st.ToMove,
+		castling:  st.Castling,
+		enPassant: -1,
+	}
+	// en passant square matters only if a pawn can actually capture on it
+	if st.EnPassantCapturable() {
+		key.enPassant = st.EnPassant.file
+	}
+	// normalize empty squares (they may have arbitrary color)
+	for file := range key.position {
+		for rank := range key.position[file] {
+			if key.position[file][rank].Kind == None {
+				key.position[file][rank] = Piece{}
+			}
+		}
+	}
+	return key
+}
+
+// EnPassantCapturable reports whether there is an en passant square and a pawn of the side to move next to the pawn
+// that has just made a two-square move (pins are not taken into account).
+func (st State) EnPassantCapturable() bool {
+	if !st.HasEnPassant {
+		return false
+	}
+	rank := st.EnPassant.rank - 1
+	if st.ToMove == Black {
+		rank = st.EnPassant.rank + 1
+	}
+	for df := -1; df <= 1; df += 2 {
+		sq, err := NewSquare(st.EnPassant.file+df, rank)
+		if err != nil {
+			continue
+		}
+		if st.Position.Get(sq) == (Piece{Kind: Pawn, Color: st.ToMove}) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStateApply(t *testing.T) {
+	tcs := []struct {
+		name          string
+		notation      string
+		wantCastling  CastlingRights
+		wantEnPassant string
+		wantHalfmove  int
+		wantFullmove  int
+	}{
+		{
+			name:          "double pawn step",
+			notation:      "1. e4",
+			wantCastling:  AllCastling,
+			wantEnPassant: "e3",
+			wantFullmove:  1,
+		},
+		{
+			name:         "king move",
+			notation:     "1. e4 e5 2. Ke2 Nc6",
+			wantCastling: BlackKingside | BlackQueenside,
+			wantHalfmove: 2,
+			wantFullmove: 3,
+		},
+		{
+			name:         "rook move",
+			notation:     "1. h4 a5 2. Rh3 Ra6",
+			wantCastling: WhiteQueenside | BlackKingside,
+			wantHalfmove: 2,
+			wantFullmove: 3,
+		},
+		{
+			name:         "rook captured",
+			notation:     "1. g3 b6 2. Bg2 Bb7 3. Bxb7 Nc6 4. Bxa8",
+			wantCastling: WhiteKingside | WhiteQueenside | BlackKingside,
+			wantFullmove: 4,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			movs, err := Algebraic().Parse(StartingPosition(), strings.NewReader(tc.notation))
+			if err != nil {
+				panic(err)
+			}
+			st := StartingState()
+			for _, mov := range movs {
+				st = st.Apply(mov)
+			}
+			if st.Castling != tc.wantCastling {
+				tt.Errorf("castling: want %s, got %s", tc.wantCastling, st.Castling)
+			}
+			if tc.wantEnPassant == "" && st.HasEnPassant {
+				tt.Errorf("en passant: want none, got %s", st.EnPassant)
+			}
+			if tc.wantEnPassant != "" && (!st.HasEnPassant || st.EnPassant.String() != tc.wantEnPassant) {
+				tt.Errorf("en passant: want %s, got %s (%t)", tc.wantEnPassant, st.EnPassant, st.HasEnPassant)
+			}
+			if st.HalfmoveClock != tc.wantHalfmove {
+				tt.Errorf("halfmove clock: want %d, got %d", tc.wantHalfmove, st.HalfmoveClock)
+			}
+			if st.FullmoveNumber != tc.wantFullmove {
+				tt.Errorf("fullmove number: want %d, got %d", tc.wantFullmove, st.FullmoveNumber)
+			}
+		})
+	}
+}
+
+func TestStateKey(t *testing.T) {
+	// the same position reached by different move orders
+	a, _ := Algebraic().Parse(StartingPosition(), strings.NewReader("1. Nf3 Nf6 2. Nc3 Nc6"))
+	b, _ := Algebraic().Parse(StartingPosition(), strings.NewReader("1. Nc3 Nc6 2. Nf3 Nf6"))
+	// the same pieces, but castling rights are lost
+	c, _ := Algebraic().Parse(StartingPosition(), strings.NewReader("1. Nf3 Nf6 2. Nc3 Nc6 3. Rb1 Rb8 4. Ra1 Ra8"))
+
+	keys := make([]Key, 0, 3)
+	for _, movs := range [][]Move{a, b, c} {
+		st := StartingState()
+		for _, mov := range movs {
+			st = st.Apply(mov)
+		}
+		keys = append(keys, st.Key())
+	}
+	if keys[0] != keys[1] {
+		t.Errorf("transposition: want equal keys")
+	}
+	if keys[0] == keys[2] {
+		t.Errorf("lost castling rights: want different keys")
+	}
+}
+
+func TestStateEnPassantCapturable(t *testing.T) {
+	tcs := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"no en passant square", "4k3/8/8/3pP3/8/8/8/4K3 w - - 0 1", false},
+		{"pawn next to it", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", true},
+		{"no pawn next to it", "4k3/8/8/3p4/8/8/4P3/4K3 w - d6 0 1", false},
+		{"black pawn", "4k3/8/8/8/3Pp3/8/8/4K3 b - d3 0 1", true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if got := st.EnPassantCapturable(); got != tc.want {
+				tt.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
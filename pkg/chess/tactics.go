@@ -0,0 +1,193 @@
+package chess
+
+// MotifKind is a kind of a tactical motif.
+type MotifKind int
+
+const (
+	// AbsolutePin is a piece that cannot move because the king is behind it.
+	AbsolutePin MotifKind = iota
+	// RelativePin is a piece that should not move because a more valuable piece is behind it.
+	RelativePin
+	// Fork is a piece attacking two or more pieces at once.
+	Fork
+	// Skewer is an attack on a valuable piece (often the king) that exposes a piece behind it.
+	Skewer
+	// DiscoveredAttack is an attack by a line piece that is opened when a piece in front of it moves.
+	DiscoveredAttack
+	// HangingPiece is an attacked piece that is not defended.
+	HangingPiece
+)
+
+var motifNames = map[MotifKind]string{
+	AbsolutePin:      "absolute pin",
+	RelativePin:      "relative pin",
+	Fork:             "fork",
+	Skewer:           "skewer",
+	DiscoveredAttack: "discovered attack",
+	HangingPiece:     "hanging piece",
+}
+
+func (kind MotifKind) String() string {
+	return motifNames[kind]
+}
+
+// Motif is a tactical motif found in a position.
+type Motif struct {
+	Kind MotifKind
+	// Attacker is the piece that creates the motif. For a hanging piece it is the least valuable attacker.
+	Attacker Square
+	// Targets are the attacked pieces. For pins, skewers and discovered attacks there is only one target
+	// in the line of attack, it is the piece behind Front.
+	Targets []Square
+	// Front is the piece in the line of attack in front of the target: the pinned or skewered piece
+	// or the piece of the attacker's color that uncovers the attack when moving away.
+	// It is meaningful only for pins, skewers and discovered attacks.
+	Front Square
+}
+
+// motifValues are the usual piece values in pawns (the king is more valuable than anything).
+var motifValues = map[PieceKind]int{
+	Pawn:   1,
+	Knight: 3,
+	Bishop: 3,
+	Rook:   5,
+	Queen:  9,
+	King:   100,
+}
+
+// Motifs returns all tactical motifs that the pieces of color by create in the position.
+func (pos Position) Motifs(by PieceColor) []Motif {
+	var motifs []Motif
+	motifs = append(motifs, pos.LineMotifs(by)...)
+	motifs = append(motifs, pos.Forks(by)...)
+	motifs = append(motifs, pos.HangingPieces(by)...)
+	return motifs
+}
+
+// LineMotifs returns the pins, skewers and discovered attacks by the rooks, bishops and queens of color by.
+func (pos Position) LineMotifs(by PieceColor) []Motif {
+	var motifs []Motif
+	for file := range pos {
+		for rank := range pos[file] {
+			from := Square{file: file, rank: rank}
+			p := pos.Get(from)
+			if p.Kind == None || p.Color != by {
+				continue
+			}
+			var dirs [][2]int
+			if p.Kind == Rook || p.Kind == Queen {
+				dirs = append(dirs, rookDirs[:]...)
+			}
+			if p.Kind == Bishop || p.Kind == Queen {
+				dirs = append(dirs, bishopDirs[:]...)
+			}
+			for _, dir := range dirs {
+				front, ok := pos.nextPiece(from, dir)
+				if !ok {
+					continue
+				}
+				target, ok := pos.nextPiece(front, dir)
+				if !ok {
+					continue
+				}
+				if m, ok := lineMotif(pos.Get(front), pos.Get(target), by); ok {
+					motifs = append(motifs, Motif{Kind: m, Attacker: from, Targets: []Square{target}, Front: front})
+				}
+			}
+		}
+	}
+	return motifs
+}
+
+// lineMotif classifies two pieces in the line of attack of a piece of color by.
+func lineMotif(front, target Piece, by PieceColor) (MotifKind, bool) {
+	if target.Color == by {
+		return 0, false
+	}
+	if front.Color == by {
+		// a discovered attack on a pawn is hardly a tactic
+		return DiscoveredAttack, target.Kind != Pawn
+	}
+	switch {
+	case target.Kind == King:
+		return AbsolutePin, true
+	case front.Kind == King || motifValues[front.Kind] > motifValues[target.Kind]:
+		return Skewer, target.Kind != Pawn
+	case motifValues[front.Kind] < motifValues[target.Kind]:
+		return RelativePin, true
+	}
+	return 0, false
+}
+
+// nextPiece returns the first occupied square from sq in the direction dir (not including sq).
+func (pos Position) nextPiece(sq Square, dir [2]int) (Square, bool) {
+	for d := 1; ; d++ {
+		next, ok := sq.offset(dir[0]*d, dir[1]*d)
+		if !ok {
+			return Square{}, false
+		}
+		if pos.Get(next).Kind != None {
+			return next, true
+		}
+	}
+}
+
+// Forks returns the pieces of color by that attack two or more enemy pieces that are worth attacking:
+// the king, pieces more valuable than the attacker and undefended pieces.
+func (pos Position) Forks(by PieceColor) []Motif {
+	targets := map[Square][]Square{}
+	var attackers []Square
+	for file := range pos {
+		for rank := range pos[file] {
+			sq := Square{file: file, rank: rank}
+			p := pos.Get(sq)
+			if p.Kind == None || p.Color == by {
+				continue
+			}
+			defended := pos.Attacked(sq, p.Color)
+			for _, from := range pos.Attackers(sq, by) {
+				if p.Kind != King && defended && motifValues[p.Kind] <= motifValues[pos.Get(from).Kind] {
+					continue
+				}
+				if targets[from] == nil {
+					attackers = append(attackers, from)
+				}
+				targets[from] = append(targets[from], sq)
+			}
+		}
+	}
+
+	var motifs []Motif
+	for _, from := range attackers {
+		if len(targets[from]) >= 2 {
+			motifs = append(motifs, Motif{Kind: Fork, Attacker: from, Targets: targets[from]})
+		}
+	}
+	return motifs
+}
+
+// HangingPieces returns the pieces of the opponent of color by that are attacked by it and not defended.
+func (pos Position) HangingPieces(by PieceColor) []Motif {
+	var motifs []Motif
+	for file := range pos {
+		for rank := range pos[file] {
+			sq := Square{file: file, rank: rank}
+			p := pos.Get(sq)
+			if p.Kind == None || p.Kind == King || p.Color == by || pos.Attacked(sq, p.Color) {
+				continue
+			}
+			attackers := pos.Attackers(sq, by)
+			if len(attackers) == 0 {
+				continue
+			}
+			least := attackers[0]
+			for _, from := range attackers[1:] {
+				if motifValues[pos.Get(from).Kind] < motifValues[pos.Get(least).Kind] {
+					least = from
+				}
+			}
+			motifs = append(motifs, Motif{Kind: HangingPiece, Attacker: least, Targets: []Square{sq}})
+		}
+	}
+	return motifs
+}
@@ -0,0 +1,81 @@
+package chess
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPositionMotifs(t *testing.T) {
+	sq := MustNewSquareFromString
+	tcs := []struct {
+		name string
+		fen  string
+		by   PieceColor
+		want []Motif
+	}{
+		{
+			name: "absolute pin",
+			fen:  "4k3/4n3/8/8/8/8/8/4RK2 w - - 0 1",
+			by:   White,
+			want: []Motif{{Kind: AbsolutePin, Attacker: sq("e1"), Targets: []Square{sq("e8")}, Front: sq("e7")}},
+		},
+		{
+			name: "relative pin",
+			fen:  "4q2k/8/8/4n3/8/8/8/4R1K1 w - - 0 1",
+			by:   White,
+			want: []Motif{{Kind: RelativePin, Attacker: sq("e1"), Targets: []Square{sq("e8")}, Front: sq("e5")}},
+		},
+		{
+			name: "skewer",
+			fen:  "8/8/q7/8/2k5/8/8/5B1K w - - 0 1",
+			by:   White,
+			want: []Motif{{Kind: Skewer, Attacker: sq("f1"), Targets: []Square{sq("a6")}, Front: sq("c4")}},
+		},
+		{
+			name: "fork",
+			fen:  "r3k3/2N5/8/8/8/8/8/4K3 w - - 0 1",
+			by:   White,
+			want: []Motif{
+				{Kind: Fork, Attacker: sq("c7"), Targets: []Square{sq("a8"), sq("e8")}},
+				{Kind: HangingPiece, Attacker: sq("c7"), Targets: []Square{sq("a8")}},
+			},
+		},
+		{
+			name: "defended pieces are not forked by a more valuable piece",
+			fen:  "4k3/8/1p3p2/2p1p3/3Q4/8/8/4K3 w - - 0 1",
+			by:   White,
+			want: nil,
+		},
+		{
+			name: "discovered attack",
+			fen:  "4k3/8/8/8/4N3/8/8/4RK2 w - - 0 1",
+			by:   White,
+			want: []Motif{{Kind: DiscoveredAttack, Attacker: sq("e1"), Targets: []Square{sq("e8")}, Front: sq("e4")}},
+		},
+		{
+			name: "hanging piece",
+			fen:  "4k3/8/8/3b4/8/8/8/3RK3 b - - 0 1",
+			by:   White,
+			want: []Motif{{Kind: HangingPiece, Attacker: sq("d1"), Targets: []Square{sq("d5")}}},
+		},
+		{
+			name: "black pins",
+			fen:  "4k3/8/8/b7/8/8/3N4/4K3 w - - 0 1",
+			by:   Black,
+			want: []Motif{{Kind: AbsolutePin, Attacker: sq("a5"), Targets: []Square{sq("e1")}, Front: sq("d2")}},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			got := st.Position.Motifs(tc.by)
+			if !reflect.DeepEqual(got, tc.want) {
+				tt.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SevenTagRoster lists the names of the tags that every PGN game should contain, in the export order.
+var SevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Tags is a list of PGN tags in the order they appear in the notation.
+// Typed accessors return zero values if the tag is missing or malformed, use Validate to find out which.
+type Tags []Tag
+
+// Get returns the value of a tag and whether it is present.
+func (tags Tags) Get(name string) (string, bool) {
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set replaces the value of a tag or appends a new tag to the end of the list.
+func (tags Tags) Set(name string, value string) Tags {
+	for i := range tags {
+		if tags[i].Name == name {
+			tags[i].Value = value
+			return tags
+		}
+	}
+	return append(tags, Tag{Name: name, Value: value})
+}
+
+func (tags Tags) value(name string) string {
+	v, _ := tags.Get(name)
+	return v
+}
+
+func (tags Tags) Event() string {
+	return tags.value("Event")
+}
+
+func (tags Tags) Site() string {
+	return tags.value("Site")
+}
+
+func (tags Tags) Date() Date {
+	d, _ := ParseDate(tags.value("Date"))
+	return d
+}
+
+func (tags Tags) Round() string {
+	return tags.value("Round")
+}
+
+func (tags Tags) White() string {
+	return tags.value("White")
+}
+
+func (tags Tags) Black() string {
+	return tags.value("Black")
+}
+
+// Result returns the game result ("1-0", "0-1", "1/2-1/2" or "*").
+func (tags Tags) Result() string {
+	if r := tags.value("Result"); validResult(r) {
+		return r
+	}
+	return ""
+}
+
+// WhiteElo returns the rating of the white player or 0 if it is unknown.
+func (tags Tags) WhiteElo() int {
+	elo, _ := parseElo(tags.value("WhiteElo"))
+	return elo
+}
+
+// BlackElo returns the rating of the black player or 0 if it is unknown.
+func (tags Tags) BlackElo() int {
+	elo, _ := parseElo(tags.value("BlackElo"))
+	return elo
+}
+
+// ECO returns the opening code from Encyclopaedia of Chess Openings (e.g. "B90").
+func (tags Tags) ECO() string {
+	if eco := tags.value("ECO"); validECO(eco) {
+		return eco
+	}
+	return ""
+}
+
+func (tags Tags) TimeControl() TimeControl {
+	tc, err := ParseTimeControl(tags.value("TimeControl"))
+	if err != nil {
+		return TimeControl{Unknown: true}
+	}
+	return tc
+}
+
+// TagError describes a malformed value of a known tag.
+type TagError struct {
+	Name   string
+	Value  string
+	Reason string
+}
+
+func (err TagError) Error() string {
+	return fmt.Sprintf("malformed %s tag %q: %s", err.Name, err.Value, err.Reason)
+}
+
+// Validate checks the values of known tags and returns an error for each malformed value.
+// Missing tags are not reported.
+func (tags Tags) Validate() []TagError {
+	var errs []TagError
+	for _, tag := range tags {
+		var err error
+		switch tag.Name {
+		case "Date":
+			_, err = ParseDate(tag.Value)
+		case "Round":
+			err = validateRound(tag.Value)
+		case "Result":
+			if !validResult(tag.Value) {
+				err = errors.New(`want "1-0", "0-1", "1/2-1/2" or "*"`)
+			}
+		case "WhiteElo", "BlackElo":
+			_, err = parseElo(tag.Value)
+		case "ECO":
+			if !validECO(tag.Value) && tag.Value != "?" {
+				err = errors.New("want a letter from A to E followed by two digits")
+			}
+		case "TimeControl":
+			_, err = ParseTimeControl(tag.Value)
+		}
+		if err != nil {
+			errs = append(errs, TagError{Name: tag.Name, Value: tag.Value, Reason: err.Error()})
+		}
+	}
+	return errs
+}
+
+func validResult(s string) bool {
+	return s == "1-0" || s == "0-1" || s == "1/2-1/2" || s == "*"
+}
+
+func validECO(s string) bool {
+	return len(s) == 3 && s[0] >= 'A' && s[0] <= 'E' && isDigits(s[1:])
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseElo parses a rating. Unknown ("", "?") and unrated ("-") players have a rating of 0.
+func parseElo(s string) (int, error) {
+	if s == "" || s == "?" || s == "-" {
+		return 0, nil
+	}
+	if !isDigits(s) {
+		return 0, errors.New("want a non-negative integer")
+	}
+	return strconv.Atoi(s)
+}
+
+func validateRound(s string) error {
+	if s == "?" || s == "-" {
+		return nil
+	}
+	for _, part := range strings.Split(s, ".") {
+		if !isDigits(part) {
+			return errors.New(`want "?", "-" or dot-separated numbers`)
+		}
+	}
+	return nil
+}
+
+// Date is a (possibly partial) date from the Date tag. Unknown components are zero.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// ParseDate parses a date in the PGN format ("YYYY.MM.DD"), where unknown components are replaced with question marks
+// (e.g. "1992.??.??").
+func ParseDate(s string) (Date, error) {
+	d := Date{}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return d, errors.New(`want "YYYY.MM.DD"`)
+	}
+	fields := []struct {
+		dst *int
+		len int
+		max int
+	}{
+		{&d.Year, 4, 9999}, {&d.Month, 2, 12}, {&d.Day, 2, 31},
+	}
+	for i, field := range fields {
+		part := parts[i]
+		if len(part) != field.len {
+			return Date{}, errors.New(`want "YYYY.MM.DD"`)
+		}
+		if part == strings.Repeat("?", field.len) {
+			continue
+		}
+		if !isDigits(part) {
+			return Date{}, errors.New(`want "YYYY.MM.DD" with digits or "?"`)
+		}
+		n, _ := strconv.Atoi(part)
+		if n < 1 || n > field.max {
+			return Date{}, fmt.Errorf("component %q is out of range", part)
+		}
+		*field.dst = n
+	}
+	return d, nil
+}
+
+func (d Date) String() string {
+	format := func(n, width int) string {
+		if n == 0 {
+			return strings.Repeat("?", width)
+		}
+		return fmt.Sprintf("%0*d", width, n)
+	}
+	return format(d.Year, 4) + "." + format(d.Month, 2) + "." + format(d.Day, 2)
+}
+
+// TimeControlPeriod is a single period of the time control (e.g. "40/7200" or "300+3").
+type TimeControlPeriod struct {
+	// Moves is the number of moves to be made in the period. 0 means the rest of the game.
+	Moves     int
+	Seconds   int
+	Increment int
+	// Sandclock is true if the time is measured by a sandclock (e.g. "*180").
+	Sandclock bool
+}
+
+func (p TimeControlPeriod) String() string {
+	if p.Sandclock {
+		return fmt.Sprintf("*%d", p.Seconds)
+	}
+	s := strconv.Itoa(p.Seconds)
+	if p.Moves > 0 {
+		s = fmt.Sprintf("%d/%s", p.Moves, s)
+	}
+	if p.Increment > 0 {
+		s = fmt.Sprintf("%s+%d", s, p.Increment)
+	}
+	return s
+}
+
+// TimeControl is the parsed value of the TimeControl tag.
+type TimeControl struct {
+	// Unknown is true for "?" (or missing tag)
+	Unknown bool
+	// None is true for "-" (no time control)
+	None    bool
+	Periods []TimeControlPeriod
+}
+
+// ParseTimeControl parses the value of the TimeControl tag: "?", "-" or a list of periods separated by colons
+// (e.g. "40/7200:3600", "300+3" or "*180").
+func ParseTimeControl(s string) (TimeControl, error) {
+	switch s {
+	case "", "?":
+		return TimeControl{Unknown: true}, nil
+	case "-":
+		return TimeControl{None: true}, nil
+	}
+
+	tc := TimeControl{}
+	for _, field := range strings.Split(s, ":") {
+		p := TimeControlPeriod{}
+		if strings.HasPrefix(field, "*") {
+			field = field[1:]
+			p.Sandclock = true
+		} else {
+			if moves, rest, ok := cut(field, "/"); ok {
+				if !isDigits(moves) {
+					return TimeControl{}, fmt.Errorf("invalid number of moves in %q", field)
+				}
+				p.Moves, _ = strconv.Atoi(moves)
+				field = rest
+			}
+			if secs, inc, ok := cut(field, "+"); ok {
+				if !isDigits(inc) {
+					return TimeControl{}, fmt.Errorf("invalid increment in %q", field)
+				}
+				p.Increment, _ = strconv.Atoi(inc)
+				field = secs
+			}
+		}
+		if !isDigits(field) {
+			return TimeControl{}, fmt.Errorf("invalid number of seconds in %q", field)
+		}
+		p.Seconds, _ = strconv.Atoi(field)
+		tc.Periods = append(tc.Periods, p)
+	}
+	return tc, nil
+}
+
+func (tc TimeControl) String() string {
+	if tc.None {
+		return "-"
+	}
+	if tc.Unknown || len(tc.Periods) == 0 {
+		return "?"
+	}
+	ss := make([]string, 0, len(tc.Periods))
+	for _, p := range tc.Periods {
+		ss = append(ss, p.String())
+	}
+	return strings.Join(ss, ":")
+}
+
+// cut is strings.Cut, which is not available in go 1.17.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
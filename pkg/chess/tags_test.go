@@ -0,0 +1,114 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestParseDate(t *testing.T) {
+	tcs := []struct {
+		s       string
+		want    Date
+		wantErr bool
+	}{
+		{s: "2023.05.17", want: Date{2023, 5, 17}},
+		{s: "1992.??.??", want: Date{Year: 1992}},
+		{s: "????.??.??", want: Date{}},
+		{s: "2023.13.01", wantErr: true},
+		{s: "2023.1.01", wantErr: true},
+		{s: "2023-05-17", wantErr: true},
+		{s: "20?3.05.17", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.s, func(tt *testing.T) {
+			got, err := ParseDate(tc.s)
+			if tc.wantErr != (err != nil) {
+				tt.Fatalf("want error: %t, got error: %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got != tc.want {
+				tt.Errorf("want %#v, got %#v", tc.want, got)
+			}
+			if got.String() != tc.s {
+				tt.Errorf("String(): want %q, got %q", tc.s, got.String())
+			}
+		})
+	}
+}
+
+func TestParseTimeControl(t *testing.T) {
+	tcs := []struct {
+		s       string
+		want    TimeControl
+		wantErr bool
+	}{
+		{s: "?", want: TimeControl{Unknown: true}},
+		{s: "-", want: TimeControl{None: true}},
+		{s: "300", want: TimeControl{Periods: []TimeControlPeriod{{Seconds: 300}}}},
+		{s: "300+3", want: TimeControl{Periods: []TimeControlPeriod{{Seconds: 300, Increment: 3}}}},
+		{s: "*180", want: TimeControl{Periods: []TimeControlPeriod{{Seconds: 180, Sandclock: true}}}},
+		{s: "40/7200:3600", want: TimeControl{Periods: []TimeControlPeriod{{Moves: 40, Seconds: 7200}, {Seconds: 3600}}}},
+		{s: "40/5400+30:1800+30", want: TimeControl{Periods: []TimeControlPeriod{
+			{Moves: 40, Seconds: 5400, Increment: 30}, {Seconds: 1800, Increment: 30},
+		}}},
+		{s: "5 min", wantErr: true},
+		{s: "40/", wantErr: true},
+		{s: "300+", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.s, func(tt *testing.T) {
+			got, err := ParseTimeControl(tc.s)
+			if tc.wantErr != (err != nil) {
+				tt.Fatalf("want error: %t, got error: %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.Unknown != tc.want.Unknown || got.None != tc.want.None || len(got.Periods) != len(tc.want.Periods) {
+				tt.Fatalf("want %#v, got %#v", tc.want, got)
+			}
+			for i := range got.Periods {
+				if got.Periods[i] != tc.want.Periods[i] {
+					tt.Errorf("period [%d]: want %#v, got %#v", i, tc.want.Periods[i], got.Periods[i])
+				}
+			}
+			if got.String() != tc.s {
+				tt.Errorf("String(): want %q, got %q", tc.s, got.String())
+			}
+		})
+	}
+}
+
+func TestTagsValidate(t *testing.T) {
+	tags := Tags{
+		{"Event", "Casual game"},
+		{"Date", "2023.??.??"},
+		{"Round", "1.2"},
+		{"Result", "1-0"},
+		{"WhiteElo", "2850"},
+		{"BlackElo", "strong"},
+		{"ECO", "F00"},
+		{"TimeControl", "300+3"},
+	}
+	if got := tags.WhiteElo(); got != 2850 {
+		t.Errorf("WhiteElo: want %d, got %d", 2850, got)
+	}
+	if got := tags.BlackElo(); got != 0 {
+		t.Errorf("BlackElo: want %d, got %d", 0, got)
+	}
+	if got := tags.ECO(); got != "" {
+		t.Errorf("ECO: want %q, got %q", "", got)
+	}
+
+	errs := tags.Validate()
+	want := []string{"BlackElo", "ECO"}
+	if len(errs) != len(want) {
+		t.Fatalf("want %d errors, got %v", len(want), errs)
+	}
+	for i := range want {
+		if errs[i].Name != want[i] {
+			t.Errorf("at [%d]: want error for %q, got %v", i, want[i], errs[i])
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Transform is a symmetry of the board that turns a position into an equivalent one.
+// Unlike drawing from black's perspective, a transform changes the position itself.
+type Transform int
+
+const (
+	// Identity leaves the position as it is.
+	Identity Transform = iota
+	// Mirror swaps the queenside and the kingside (a-file becomes h-file). The castling rights are lost,
+	// because the kings are not on their original squares anymore.
+	Mirror
+	// Flip swaps the colors of the pieces and turns the board upside down (1st rank becomes 8th rank),
+	// so that black plays white's game. The side to move and the castling rights are swapped too.
+	Flip
+	// Rotate is Mirror and Flip together.
+	Rotate
+)
+
+var transformNames = map[Transform]string{
+	Identity: "identity",
+	Mirror:   "mirror",
+	Flip:     "flip",
+	Rotate:   "rotate",
+}
+
+func (t Transform) String() string {
+	return transformNames[t]
+}
+
+// ParseTransform returns the transform by its name ("identity", "mirror", "flip" or "rotate").
+func ParseTransform(s string) (Transform, error) {
+	for t, name := range transformNames {
+		if name == s {
+			return t, nil
+		}
+	}
+	return Identity, fmt.Errorf("unknown transform %q", s)
+}
+
+// ErrTransformCastling is returned if a castling move is mirrored.
+var ErrTransformCastling = errors.New("castling cannot be mirrored")
+
+func (t Transform) mirrors() bool {
+	return t == Mirror || t == Rotate
+}
+
+func (t Transform) flips() bool {
+	return t == Flip || t == Rotate
+}
+
+// SwapsColors reports whether the transform swaps the colors of the pieces (Flip and Rotate).
+func (t Transform) SwapsColors() bool {
+	return t.flips()
+}
+
+// Square returns the square that sq becomes after the transform.
+func (t Transform) Square(sq Square) Square {
+	if t.mirrors() {
+		sq.file = 7 - sq.file
+	}
+	if t.flips() {
+		sq.rank = 7 - sq.rank
+	}
+	return sq
+}
+
+// Piece returns the piece that p becomes after the transform.
+func (t Transform) Piece(p Piece) Piece {
+	if t.flips() && p.Kind != None {
+		p.Color = 1 - p.Color
+	}
+	return p
+}
+
+// Position returns the transformed position.
+func (t Transform) Position(pos Position) Position {
+	var res Position
+	for file := range pos {
+		for rank := range pos[file] {
+			sq := t.Square(Square{file: file, rank: rank})
+			res[sq.file][sq.rank] = t.Piece(pos[file][rank])
+		}
+	}
+	return res
+}
+
+// State returns the transformed state. The move counters are kept.
+func (t Transform) State(st State) State {
+	res := st
+	res.Position = t.Position(st.Position)
+	if st.HasEnPassant {
+		res.EnPassant = t.Square(st.EnPassant)
+	}
+	if t.flips() {
+		res.ToMove = 1 - st.ToMove
+		// white and black rights are swapped
+		res.Castling = (st.Castling&(WhiteKingside|WhiteQueenside))<<2 | (st.Castling&(BlackKingside|BlackQueenside))>>2
+	}
+	if t.mirrors() {
+		res.Castling = NoCastling
+	}
+	return res
+}
+
+// Move returns the transformed move. Castling moves cannot be mirrored.
+func (t Transform) Move(mov Move) (Move, error) {
+	if mov.Castle && t.mirrors() {
+		return mov, ErrTransformCastling
+	}
+	mov.From, mov.To = t.Square(mov.From), t.Square(mov.To)
+	mov.Promotion = t.Piece(mov.Promotion)
+	return mov, nil
+}
+
+// Moves returns the transformed moves of a game. Castling moves cannot be mirrored.
+func (t Transform) Moves(movs []Move) ([]Move, error) {
+	res := make([]Move, len(movs))
+	for i, mov := range movs {
+		var err error
+		if res[i], err = t.Move(mov); err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	return res, nil
+}
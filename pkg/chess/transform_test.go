@@ -0,0 +1,77 @@
+package chess
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTransformState(t *testing.T) {
+	tcs := []struct {
+		name string
+		fen  string
+		t    Transform
+		want string
+	}{
+		{"identity", "4k3/8/8/8/8/8/8/R3K3 w Q - 0 1", Identity, "4k3/8/8/8/8/8/8/R3K3 w Q - 0 1"},
+		{"mirror", "4k3/8/8/8/8/8/8/R3K3 w Q - 0 1", Mirror, "3k4/8/8/8/8/8/8/3K3R w - - 0 1"},
+		{"flip", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 5", Flip, "4k3/8/8/8/3Pp3/8/8/4K3 b - d3 0 5"},
+		{"flip castling", "r3k3/8/8/8/8/8/8/4K2R b Kq - 0 1", Flip, "4k2r/8/8/8/8/8/8/R3K3 w Qk - 0 1"},
+		{"rotate", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", Rotate, "3k4/8/8/8/3pP3/8/8/3K4 b - e3 0 1"},
+		{"start", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", Flip, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if got := tc.t.State(st).FEN(); got != tc.want {
+				tt.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTransformMoves(t *testing.T) {
+	res, err := ParsePGN(strings.NewReader("1. e4 d5 2. exd5 Qxd5 3. Nf3 Bg4 4. Be2 Nc6 5. O-O O-O-O 6. d4 e5 7. dxe5 Qxd1 8. Rxd1 Rxd1+ 9. Bxd1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range []Transform{Flip, Mirror, Rotate} {
+		t.Run(tr.String(), func(tt *testing.T) {
+			st := tr.State(res.StartState)
+			movs, err := tr.Moves(res.Moves)
+			if tr != Flip {
+				// the game contains castling
+				if !errors.Is(err, ErrTransformCastling) {
+					tt.Fatalf("want ErrTransformCastling, got %v", err)
+				}
+				movs, err = tr.Moves(res.Moves[:8])
+			}
+			if err != nil {
+				tt.Fatal(err)
+			}
+
+			orig := res.StartState
+			for i, mov := range movs {
+				if !st.IsLegal(mov) {
+					tt.Fatalf("move %d (%s) is illegal in %s", i+1, mov, st.FEN())
+				}
+				st, orig = st.Apply(mov), orig.Apply(res.Moves[i])
+				if want := tr.State(orig); st.Key() != want.Key() {
+					tt.Fatalf("after move %d: want %s, got %s", i+1, want.FEN(), st.FEN())
+				}
+			}
+		})
+	}
+}
+
+func TestTransformSwapsColors(t *testing.T) {
+	want := map[Transform]bool{Identity: false, Mirror: false, Flip: true, Rotate: true}
+	for tr, w := range want {
+		if got := tr.SwapsColors(); got != w {
+			t.Errorf("%s: want %v, got %v", tr, w, got)
+		}
+	}
+}
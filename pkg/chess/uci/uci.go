@@ -0,0 +1,533 @@
+// Package uci drives a chess engine that supports Universal Chess Interface over its stdin and stdout.
+package uci
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+var (
+	// ErrExited is returned if the engine process has closed its output.
+	ErrExited = errors.New("engine exited")
+	// ErrNoBestMove is returned by Go if the engine has not found any move (e.g. in a checkmate position).
+	ErrNoBestMove = errors.New("engine returned no best move")
+)
+
+// stopTimeout is how long the engine is given to respond to "stop" and "quit" commands.
+const stopTimeout = time.Second
+
+// Engine is a running UCI engine. It is not safe for concurrent use.
+type Engine struct {
+	// Name and Author are reported by the engine during the handshake.
+	Name   string
+	Author string
+	// Options lists the names of the options supported by the engine.
+	Options []string
+
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+	// done is closed by Close, the output is not sent to lines after that
+	done chan struct{}
+	// err is set before lines is closed
+	err error
+	// state is the position set by the last Position call
+	state chess.State
+}
+
+// Start runs the engine binary and performs the handshake.
+func Start(ctx context.Context, path string, args ...string) (*Engine, error) {
+	return Run(ctx, exec.Command(path, args...))
+}
+
+// Run starts cmd and performs the handshake. Stdin and Stdout of cmd must not be set.
+// The engine process is not bound to ctx, it runs until Close is called.
+func Run(ctx context.Context, cmd *exec.Cmd) (*Engine, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		cmd:   cmd,
+		stdin: stdin,
+		lines: make(chan string, 64),
+		done:  make(chan struct{}),
+	}
+	go e.readLines(stdout)
+
+	if err := e.handshake(ctx); err != nil {
+		close(e.done)
+		e.kill()
+		return nil, fmt.Errorf("handshake: %w", err)
+	}
+	return e, nil
+}
+
+func (e *Engine) readLines(r io.Reader) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		select {
+		case e.lines <- sc.Text():
+		case <-e.done:
+			// nobody reads the lines after Close, they are dropped until the engine exits
+		}
+	}
+	e.err = sc.Err()
+	if e.err == nil {
+		e.err = ErrExited
+	}
+	close(e.lines)
+}
+
+func (e *Engine) send(format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(e.stdin, format+"\n", a...)
+	return err
+}
+
+// readLine returns the next line of engine output split into tokens. Empty lines are skipped.
+func (e *Engine) readLine(ctx context.Context) ([]string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case line, ok := <-e.lines:
+			if !ok {
+				return nil, e.err
+			}
+			if tokens := strings.Fields(line); len(tokens) > 0 {
+				return tokens, nil
+			}
+		}
+	}
+}
+
+func (e *Engine) handshake(ctx context.Context) error {
+	if err := e.send("uci"); err != nil {
+		return err
+	}
+	for {
+		tokens, err := e.readLine(ctx)
+		if err != nil {
+			return err
+		}
+		switch tokens[0] {
+		case "uciok":
+			return e.IsReady(ctx)
+		case "id":
+			if len(tokens) < 3 {
+				continue
+			}
+			value := strings.Join(tokens[2:], " ")
+			if tokens[1] == "name" {
+				e.Name = value
+			} else if tokens[1] == "author" {
+				e.Author = value
+			}
+		case "option":
+			if len(tokens) > 2 && tokens[1] == "name" {
+				e.Options = append(e.Options, optionName(tokens[2:]))
+			}
+		}
+	}
+}
+
+// optionName extracts the name from the tokens of "option name <name> type <type> ..." line.
+func optionName(tokens []string) string {
+	for i, tok := range tokens {
+		if tok == "type" {
+			return strings.Join(tokens[:i], " ")
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// IsReady waits until the engine is ready to accept new commands.
+func (e *Engine) IsReady(ctx context.Context) error {
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	for {
+		tokens, err := e.readLine(ctx)
+		if err != nil {
+			return err
+		}
+		if tokens[0] == "readyok" {
+			return nil
+		}
+	}
+}
+
+// SetOption sets the value of an engine option (e.g. "Hash" or "MultiPV").
+func (e *Engine) SetOption(ctx context.Context, name string, value string) error {
+	if err := e.send("setoption name %s value %s", name, value); err != nil {
+		return err
+	}
+	return e.IsReady(ctx)
+}
+
+// NewGame tells the engine that the next position is from a different game.
+func (e *Engine) NewGame(ctx context.Context) error {
+	if err := e.send("ucinewgame"); err != nil {
+		return err
+	}
+	return e.IsReady(ctx)
+}
+
+// Position sets the position to analyze: start state followed by the moves.
+func (e *Engine) Position(start chess.State, movs []chess.Move) error {
+	bldr := strings.Builder{}
+	bldr.WriteString("position ")
+	if start == chess.StartingState() {
+		bldr.WriteString("startpos")
+	} else {
+		bldr.WriteString("fen " + start.FEN())
+	}
+	if len(movs) > 0 {
+		bldr.WriteString(" moves")
+		for _, mov := range movs {
+			bldr.WriteString(" " + FormatMove(mov))
+		}
+	}
+	if err := e.send("%s", bldr.String()); err != nil {
+		return err
+	}
+	e.state = start
+	for _, mov := range movs {
+		e.state = e.state.Apply(mov)
+	}
+	return nil
+}
+
+// Limits restrict the search started with Go. Zero values mean no limit.
+// If no limits are set, the search is infinite and must be ended by cancelling the context.
+type Limits struct {
+	Depth    int
+	Nodes    int
+	MoveTime time.Duration
+}
+
+// Go starts the search in the position set by the last Position call and waits for its end.
+// If ctx is done before the engine returns the best move, the search is stopped and
+// the best move found so far is returned along with ctx.Err().
+func (e *Engine) Go(ctx context.Context, limits Limits) (Result, error) {
+	st := e.state
+	bldr := strings.Builder{}
+	bldr.WriteString("go")
+	if limits.Depth > 0 {
+		fmt.Fprintf(&bldr, " depth %d", limits.Depth)
+	}
+	if limits.Nodes > 0 {
+		fmt.Fprintf(&bldr, " nodes %d", limits.Nodes)
+	}
+	if limits.MoveTime > 0 {
+		fmt.Fprintf(&bldr, " movetime %d", limits.MoveTime.Milliseconds())
+	}
+	if limits == (Limits{}) {
+		bldr.WriteString(" infinite")
+	}
+	if err := e.send("%s", bldr.String()); err != nil {
+		return Result{}, err
+	}
+
+	res := Result{}
+	var ctxErr error
+	lineCtx := ctx
+	for {
+		tokens, err := e.readLine(lineCtx)
+		if err != nil && ctxErr == nil && errors.Is(err, ctx.Err()) {
+			// stop the search and give the engine some time to report the best move
+			ctxErr = err
+			if err := e.send("stop"); err != nil {
+				return res, ctxErr
+			}
+			var cancel context.CancelFunc
+			lineCtx, cancel = context.WithTimeout(context.Background(), stopTimeout)
+			defer cancel()
+			continue
+		}
+		if err != nil {
+			if ctxErr != nil {
+				return res, ctxErr
+			}
+			return res, err
+		}
+
+		switch tokens[0] {
+		case "info":
+			// malformed info lines are skipped, only the lines with the score of the main line are kept
+			info, err := parseInfo(st, tokens[1:])
+			if err == nil && info.HasScore && info.MultiPV <= 1 {
+				res.Info = info
+			}
+		case "bestmove":
+			if len(tokens) < 2 || tokens[1] == "(none)" || tokens[1] == "0000" {
+				if ctxErr != nil {
+					return res, ctxErr
+				}
+				return res, ErrNoBestMove
+			}
+			res.BestMove, err = ParseMove(st, tokens[1])
+			if err != nil {
+				return res, fmt.Errorf("parse best move: %w", err)
+			}
+			if len(tokens) >= 4 && tokens[2] == "ponder" {
+				if ponder, err := ParseMove(st.Apply(res.BestMove), tokens[3]); err == nil {
+					res.Ponder = ponder
+					res.HasPonder = true
+				}
+			}
+			return res, ctxErr
+		}
+	}
+}
+
+// Close asks the engine to quit and kills it if it does not exit in time.
+func (e *Engine) Close() error {
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
+	}
+	_ = e.send("quit")
+	e.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(stopTimeout):
+		e.kill()
+		return <-done
+	}
+}
+
+func (e *Engine) kill() {
+	if e.cmd.Process != nil {
+		_ = e.cmd.Process.Kill()
+	}
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	BestMove chess.Move
+	// Ponder is the expected reply to BestMove (meaningful only if HasPonder is true).
+	Ponder    chess.Move
+	HasPonder bool
+	// Info is the last reported search info with a score (usually along with the principal variation).
+	Info Info
+}
+
+// Score is an evaluation of the position from the point of view of the side to move.
+type Score struct {
+	// Centipawns is the evaluation in hundredths of a pawn (if IsMate is false).
+	Centipawns int
+	// Mate is the number of moves to checkmate (negative if the side to move is getting mated).
+	// Mate is 0 if the side to move is already checkmated.
+	Mate   int
+	IsMate bool
+	// LowerBound and UpperBound are set if the score is just a bound.
+	LowerBound bool
+	UpperBound bool
+}
+
+func (s Score) String() string {
+	if s.IsMate {
+		return fmt.Sprintf("#%d", s.Mate)
+	}
+	return fmt.Sprintf("%+.2f", float64(s.Centipawns)/100)
+}
+
+// Eval converts the score to white's point of view given the side to move in the analyzed position.
+// A checkmated position (mate in 0) has no evaluation, so false is returned for it.
+func (s Score) Eval(toMove chess.PieceColor) (chess.Eval, bool) {
+	if s.IsMate && s.Mate == 0 {
+		return chess.Eval{}, false
+	}
+	e := chess.Eval{Centipawns: s.Centipawns}
+	if s.IsMate {
+		e = chess.Eval{Mate: s.Mate}
+	}
+	if toMove == chess.Black {
+		e.Centipawns, e.Mate = -e.Centipawns, -e.Mate
+	}
+	return e, true
+}
+
+// Info is the data from "info" line of engine output.
+type Info struct {
+	Depth    int
+	SelDepth int
+	MultiPV  int
+	Score    Score
+	HasScore bool
+	Nodes    int
+	NPS      int
+	Time     time.Duration
+	// PV is the principal variation starting from the analyzed position.
+	PV []chess.Move
+}
+
+func parseInfo(st chess.State, tokens []string) (Info, error) {
+	info := Info{}
+	intValue := func(i int) (int, error) {
+		if i+1 >= len(tokens) {
+			return 0, fmt.Errorf("missing value of %q", tokens[i])
+		}
+		return strconv.Atoi(tokens[i+1])
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		var (
+			dst *int
+			err error
+		)
+		switch tokens[i] {
+		case "depth":
+			dst = &info.Depth
+		case "seldepth":
+			dst = &info.SelDepth
+		case "multipv":
+			dst = &info.MultiPV
+		case "nodes":
+			dst = &info.Nodes
+		case "nps":
+			dst = &info.NPS
+		case "time":
+			var ms int
+			if ms, err = intValue(i); err != nil {
+				return info, err
+			}
+			info.Time = time.Duration(ms) * time.Millisecond
+			i++
+		case "score":
+			info.HasScore = true
+			if i, err = parseScore(tokens, i, &info.Score); err != nil {
+				return info, err
+			}
+		case "pv":
+			pst := st
+			for _, tok := range tokens[i+1:] {
+				mov, err := ParseMove(pst, tok)
+				if err != nil {
+					return info, err
+				}
+				info.PV = append(info.PV, mov)
+				pst = pst.Apply(mov)
+			}
+			i = len(tokens)
+		case "string":
+			// the rest of the line is free text
+			i = len(tokens)
+		}
+		if dst != nil {
+			if *dst, err = intValue(i); err != nil {
+				return info, err
+			}
+			i++
+		}
+	}
+	return info, nil
+}
+
+// parseScore parses the score fields following tokens[i] ("score") and returns the index of the last parsed token.
+func parseScore(tokens []string, i int, score *Score) (int, error) {
+	for i+1 < len(tokens) {
+		switch tokens[i+1] {
+		case "cp", "mate":
+			if i+2 >= len(tokens) {
+				return i, fmt.Errorf("missing value of %q", tokens[i+1])
+			}
+			v, err := strconv.Atoi(tokens[i+2])
+			if err != nil {
+				return i, err
+			}
+			if tokens[i+1] == "cp" {
+				score.Centipawns = v
+			} else {
+				score.Mate = v
+				score.IsMate = true
+			}
+			i += 2
+		case "lowerbound":
+			score.LowerBound = true
+			i++
+		case "upperbound":
+			score.UpperBound = true
+			i++
+		default:
+			return i, nil
+		}
+	}
+	return i, nil
+}
+
+var (
+	promotionLetters = map[chess.PieceKind]byte{chess.Knight: 'n', chess.Bishop: 'b', chess.Rook: 'r', chess.Queen: 'q'}
+	promotionKinds   = map[byte]chess.PieceKind{'n': chess.Knight, 'b': chess.Bishop, 'r': chess.Rook, 'q': chess.Queen}
+)
+
+// FormatMove returns the move in UCI notation (e.g. "e2e4", "e1g1" or "e7e8q").
+func FormatMove(mov chess.Move) string {
+	s := mov.From.String() + mov.To.String()
+	if mov.Promotion.Kind != chess.None {
+		s += string(promotionLetters[mov.Promotion.Kind])
+	}
+	return s
+}
+
+// ParseMove parses a move in UCI notation made in the state.
+// Castling and en passant are detected from the position. The move is not checked for legality.
+func ParseMove(st chess.State, s string) (chess.Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return chess.Move{}, fmt.Errorf("invalid move %q", s)
+	}
+	from, err := chess.NewSquareFromString(s[0:2])
+	if err != nil {
+		return chess.Move{}, fmt.Errorf("invalid move %q", s)
+	}
+	to, err := chess.NewSquareFromString(s[2:4])
+	if err != nil {
+		return chess.Move{}, fmt.Errorf("invalid move %q", s)
+	}
+	p := st.Position.Get(from)
+	if p.Kind == chess.None || p.Color != st.ToMove {
+		return chess.Move{}, fmt.Errorf("invalid move %q: no piece to move", s)
+	}
+
+	mov := chess.Move{From: from, To: to}
+	if len(s) == 5 {
+		kind, ok := promotionKinds[s[4]]
+		if !ok || p.Kind != chess.Pawn {
+			return chess.Move{}, fmt.Errorf("invalid move %q: bad promotion", s)
+		}
+		mov.Promotion = chess.Piece{Kind: kind, Color: p.Color}
+	}
+	df := to.File() - from.File()
+	if p.Kind == chess.King && to.Rank() == from.Rank() && (df == 2 || df == -2) {
+		mov.Castle = true
+	}
+	if p.Kind == chess.Pawn && df != 0 && st.Position.Get(to).Kind == chess.None {
+		mov.EnPassant = true
+	}
+	return mov, nil
+}
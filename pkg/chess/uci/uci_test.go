@@ -0,0 +1,332 @@
+package uci
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// fakeEngineEnv selects the behaviour of the test binary when it is run as a fake engine.
+const fakeEngineEnv = "UCI_FAKE_ENGINE"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakeEngineEnv); mode != "" {
+		fakeEngine(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeEngine is a minimal UCI engine: it plays 1. e4 from the starting position and 1... e5 otherwise.
+// In "hang" mode it never completes the handshake. In "chatty" mode it floods the output after every position.
+func fakeEngine(mode string) {
+	sc := bufio.NewScanner(os.Stdin)
+	position := ""
+	stop := make(chan struct{}, 1)
+	for sc.Scan() {
+		tokens := strings.Fields(sc.Text())
+		if len(tokens) == 0 {
+			continue
+		}
+		switch tokens[0] {
+		case "uci":
+			if mode == "hang" {
+				continue
+			}
+			fmt.Println("id name Fake Engine 1.0")
+			fmt.Println("id author Nobody")
+			fmt.Println("option name Hash type spin default 16 min 1 max 1024")
+			fmt.Println("option name Skill Level type spin default 20 min 0 max 20")
+			fmt.Println("uciok")
+		case "isready":
+			fmt.Println("readyok")
+		case "position":
+			position = strings.Join(tokens[1:], " ")
+			if mode == "chatty" {
+				for i := 0; i < 200; i++ {
+					fmt.Printf("info string line %d\n", i)
+				}
+			}
+		case "go":
+			move, reply := "e2e4", "e7e5"
+			if position != "startpos" {
+				move, reply = "e7e5", "g1f3"
+			}
+			if tokens[len(tokens)-1] == "infinite" {
+				fmt.Printf("info depth 1 score cp 10 pv %s\n", move)
+				go func() {
+					<-stop
+					fmt.Printf("bestmove %s\n", move)
+				}()
+				continue
+			}
+			fmt.Println("info string thinking")
+			fmt.Printf("info depth 1 seldepth 1 multipv 1 score cp 30 nodes 20 nps 2000 time 10 pv %s\n", move)
+			fmt.Printf("info depth 2 seldepth 3 multipv 1 score cp 25 lowerbound nodes 400 nps 4000 time 100 pv %s %s\n", move, reply)
+			// secondary lines (and malformed ones, since a2a3 is illegal for black) must be ignored
+			fmt.Println("info depth 2 multipv 2 score cp -50 pv a2a3")
+			fmt.Printf("bestmove %s ponder %s\n", move, reply)
+		case "stop":
+			stop <- struct{}{}
+		case "quit":
+			return
+		}
+	}
+}
+
+func startFake(ctx context.Context, mode string) (*Engine, error) {
+	cmd := exec.Command(os.Args[0])
+	// the race detector makes the binary sleep for a second at exit by default, which Close does not wait for
+	cmd.Env = append(os.Environ(), fakeEngineEnv+"="+mode, "GORACE=atexit_sleep_ms=0")
+	return Run(ctx, cmd)
+}
+
+func TestEngine(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	e, err := startFake(ctx, "normal")
+	if err != nil {
+		t.Fatalf("start: %s", err)
+	}
+	defer e.Close()
+
+	if e.Name != "Fake Engine 1.0" || e.Author != "Nobody" {
+		t.Errorf("id: got %q by %q", e.Name, e.Author)
+	}
+	if want := []string{"Hash", "Skill Level"}; strings.Join(e.Options, ",") != strings.Join(want, ",") {
+		t.Errorf("options: want %q, got %q", want, e.Options)
+	}
+	if err := e.SetOption(ctx, "Hash", "32"); err != nil {
+		t.Fatalf("set option: %s", err)
+	}
+	if err := e.NewGame(ctx); err != nil {
+		t.Fatalf("new game: %s", err)
+	}
+
+	t.Run("startpos", func(tt *testing.T) {
+		start := chess.StartingState()
+		if err := e.Position(start, nil); err != nil {
+			tt.Fatalf("position: %s", err)
+		}
+		res, err := e.Go(ctx, Limits{Depth: 2})
+		if err != nil {
+			tt.Fatalf("go: %s", err)
+		}
+		e4 := getMove("e2e4")
+		e5 := chess.Move{From: chess.MustNewSquareFromString("e7"), To: chess.MustNewSquareFromString("e5")}
+		if res.BestMove != e4 {
+			tt.Errorf("best move: want %s, got %s", e4, res.BestMove)
+		}
+		if !res.HasPonder || res.Ponder != e5 {
+			tt.Errorf("ponder: want %s, got %s (%t)", e5, res.Ponder, res.HasPonder)
+		}
+		wantInfo := Info{
+			Depth: 2, SelDepth: 3, MultiPV: 1,
+			Score: Score{Centipawns: 25, LowerBound: true}, HasScore: true,
+			Nodes: 400, NPS: 4000, Time: 100 * time.Millisecond,
+			PV: []chess.Move{e4, e5},
+		}
+		if fmt.Sprint(res.Info) != fmt.Sprint(wantInfo) {
+			tt.Errorf("info: want %+v, got %+v", wantInfo, res.Info)
+		}
+	})
+
+	t.Run("moves", func(tt *testing.T) {
+		start := chess.StartingState()
+		e4 := getMove("e2e4")
+		if err := e.Position(start, []chess.Move{e4}); err != nil {
+			tt.Fatalf("position: %s", err)
+		}
+		res, err := e.Go(ctx, Limits{MoveTime: 10 * time.Millisecond})
+		if err != nil {
+			tt.Fatalf("go: %s", err)
+		}
+		if want := "e7e5"; FormatMove(res.BestMove) != want {
+			tt.Errorf("best move: want %s, got %s", want, FormatMove(res.BestMove))
+		}
+	})
+
+	t.Run("cancel", func(tt *testing.T) {
+		start := chess.StartingState()
+		if err := e.Position(start, nil); err != nil {
+			tt.Fatalf("position: %s", err)
+		}
+		goCtx, goCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer goCancel()
+		res, err := e.Go(goCtx, Limits{})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			tt.Fatalf("want deadline exceeded, got %v", err)
+		}
+		if want := "e2e4"; FormatMove(res.BestMove) != want {
+			tt.Errorf("best move: want %s, got %s", want, FormatMove(res.BestMove))
+		}
+		// the engine must still be usable
+		if err := e.IsReady(ctx); err != nil {
+			tt.Errorf("is ready: %s", err)
+		}
+	})
+}
+
+func TestEngineCloseUnreadOutput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	before := runtime.NumGoroutine()
+	e, err := startFake(ctx, "chatty")
+	if err != nil {
+		t.Fatalf("start: %s", err)
+	}
+	// the output is never read, so it does not fit in the buffer of the lines
+	if err := e.Position(chess.StartingState(), nil); err != nil {
+		t.Fatalf("position: %s", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close: %s", err)
+	}
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("the output reader must stop after Close: want %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+	}
+}
+
+func TestEngineHandshakeTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := startFake(ctx, "hang")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want deadline exceeded, got %v", err)
+	}
+}
+
+func TestMoveNotation(t *testing.T) {
+	tcs := []struct {
+		name string
+		fen  string
+		move string
+		want chess.Move
+	}{
+		{
+			name: "simple",
+			fen:  "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			move: "g1f3",
+			want: getMove("g1f3"),
+		},
+		{
+			name: "castle",
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			move: "e8c8",
+			want: chess.Move{From: chess.MustNewSquareFromString("e8"), To: chess.MustNewSquareFromString("c8"), Castle: true},
+		},
+		{
+			name: "en passant",
+			fen:  "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+			move: "e5d6",
+			want: chess.Move{From: chess.MustNewSquareFromString("e5"), To: chess.MustNewSquareFromString("d6"), EnPassant: true},
+		},
+		{
+			name: "promotion",
+			fen:  "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1",
+			move: "b7b8n",
+			want: chess.Move{
+				From: chess.MustNewSquareFromString("b7"), To: chess.MustNewSquareFromString("b8"),
+				Promotion: chess.Piece{Kind: chess.Knight, Color: chess.White},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			st, err := chess.ParseFEN(strings.NewReader(tc.fen))
+			if err != nil {
+				tt.Fatalf("parse fen: %s", err)
+			}
+			mov, err := ParseMove(st, tc.move)
+			if err != nil {
+				tt.Fatalf("parse move: %s", err)
+			}
+			if mov != tc.want {
+				tt.Errorf("want %s, got %s", tc.want, mov)
+			}
+			if s := FormatMove(mov); s != tc.move {
+				tt.Errorf("format: want %q, got %q", tc.move, s)
+			}
+		})
+	}
+
+	for _, s := range []string{"", "e2", "e2e9", "e3e4", "e7e5", "e2e4k", "g1f3q"} {
+		if _, err := ParseMove(chess.StartingState(), s); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	info, err := parseInfo(chess.StartingState(), strings.Fields("depth 20 score mate -3 upperbound time 1500 string pv is e2e4"))
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+	want := Info{Depth: 20, Score: Score{Mate: -3, IsMate: true, UpperBound: true}, HasScore: true, Time: 1500 * time.Millisecond}
+	if fmt.Sprint(info) != fmt.Sprint(want) {
+		t.Errorf("want %+v, got %+v", want, info)
+	}
+	if s := want.Score.String(); s != "#-3" {
+		t.Errorf("score string: got %q", s)
+	}
+
+	// the side to move is checkmated: not an even position
+	info, err = parseInfo(chess.StartingState(), strings.Fields("depth 0 score mate 0"))
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+	if !info.HasScore || !info.Score.IsMate || info.Score.Mate != 0 {
+		t.Errorf("want mate in 0, got %+v", info.Score)
+	}
+	if s := info.Score.String(); s != "#0" {
+		t.Errorf("score string: want %q, got %q", "#0", s)
+	}
+	if _, ok := info.Score.Eval(chess.White); ok {
+		t.Errorf("checkmated position must have no evaluation")
+	}
+
+	if _, err := parseInfo(chess.StartingState(), strings.Fields("depth x")); err == nil {
+		t.Errorf("want error for bad depth")
+	}
+	if _, err := parseInfo(chess.StartingState(), strings.Fields("pv e2e4 e2e4")); err == nil {
+		t.Errorf("want error for bad pv")
+	}
+}
+
+func TestScoreEval(t *testing.T) {
+	tcs := []struct {
+		score  Score
+		toMove chess.PieceColor
+		want   chess.Eval
+		ok     bool
+	}{
+		{Score{Centipawns: 35}, chess.White, chess.Eval{Centipawns: 35}, true},
+		{Score{Centipawns: 35}, chess.Black, chess.Eval{Centipawns: -35}, true},
+		{Score{Mate: 2, IsMate: true}, chess.Black, chess.Eval{Mate: -2}, true},
+		{Score{Mate: -1, IsMate: true}, chess.Black, chess.Eval{Mate: 1}, true},
+		{Score{IsMate: true}, chess.White, chess.Eval{}, false},
+	}
+	for _, tc := range tcs {
+		got, ok := tc.score.Eval(tc.toMove)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s (%s to move): want %s (%t), got %s (%t)", tc.score, tc.toMove.Name(), tc.want, tc.ok, got, ok)
+		}
+	}
+}
+
+func getMove(s string) chess.Move {
+	return chess.Move{From: chess.MustNewSquareFromString(s[0:2]), To: chess.MustNewSquareFromString(s[2:4])}
+}
@@ -62,7 +62,7 @@ func positionEqual(a, b Position) bool {
 type pgnResult struct {
 	start string
 	movs  string
-	tags  map[string]string
+	tags  Tags
 }
 
 func getPgnResult(res pgnResult) PGNResult {
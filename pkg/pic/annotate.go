@@ -0,0 +1,232 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// AnnotationColors are the named colors of the annotations, translucent like the marks of lichess.
+var AnnotationColors = map[string]color.NRGBA{
+	"green":  {R: 0x15, G: 0x78, B: 0x1b, A: 0xc0},
+	"red":    {R: 0x88, G: 0x20, B: 0x20, A: 0xc0},
+	"blue":   {R: 0x00, G: 0x30, B: 0x88, A: 0xc0},
+	"yellow": {R: 0xe6, G: 0x8f, B: 0x00, A: 0xc0},
+}
+
+// DefaultAnnotationColor is the name of the color of annotations without a color.
+const DefaultAnnotationColor = "green"
+
+// ParseColor returns a named color (see AnnotationColors) or a color in hex notation ("#rrggbb" or "#rrggbbaa").
+func ParseColor(s string) (color.Color, error) {
+	if c, ok := AnnotationColors[s]; ok {
+		return c, nil
+	}
+	if !strings.HasPrefix(s, "#") || (len(s) != 7 && len(s) != 9) {
+		return nil, fmt.Errorf("unknown color %q", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unknown color %q", s)
+	}
+	if len(s) == 7 {
+		v = v<<8 | 0xff
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// Arrow is an arrow between the centers of two squares.
+type Arrow struct {
+	From, To chess.Square
+	Color    color.Color
+}
+
+// Mark is a circle around a square or a fill of a square.
+type Mark struct {
+	Square chess.Square
+	Color  color.Color
+}
+
+// Badge is a short text (e.g. "!?" or "1") in a colored circle at the top-right corner of a square.
+type Badge struct {
+	Square chess.Square
+	Text   string
+	Color  color.Color
+}
+
+// Annotations are the marks drawn on a position, e.g. for lesson diagrams.
+type Annotations struct {
+	Arrows  []Arrow
+	Circles []Mark
+	Fills   []Mark
+	Badges  []Badge
+}
+
+// Empty reports whether there are no annotations.
+func (a Annotations) Empty() bool {
+	return len(a.Arrows) == 0 && len(a.Circles) == 0 && len(a.Fills) == 0 && len(a.Badges) == 0
+}
+
+// splitAnnotation splits "<value>[:<color>]" and parses the color.
+func splitAnnotation(s string) (string, color.Color, error) {
+	name := DefaultAnnotationColor
+	if i := strings.LastIndexByte(s, ':'); i >= 0 {
+		s, name = s[:i], s[i+1:]
+	}
+	c, err := ParseColor(name)
+	return s, c, err
+}
+
+// ParseArrow parses an arrow in the form "<from><to>[:<color>]" (e.g. "e2e4" or "g1f3:red").
+func ParseArrow(s string) (Arrow, error) {
+	sqs, c, err := splitAnnotation(s)
+	if err != nil {
+		return Arrow{}, fmt.Errorf("invalid arrow %q: %w", s, err)
+	}
+	if len(sqs) != 4 {
+		return Arrow{}, fmt.Errorf("invalid arrow %q", s)
+	}
+	from, err := chess.NewSquareFromString(sqs[:2])
+	if err != nil {
+		return Arrow{}, fmt.Errorf("invalid arrow %q: %w", s, err)
+	}
+	to, err := chess.NewSquareFromString(sqs[2:])
+	if err != nil {
+		return Arrow{}, fmt.Errorf("invalid arrow %q: %w", s, err)
+	}
+	if from == to {
+		return Arrow{}, fmt.Errorf("invalid arrow %q: same squares", s)
+	}
+	return Arrow{From: from, To: to, Color: c}, nil
+}
+
+// ParseMark parses a circle or a fill in the form "<square>[:<color>]" (e.g. "d5" or "d5:red").
+func ParseMark(s string) (Mark, error) {
+	name, c, err := splitAnnotation(s)
+	if err != nil {
+		return Mark{}, fmt.Errorf("invalid mark %q: %w", s, err)
+	}
+	sq, err := chess.NewSquareFromString(name)
+	if err != nil {
+		return Mark{}, fmt.Errorf("invalid mark %q: %w", s, err)
+	}
+	return Mark{Square: sq, Color: c}, nil
+}
+
+// maxBadgeText is the maximum length of the text of a badge, longer texts do not fit in the circle.
+const maxBadgeText = 3
+
+// ParseBadge parses a badge in the form "<square>:<text>[:<color>]" (e.g. "e4:!!" or "c3:1:blue").
+func ParseBadge(s string) (Badge, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return Badge{}, fmt.Errorf("invalid badge %q: no text", s)
+	}
+	sq, err := chess.NewSquareFromString(s[:i])
+	if err != nil {
+		return Badge{}, fmt.Errorf("invalid badge %q: %w", s, err)
+	}
+	text, c := s[i+1:], color.Color(AnnotationColors[DefaultAnnotationColor])
+	if j := strings.IndexByte(text, ':'); j >= 0 {
+		if c, err = ParseColor(text[j+1:]); err != nil {
+			return Badge{}, fmt.Errorf("invalid badge %q: %w", s, err)
+		}
+		text = text[:j]
+	}
+	if text == "" || len([]rune(text)) > maxBadgeText {
+		return Badge{}, fmt.Errorf("invalid badge %q: text must have from 1 to %d characters", s, maxBadgeText)
+	}
+	return Badge{Square: sq, Text: text, Color: c}, nil
+}
+
+// Transform returns the annotations of the position transformed by t.
+func (a Annotations) Transform(t chess.Transform) Annotations {
+	res := Annotations{
+		Arrows:  make([]Arrow, len(a.Arrows)),
+		Circles: make([]Mark, len(a.Circles)),
+		Fills:   make([]Mark, len(a.Fills)),
+		Badges:  make([]Badge, len(a.Badges)),
+	}
+	for i, arrow := range a.Arrows {
+		arrow.From, arrow.To = t.Square(arrow.From), t.Square(arrow.To)
+		res.Arrows[i] = arrow
+	}
+	for i, m := range a.Circles {
+		m.Square = t.Square(m.Square)
+		res.Circles[i] = m
+	}
+	for i, m := range a.Fills {
+		m.Square = t.Square(m.Square)
+		res.Fills[i] = m
+	}
+	for i, b := range a.Badges {
+		b.Square = t.Square(b.Square)
+		res.Badges[i] = b
+	}
+	return res
+}
+
+// Layer returns a Layer that fills the squares of the annotations under the pieces.
+func (a Annotations) Layer(fromPerspective chess.PieceColor) Layer {
+	return func(dst draw.Image, board image.Rectangle) {
+		for _, m := range a.Fills {
+			FillSquare(dst, board, m.Square, fromPerspective, m.Color)
+		}
+	}
+}
+
+// Draw draws the circles, the arrows and the badges of the annotations over the board drawn
+// in the rectangle board (and over the pieces). The fills are drawn by Layer.
+func (a Annotations) Draw(dst draw.Image, board image.Rectangle, fromPerspective chess.PieceColor) {
+	for _, m := range a.Circles {
+		DrawCircle(dst, board, m.Square, fromPerspective, m.Color)
+	}
+	for _, arrow := range a.Arrows {
+		DrawArrow(dst, board, arrow.From, arrow.To, fromPerspective, arrow.Color)
+	}
+	for _, b := range a.Badges {
+		DrawBadge(dst, board, b.Square, b.Text, fromPerspective, b.Color)
+	}
+}
+
+// DrawCircle draws a ring along the edges of sq of the board drawn in the rectangle board.
+func DrawCircle(dst draw.Image, board image.Rectangle, sq chess.Square, fromPerspective chess.PieceColor, c color.Color) {
+	r := SquareRect(board, sq, fromPerspective)
+	ss := float64(r.Dx())
+	center := point{float64(r.Min.X+r.Max.X) / 2, float64(r.Min.Y+r.Max.Y) / 2}
+	width := ss / 14
+	fillShape(dst, shape{
+		circle(center, ss/2-width/2, false),
+		circle(center, ss/2-width*3/2, true),
+	}, c)
+}
+
+// DrawBadge draws text in a circle of c at the top-right corner of sq of the board drawn in the rectangle board.
+// The text is white or black, whichever is more readable on c.
+func DrawBadge(dst draw.Image, board image.Rectangle, sq chess.Square, text string, fromPerspective chess.PieceColor, c color.Color) {
+	r := SquareRect(board, sq, fromPerspective)
+	radius := float64(r.Dx()) / 5
+	center := point{float64(r.Max.X) - radius, float64(r.Min.Y) + radius}
+	fillShape(dst, shape{circle(center, radius, false)}, c)
+
+	DrawText(dst, image.Pt(int(center[0]), int(center[1])), text, badgeTextSize(radius, text), AnchorCenter, badgeTextColor(c))
+}
+
+// badgeTextSize returns the font size of the text in the badge of the radius, longer texts are smaller to fit in.
+func badgeTextSize(radius float64, text string) float64 {
+	return math.Min(radius*1.2, radius*2.8/float64(len([]rune(text))+1))
+}
+
+// badgeTextColor returns white or black, whichever is more readable on c.
+func badgeTextColor(c color.Color) color.Color {
+	if r, g, b, a := c.RGBA(); a > 0x8000 && (299*r+587*g+114*b)/a > 500 {
+		return color.Black
+	}
+	return color.White
+}
@@ -0,0 +1,109 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	sq := chess.MustNewSquareFromString
+	green, red := AnnotationColors["green"], AnnotationColors["red"]
+
+	t.Run("arrows", func(tt *testing.T) {
+		tcs := []struct {
+			s    string
+			want Arrow
+			ok   bool
+		}{
+			{"e2e4", Arrow{From: sq("e2"), To: sq("e4"), Color: green}, true},
+			{"g1f3:red", Arrow{From: sq("g1"), To: sq("f3"), Color: red}, true},
+			{"a1h8:#ff000080", Arrow{From: sq("a1"), To: sq("h8"), Color: color.NRGBA{R: 0xff, A: 0x80}}, true},
+			{"e2e2", Arrow{}, false},
+			{"e2e9", Arrow{}, false},
+			{"e2e4:pink", Arrow{}, false},
+		}
+		for _, tc := range tcs {
+			got, err := ParseArrow(tc.s)
+			if (err == nil) != tc.ok || got != tc.want {
+				tt.Errorf("%q: want %v (ok: %t), got %v, %v", tc.s, tc.want, tc.ok, got, err)
+			}
+		}
+	})
+	t.Run("marks", func(tt *testing.T) {
+		if got, err := ParseMark("d5:#102030"); err != nil || got != (Mark{Square: sq("d5"), Color: color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}}) {
+			tt.Errorf("got %v, %v", got, err)
+		}
+		if _, err := ParseMark("d5:#1020"); err == nil {
+			tt.Errorf("want error for a short hex color")
+		}
+	})
+	t.Run("badges", func(tt *testing.T) {
+		tcs := []struct {
+			s    string
+			want Badge
+			ok   bool
+		}{
+			{"e4:!!", Badge{Square: sq("e4"), Text: "!!", Color: green}, true},
+			{"c3:1:red", Badge{Square: sq("c3"), Text: "1", Color: red}, true},
+			{"c3", Badge{}, false},
+			{"c3:", Badge{}, false},
+			{"c3:long", Badge{}, false},
+		}
+		for _, tc := range tcs {
+			got, err := ParseBadge(tc.s)
+			if (err == nil) != tc.ok || got != tc.want {
+				tt.Errorf("%q: want %v (ok: %t), got %v, %v", tc.s, tc.want, tc.ok, got, err)
+			}
+		}
+	})
+}
+
+func TestAnnotationsTransform(t *testing.T) {
+	sq := chess.MustNewSquareFromString
+	a := Annotations{
+		Arrows: []Arrow{{From: sq("e2"), To: sq("e4")}},
+		Fills:  []Mark{{Square: sq("a1")}},
+		Badges: []Badge{{Square: sq("h8"), Text: "1"}},
+	}
+	got := a.Transform(chess.Rotate)
+	if got.Arrows[0].From != sq("d7") || got.Arrows[0].To != sq("d5") || got.Fills[0].Square != sq("h8") || got.Badges[0].Square != sq("a1") {
+		t.Errorf("got %+v", got)
+	}
+	if a.Arrows[0].From != sq("e2") {
+		t.Errorf("original annotations must not change")
+	}
+}
+
+func TestDrawAnnotations(t *testing.T) {
+	sq := chess.MustNewSquareFromString
+	c := color.RGBA{B: 0xff, A: 0xff}
+	a := Annotations{
+		Circles: []Mark{{Square: sq("d4"), Color: c}},
+		Fills:   []Mark{{Square: sq("a1"), Color: c}},
+		Badges:  []Badge{{Square: sq("h8"), Text: "1", Color: c}},
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, 320, 320))
+	a.Layer(chess.White)(dst, dst.Bounds())
+	a.Draw(dst, dst.Bounds(), chess.White)
+
+	// the squares are 40 pixels wide, d4 is at (120, 160)
+	tcs := []struct {
+		pt   image.Point
+		want bool
+	}{
+		{image.Pt(20, 300), true},  // a1 is filled
+		{image.Pt(122, 180), true}, // the ring of d4
+		{image.Pt(140, 180), false},
+		{image.Pt(121, 161), false}, // the corner of d4 is outside the ring
+		{image.Pt(312, 8), true},    // the badge of h8
+		{image.Pt(285, 35), false},
+	}
+	for _, tc := range tcs {
+		if got := dst.RGBAAt(tc.pt.X, tc.pt.Y).A != 0; got != tc.want {
+			t.Errorf("pixel %v: want drawn %t, got %t", tc.pt, tc.want, got)
+		}
+	}
+}
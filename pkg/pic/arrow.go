@@ -0,0 +1,92 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// HintColor is the color of the arrow of a suggested move.
+var HintColor = color.NRGBA{R: 0x15, G: 0x78, B: 0x1b, A: 0xc0}
+
+// SquareRect returns the rectangle of sq on the board drawn in the rectangle board.
+func SquareRect(board image.Rectangle, sq chess.Square, fromPerspective chess.PieceColor) image.Rectangle {
+	ss := board.Dx() / 8
+	x, y := sq.File(), 7-sq.Rank()
+	if fromPerspective == chess.Black {
+		x, y = 7-sq.File(), sq.Rank()
+	}
+	min := board.Min.Add(image.Pt(x*ss, y*ss))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(ss, ss))}
+}
+
+// FillSquare blends c over sq of the board drawn in the rectangle board.
+func FillSquare(dst draw.Image, board image.Rectangle, sq chess.Square, fromPerspective chess.PieceColor, c color.Color) {
+	draw.Draw(dst, SquareRect(board, sq, fromPerspective), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// DrawArrow draws an arrow between the centers of two squares of the board drawn in the rectangle board.
+// A knight jump is drawn as an L-shaped arrow with the long leg first.
+// The color c is blended over dst, so it may be translucent.
+func DrawArrow(dst draw.Image, board image.Rectangle, from, to chess.Square, fromPerspective chess.PieceColor, c color.Color) {
+	if from == to {
+		return
+	}
+	fillShape(dst, arrowShape(arrowPoints(board, from, to, fromPerspective), float64(board.Dx())/8), c)
+}
+
+// arrowPoints returns the polyline of the arrow between the centers of two squares, with a corner for a knight jump.
+func arrowPoints(board image.Rectangle, from, to chess.Square, fromPerspective chess.PieceColor) []point {
+	center := func(sq chess.Square) point {
+		r := SquareRect(board, sq, fromPerspective)
+		return point{float64(r.Min.X+r.Max.X) / 2, float64(r.Min.Y+r.Max.Y) / 2}
+	}
+	pts := []point{center(from)}
+	if df, dr := abs(to.File()-from.File()), abs(to.Rank()-from.Rank()); df*dr == 2 {
+		corner := chess.MustNewSquare(to.File(), from.Rank())
+		if dr > df {
+			corner = chess.MustNewSquare(from.File(), to.Rank())
+		}
+		pts = append(pts, center(corner))
+	}
+	return append(pts, center(to))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// arrowShape returns the shape of an arrow along the polyline pts with the head at the last point
+// for the board with the square size ss.
+func arrowShape(pts []point, ss float64) shape {
+	shaft, headWidth, headLength := ss/6, ss/2, ss/2.5
+	var s shape
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		length := math.Hypot(b[0]-a[0], b[1]-a[1])
+		// unit vector along the segment and the normal to it
+		ux, uy := (b[0]-a[0])/length, (b[1]-a[1])/length
+		nx, ny := -uy, ux
+		at := func(along, across float64) point {
+			return point{a[0] + ux*along + nx*across, a[1] + uy*along + ny*across}
+		}
+		if i < len(pts)-1 {
+			// the leg goes past the corner to fill it
+			end := length + shaft/2
+			s = append(s, []point{at(0, shaft/2), at(end, shaft/2), at(end, -shaft/2), at(0, -shaft/2)})
+			continue
+		}
+		neck := length - math.Min(headLength, length)
+		s = append(s, []point{
+			at(0, shaft/2), at(neck, shaft/2), at(neck, headWidth/2), at(length, 0),
+			at(neck, -headWidth/2), at(neck, -shaft/2), at(0, -shaft/2),
+		})
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestSquareRect(t *testing.T) {
+	board := image.Rect(10, 20, 90, 100)
+	tcs := []struct {
+		sq   string
+		from chess.PieceColor
+		want image.Rectangle
+	}{
+		{"a1", chess.White, image.Rect(10, 90, 20, 100)},
+		{"h8", chess.White, image.Rect(80, 20, 90, 30)},
+		{"a1", chess.Black, image.Rect(80, 20, 90, 30)},
+		{"e2", chess.Black, image.Rect(40, 30, 50, 40)},
+	}
+	for _, tc := range tcs {
+		sq := chess.MustNewSquareFromString(tc.sq)
+		if got := SquareRect(board, sq, tc.from); got != tc.want {
+			t.Errorf("%s from %s: want %v, got %v", tc.sq, tc.from, tc.want, got)
+		}
+	}
+}
+
+func TestDrawArrow(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 160, 160))
+	c := color.RGBA{G: 0xff, A: 0xff}
+	from, to := chess.MustNewSquare(0, 0), chess.MustNewSquare(0, 3)
+	DrawArrow(dst, dst.Bounds(), from, to, chess.White, c)
+
+	// the arrow goes up along the a-file from a1 to a4
+	for _, pt := range []image.Point{{10, 148}, {10, 120}, {9, 94}} {
+		if dst.RGBAAt(pt.X, pt.Y) != c {
+			t.Errorf("pixel %v must be on the arrow", pt)
+		}
+	}
+	for _, pt := range []image.Point{{10, 80}, {30, 120}, {0, 120}} {
+		if dst.RGBAAt(pt.X, pt.Y).A != 0 {
+			t.Errorf("pixel %v must not be on the arrow", pt)
+		}
+	}
+}
+
+func TestDrawKnightArrow(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 160, 160))
+	c := color.RGBA{G: 0xff, A: 0xff}
+	// g1 to f3 goes up the g-file to g3 and turns left
+	DrawArrow(dst, dst.Bounds(), chess.MustNewSquareFromString("g1"), chess.MustNewSquareFromString("f3"), chess.White, c)
+
+	for _, pt := range []image.Point{{130, 140}, {130, 110}, {120, 110}} {
+		if dst.RGBAAt(pt.X, pt.Y) != c {
+			t.Errorf("pixel %v must be on the arrow", pt)
+		}
+	}
+	// the straight line between the centers is not drawn
+	if dst.RGBAAt(120, 125).A != 0 {
+		t.Errorf("knight arrow must not be straight")
+	}
+}
+
+func TestDrawArrowAntialiasing(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 160, 160))
+	c := color.RGBA{G: 0xff, A: 0xff}
+	DrawArrow(dst, dst.Bounds(), chess.MustNewSquare(0, 0), chess.MustNewSquare(0, 3), chess.White, c)
+
+	// the shaft is 20/6 pixels wide around x = 10, so the edge pixels are partially covered
+	if a := dst.RGBAAt(8, 120).A; a == 0 || a == 0xff {
+		t.Errorf("edge pixel must be partially covered, got alpha %d", a)
+	}
+}
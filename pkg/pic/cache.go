@@ -0,0 +1,105 @@
+package pic
+
+import (
+	"container/list"
+	"image"
+	"sync"
+)
+
+// imageCache is a cache of drawn images (or sets of them) bounded by their total size in bytes.
+// When it is full, the least recently used values are evicted. The values are computed outside the lock,
+// and concurrent requests of the same key wait for a single computation.
+type imageCache struct {
+	// maxBytes is the largest total size of the cached values. Values bigger than it are not cached.
+	maxBytes int
+
+	mtx     sync.Mutex
+	bytes   int
+	order   *list.List
+	entries map[interface{}]*list.Element
+	pending map[interface{}]*cacheCall
+}
+
+type cacheEntry struct {
+	key   interface{}
+	value interface{}
+	bytes int
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newImageCache(maxBytes int) *imageCache {
+	return &imageCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  map[interface{}]*list.Element{},
+		pending:  map[interface{}]*cacheCall{},
+	}
+}
+
+// get returns the cached value of key or computes it with f, which also returns the size of the value in bytes.
+// Errors are not cached.
+func (c *imageCache) get(key interface{}, f func() (value interface{}, bytes int, err error)) (interface{}, error) {
+	c.mtx.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.mtx.Unlock()
+		return e.Value.(*cacheEntry).value, nil
+	}
+	if call, ok := c.pending[key]; ok {
+		c.mtx.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.pending[key] = call
+	c.mtx.Unlock()
+
+	var bytes int
+	call.value, bytes, call.err = f()
+
+	c.mtx.Lock()
+	delete(c.pending, key)
+	if call.err == nil && bytes <= c.maxBytes {
+		c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: call.value, bytes: bytes})
+		c.bytes += bytes
+		for c.bytes > c.maxBytes {
+			c.remove(c.order.Back())
+		}
+	}
+	c.mtx.Unlock()
+	close(call.done)
+	return call.value, call.err
+}
+
+func (c *imageCache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.bytes
+}
+
+// size returns the number of the cached values and their total size in bytes.
+func (c *imageCache) size() (n, bytes int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.order.Len(), c.bytes
+}
+
+// setMax changes the largest total size and evicts the values that no longer fit.
+func (c *imageCache) setMax(maxBytes int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.maxBytes = maxBytes
+	for c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// imageBytes returns the size of the pixels of img as RGBA.
+func imageBytes(img image.Image) int {
+	return img.Bounds().Dx() * img.Bounds().Dy() * 4
+}
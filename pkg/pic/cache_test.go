@@ -0,0 +1,101 @@
+package pic
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestImageCache(t *testing.T) {
+	c := newImageCache(100)
+	calls := 0
+	get := func(key string, bytes int) interface{} {
+		v, err := c.get(key, func() (interface{}, int, error) {
+			calls++
+			return key, bytes, nil
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", key, err)
+		}
+		return v
+	}
+
+	get("a", 40)
+	get("b", 40)
+	if get("a", 40) != "a" || calls != 2 {
+		t.Errorf("a must be cached, got %d calls", calls)
+	}
+	// b is the least recently used one
+	get("c", 40)
+	if n, bytes := c.size(); n != 2 || bytes != 80 {
+		t.Errorf("want 2 values of 80 bytes, got %d of %d", n, bytes)
+	}
+	calls = 0
+	get("a", 40)
+	get("c", 40)
+	if calls != 0 {
+		t.Errorf("a and c must be cached, got %d calls", calls)
+	}
+	get("b", 40)
+	if calls != 1 {
+		t.Errorf("b must be evicted")
+	}
+
+	// too big values are returned but not cached
+	if get("big", 200) != "big" {
+		t.Errorf("want the value")
+	}
+	if _, bytes := c.size(); bytes > 100 {
+		t.Errorf("cache must stay bounded, got %d bytes", bytes)
+	}
+
+	// errors are not cached
+	errTest := errors.New("test")
+	if _, err := c.get("err", func() (interface{}, int, error) { return nil, 0, errTest }); err != errTest {
+		t.Errorf("want test error, got %v", err)
+	}
+	calls = 0
+	get("err", 10)
+	if calls != 1 {
+		t.Errorf("error must not be cached")
+	}
+
+	c.setMax(10)
+	if n, bytes := c.size(); n != 1 || bytes != 10 {
+		t.Errorf("want a value of 10 bytes left, got %d of %d", n, bytes)
+	}
+}
+
+func TestImageCacheConcurrent(t *testing.T) {
+	c := newImageCache(100)
+	var calls int32
+	started, release := make(chan struct{}), make(chan struct{})
+	f := func() (interface{}, int, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return "value", 10, nil
+	}
+	var wg sync.WaitGroup
+	get := func() {
+		defer wg.Done()
+		if v, err := c.get("key", f); err != nil || v != "value" {
+			t.Errorf("want value, got %v, %v", v, err)
+		}
+	}
+	wg.Add(1)
+	go get()
+	<-started
+	// the value is being computed, the others wait for it
+	for i := 0; i < 7; i++ {
+		wg.Add(1)
+		go get()
+	}
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("want a single computation, got %d", calls)
+	}
+}
@@ -0,0 +1,56 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// HeaderHeight returns the height of the header band over the board drawn in the rectangle board (see DrawHeader).
+func HeaderHeight(board image.Rectangle) int {
+	return board.Dx() / 8
+}
+
+// CaptionHeight returns the height of the caption band under the board drawn in the rectangle board
+// (see DrawCaption).
+func CaptionHeight(board image.Rectangle) int {
+	return board.Dx() / 12
+}
+
+// DrawHeader fills r with MarginColor and draws the title (e.g. the players) and the smaller subtitle
+// (e.g. the event and the date) under it in the middle of r. Either of them may be empty.
+// The texts that are too long for r are made smaller.
+func DrawHeader(dst draw.Image, r image.Rectangle, title, subtitle string) {
+	draw.Draw(dst, r, image.NewUniform(MarginColor), image.Point{}, draw.Src)
+	h := float64(r.Dy())
+	x := (r.Min.X + r.Max.X) / 2
+	if title == "" || subtitle == "" {
+		// a single line is in the middle
+		drawBandText(dst, r, image.Pt(x, r.Min.Y+r.Dy()/2), title+subtitle, h*0.3, MarginTextColor)
+		return
+	}
+	drawBandText(dst, r, image.Pt(x, r.Min.Y+int(h*0.36)), title, h*0.3, MarginTextColor)
+	drawBandText(dst, r, image.Pt(x, r.Min.Y+int(h*0.74)), subtitle, h*0.2, MarginTextColor)
+}
+
+// DrawCaption fills r with MarginColor and draws text (e.g. the last move) in the middle of r.
+// The text that is too long for r is made smaller.
+func DrawCaption(dst draw.Image, r image.Rectangle, text string) {
+	draw.Draw(dst, r, image.NewUniform(MarginColor), image.Point{}, draw.Src)
+	drawBandText(dst, r, r.Min.Add(r.Max).Div(2), text, float64(r.Dy())*0.45, MarginTextColor)
+}
+
+// drawBandText draws s centered at pt with the size reduced to fit in the width of r with padding.
+func drawBandText(dst draw.Image, r image.Rectangle, pt image.Point, s string, size float64, c color.Color) {
+	if s == "" {
+		return
+	}
+	pad := r.Dy() / 4
+	width := r.Dx() - 2*pad
+	if w, _ := textBounds(textFace(size), s); w > width {
+		// the sizes are rounded down so that the text never ends up wider than the band
+		size = math.Floor(size*float64(width)/float64(w)*2) / 2
+	}
+	DrawText(dst, pt, s, size, AnchorCenter, c)
+}
@@ -0,0 +1,75 @@
+package pic
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+// textColumns returns the range of the columns of r in dst that have pixels other than MarginColor.
+func textColumns(dst *image.RGBA, r image.Rectangle) (minX, maxX int) {
+	minX, maxX = r.Max.X, r.Min.X-1
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if dst.RGBAAt(x, y) != MarginColor {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+			}
+		}
+	}
+	return minX, maxX
+}
+
+func TestDrawCaption(t *testing.T) {
+	board := image.Rect(0, 0, 360, 360)
+	r := image.Rect(0, 360, 360, 360+CaptionHeight(board))
+	dst := image.NewRGBA(image.Rect(0, 0, 360, r.Max.Y+10))
+
+	DrawCaption(dst, r, "")
+	if minX, maxX := textColumns(dst, r); minX <= maxX {
+		t.Errorf("want empty band, got text in columns %d-%d", minX, maxX)
+	}
+	if got := dst.RGBAAt(0, 0); got == MarginColor {
+		t.Errorf("only the band must be filled")
+	}
+
+	DrawCaption(dst, r, "23. Rxe6+")
+	minX, maxX := textColumns(dst, r)
+	if minX > maxX {
+		t.Fatalf("no text in the band")
+	}
+	if mid := (minX + maxX) / 2; mid < 170 || mid > 190 {
+		t.Errorf("text must be centered, got columns %d-%d", minX, maxX)
+	}
+
+	// too long text is made smaller to fit
+	DrawCaption(dst, r, strings.Repeat("23. Rxe6+ ", 10))
+	if minX, maxX := textColumns(dst, r); minX <= r.Min.X || maxX >= r.Max.X-1 {
+		t.Errorf("text must fit in the band, got columns %d-%d", minX, maxX)
+	}
+}
+
+func TestDrawHeader(t *testing.T) {
+	board := image.Rect(0, 0, 360, 360)
+	r := image.Rect(0, 0, 360, HeaderHeight(board))
+	for _, tc := range []struct{ title, subtitle string }{
+		{"Carlsen (2882) – Caruana (2820)", "Norway Chess · 2023.05.30"},
+		{"Carlsen – Caruana", ""},
+		{"", "Norway Chess"},
+	} {
+		dst := image.NewRGBA(r)
+		DrawHeader(dst, r, tc.title, tc.subtitle)
+		// the text lines are in the upper and the lower halves, a single line is in both
+		upper, lower := r, r
+		upper.Max.Y, lower.Min.Y = r.Dy()/2, r.Dy()/2
+		upperMin, upperMax := textColumns(dst, upper)
+		lowerMin, lowerMax := textColumns(dst, lower)
+		if upperMin > upperMax || lowerMin > lowerMax {
+			t.Errorf("%q, %q: want text in both halves of the band", tc.title, tc.subtitle)
+		}
+	}
+}
@@ -54,32 +54,75 @@ func (col collection) Canvas() draw.Image {
 	return image.NewRGBA(col.Board(chess.White).Bounds())
 }
 
+// OpenCollection opens the board images (board_white.png and board_black.png) and the piece images
+// (e.g. white/knight.png, see OpenPieces) in dir under prefix.
 func OpenCollection(dir fs.FS, prefix string) (Collection, error) {
-	col := collection{}
-
-	ps := -1
+	col, err := openPieces(dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	ps := col.images[2].Bounds().Dx()
 	for color := chess.White; color <= chess.Black; color++ {
-		img, err := loadSquareImage(dir, "board_"+color.Name()+".png", prefix)
+		name := "board_" + color.Name() + ".png"
+		img, err := loadSquareImage(dir, name, prefix)
 		if err != nil {
-			return col, err
+			return nil, err
+		}
+		if err := checkPieceSize(ps, img.Bounds().Dx()); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
 		}
 		col.images[color] = img
-		bs := img.Bounds().Dx()
+	}
+
+	return col, nil
+}
+
+// OpenPieces opens the piece images in dir under prefix and returns them with the boards (and the highlights)
+// of board. The images are named by the color and the kind of the pieces, e.g. white/knight.png.
+// If the pieces do not fit in the squares of board, a PieceSizeError is returned.
+func OpenPieces(dir fs.FS, prefix string, board Collection) (Collection, error) {
+	col, err := openPieces(dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return withBoard(col, board)
+}
+
+// withBoard returns the pieces of col with the boards (and the highlights) of board.
+// The pieces must fit in the squares of board.
+func withBoard(col collection, board Collection) (Collection, error) {
+	ps := col.images[2].Bounds().Dx()
+	for color := chess.White; color <= chess.Black; color++ {
+		if err := checkPieceSize(ps, board.Board(color).Bounds().Dx()); err != nil {
+			return nil, err
+		}
+	}
+	col.images[chess.White] = board.Board(chess.White)
+	col.images[chess.Black] = board.Board(chess.Black)
+
+	if _, ok := board.(HighlightCollection); ok {
+		return WithHighlights(col, CollectionHighlights(board)), nil
+	}
+	return col, nil
+}
+
+// openPieces returns a collection of the piece images in dir under prefix without the boards.
+func openPieces(dir fs.FS, prefix string) (collection, error) {
+	col := collection{}
+
+	ps := -1
+	for color := chess.White; color <= chess.Black; color++ {
 		for kind := chess.Pawn; kind <= chess.King; kind++ {
-			img, err = loadSquareImage(dir, path.Join(color.Name(), kind.Name()+".png"), prefix)
+			name := path.Join(color.Name(), kind.Name()+".png")
+			img, err := loadSquareImage(dir, name, prefix)
 			if err != nil {
 				return col, err
 			}
 			col.images[2+int(color)*6+int(kind-chess.Pawn)] = img
 			if ps < 0 {
 				ps = img.Bounds().Dx()
-				if ps*8 > bs {
-					return nil, fmt.Errorf("piece image too big for the board")
-				}
-			} else {
-				if img.Bounds().Dx() != ps {
-					return nil, fmt.Errorf("piece images must be the same size")
-				}
+			} else if img.Bounds().Dx() != ps {
+				return col, fmt.Errorf("%s: piece images must be the same size (%dpx, not %dpx)", name, ps, img.Bounds().Dx())
 			}
 		}
 	}
@@ -87,11 +130,37 @@ func OpenCollection(dir fs.FS, prefix string) (Collection, error) {
 	return col, nil
 }
 
+// PieceSizeError is returned when the piece images do not fit in the squares of the board.
+// The board may be scaled to MinSize (see ScaleCollection) to fit them.
+type PieceSizeError struct {
+	// Piece and Board are the sizes of the images in pixels.
+	Piece, Board int
+}
+
+func (err PieceSizeError) Error() string {
+	return fmt.Sprintf("piece image too big for the board: %dpx pieces do not fit in the %dpx squares of the %dpx board",
+		err.Piece, err.Board/8, err.Board)
+}
+
+// MinSize returns the size of the smallest board the pieces fit in.
+func (err PieceSizeError) MinSize() int {
+	return err.Piece * 8
+}
+
+// checkPieceSize checks that the pieces of the size ps fit in the squares of the board of the size bs.
+func checkPieceSize(ps, bs int) error {
+	if ps*8 > bs {
+		return PieceSizeError{Piece: ps, Board: bs}
+	}
+	return nil
+}
+
 func loadSquareImage(dir fs.FS, name, prefix string) (Image, error) {
 	f, err := dir.Open(path.Join(prefix, name))
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 	img, _, err := image.Decode(f)
 	if err != nil {
 		return nil, fmt.Errorf("decode %s: %w", name, err)
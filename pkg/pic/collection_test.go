@@ -0,0 +1,81 @@
+package pic
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/pic/pictest"
+)
+
+func TestOpenCollection(t *testing.T) {
+	tcs := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "valid",
+			fsys: pictest.CollectionFS("set", 160, 20, nil),
+		},
+		{
+			name:    "pieces too big",
+			fsys:    pictest.CollectionFS("set", 160, 24, nil),
+			wantErr: "board_white.png: piece image too big for the board: 24px pieces do not fit in the 20px squares of the 160px board",
+		},
+		{
+			name:    "black board too small",
+			fsys:    pictest.CollectionFS("set", 160, 20, map[string]int{"board_black.png": 120}),
+			wantErr: "board_black.png: piece image too big for the board",
+		},
+		{
+			name:    "different pieces",
+			fsys:    pictest.CollectionFS("set", 160, 20, map[string]int{"black/queen.png": 18}),
+			wantErr: "black/queen.png: piece images must be the same size (20px, not 18px)",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			_, err := OpenCollection(tc.fsys, "set")
+			if tc.wantErr == "" {
+				if err != nil {
+					tt.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				tt.Errorf("want error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenPieces(t *testing.T) {
+	board := WithHighlights(testCollection(240, 30), Highlights{LastMove: image.Black})
+	col, err := OpenPieces(pictest.CollectionFS("set", 160, 30, nil), "set", board)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := col.Board(chess.White).Bounds().Dx(); got != 240 {
+		t.Errorf("board: want 240, got %d", got)
+	}
+	if got := col.Piece(chess.Piece{Color: chess.Black, Kind: chess.King}).Bounds().Dx(); got != 30 {
+		t.Errorf("piece: want 30, got %d", got)
+	}
+	if CollectionHighlights(col).LastMove != image.Black {
+		t.Errorf("highlights of the board must be kept")
+	}
+
+	// the board is not scaled up to fit the pieces
+	_, err = OpenPieces(pictest.CollectionFS("set", 160, 30, nil), "set", testCollection(160, 20))
+	var serr PieceSizeError
+	if !errors.As(err, &serr) {
+		t.Fatalf("want PieceSizeError, got %v", err)
+	}
+	if serr.MinSize() != 240 {
+		t.Errorf("want the minimal size of 240, got %d", serr.MinSize())
+	}
+}
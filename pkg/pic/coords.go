@@ -0,0 +1,151 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// Coordinates selects where the file letters and the rank numbers are drawn.
+type Coordinates int
+
+const (
+	// NoCoordinates draws no labels.
+	NoCoordinates Coordinates = iota
+	// InsideCoordinates draws the labels in the corners of the edge squares: the files on the bottom row
+	// and the ranks on the left column. The labels have the color of the opposite squares.
+	InsideCoordinates
+	// MarginCoordinates draws the labels in a margin around the board (see CoordinatesMargin).
+	MarginCoordinates
+)
+
+var coordinatesNames = map[Coordinates]string{
+	NoCoordinates:     "none",
+	InsideCoordinates: "inside",
+	MarginCoordinates: "margin",
+}
+
+func (c Coordinates) String() string {
+	return coordinatesNames[c]
+}
+
+// ParseCoordinates returns the placement of the coordinates by its name ("none", "inside" or "margin").
+func ParseCoordinates(s string) (Coordinates, error) {
+	for c, name := range coordinatesNames {
+		if name == s {
+			return c, nil
+		}
+	}
+	return NoCoordinates, fmt.Errorf("unknown coordinates %q", s)
+}
+
+var (
+	// MarginColor is the background of the margin with the coordinates.
+	MarginColor = color.RGBA{R: 0x30, G: 0x2e, B: 0x2b, A: 0xff}
+	// MarginTextColor is the color of the coordinates in the margin.
+	MarginTextColor = color.RGBA{R: 0xd0, G: 0xd0, B: 0xd0, A: 0xff}
+)
+
+// coordinateLabels returns the labels of the columns (from left to right) and the rows (from top to bottom)
+// of the board drawn from the perspective.
+func coordinateLabels(fromPerspective chess.PieceColor) (files, ranks [8]string) {
+	for i := 0; i < 8; i++ {
+		files[i] = string(rune('a' + i))
+		ranks[i] = strconv.Itoa(8 - i)
+		if fromPerspective == chess.Black {
+			files[i] = string(rune('h' - i))
+			ranks[i] = strconv.Itoa(i + 1)
+		}
+	}
+	return files, ranks
+}
+
+// DrawCoordinates draws the labels inside the edge squares of the board drawn in the rectangle board.
+// The colors of the squares are taken from dst, so it must be used right after the board is drawn
+// (e.g. as the first Layer).
+func DrawCoordinates(dst draw.Image, board image.Rectangle, fromPerspective chess.PieceColor) {
+	colorOf := func(sq chess.Square) color.Color {
+		r := SquareRect(board, sq, fromPerspective)
+		center := r.Min.Add(r.Max).Div(2)
+		return dst.At(center.X, center.Y)
+	}
+	// a1 is a dark square and h1 is a light one
+	dark, light := colorOf(chess.MustNewSquare(0, 0)), colorOf(chess.MustNewSquare(7, 0))
+	// labels on light squares are dark and vice versa
+	onSquare := func(sq chess.Square) color.Color {
+		if (sq.File()+sq.Rank())%2 == 0 {
+			return light
+		}
+		return dark
+	}
+
+	ss := board.Dx() / 8
+	size := float64(ss) * 3 / 10
+	pad := ss / 16
+	files, ranks := coordinateLabels(fromPerspective)
+	for i := 0; i < 8; i++ {
+		// the labels are placed by the position on the board, the squares under them depend on the perspective
+		bottom := image.Rect(board.Min.X+i*ss, board.Max.Y-ss, board.Min.X+(i+1)*ss, board.Max.Y)
+		sq := squareAt(board, bottom.Min, fromPerspective)
+		DrawText(dst, bottom.Max.Sub(image.Pt(pad, 2*pad)), files[i], size, AnchorBottomRight, onSquare(sq))
+
+		left := image.Rect(board.Min.X, board.Min.Y+i*ss, board.Min.X+ss, board.Min.Y+(i+1)*ss)
+		sq = squareAt(board, left.Min, fromPerspective)
+		DrawText(dst, left.Min.Add(image.Pt(pad, pad)), ranks[i], size, AnchorTopLeft, onSquare(sq))
+	}
+}
+
+// squareAt returns the square that contains pt of the board drawn in the rectangle board.
+func squareAt(board image.Rectangle, pt image.Point, fromPerspective chess.PieceColor) chess.Square {
+	ss := board.Dx() / 8
+	x, y := (pt.X-board.Min.X)/ss, (pt.Y-board.Min.Y)/ss
+	if fromPerspective == chess.Black {
+		return chess.MustNewSquare(7-x, y)
+	}
+	return chess.MustNewSquare(x, 7-y)
+}
+
+// CoordinatesLayer returns a Layer that draws the labels inside the edge squares.
+func CoordinatesLayer(fromPerspective chess.PieceColor) Layer {
+	return func(dst draw.Image, board image.Rectangle) {
+		DrawCoordinates(dst, board, fromPerspective)
+	}
+}
+
+// CoordinatesMargin returns the width of the margin with the coordinates around the board drawn in the rectangle board.
+func CoordinatesMargin(board image.Rectangle) int {
+	return board.Dx() / 16
+}
+
+// DrawCoordinatesMargin fills the margin around the board drawn in the rectangle board with MarginColor
+// and draws the labels on all four sides of the board. The board itself is left intact.
+func DrawCoordinatesMargin(dst draw.Image, board image.Rectangle, fromPerspective chess.PieceColor) {
+	m := CoordinatesMargin(board)
+	outer := board.Inset(-m)
+	bg := image.NewUniform(MarginColor)
+	for _, r := range []image.Rectangle{
+		image.Rect(outer.Min.X, outer.Min.Y, outer.Max.X, board.Min.Y),
+		image.Rect(outer.Min.X, board.Max.Y, outer.Max.X, outer.Max.Y),
+		image.Rect(outer.Min.X, board.Min.Y, board.Min.X, board.Max.Y),
+		image.Rect(board.Max.X, board.Min.Y, outer.Max.X, board.Max.Y),
+	} {
+		draw.Draw(dst, r, bg, image.Point{}, draw.Src)
+	}
+
+	ss := board.Dx() / 8
+	size := float64(m) * 3 / 4
+	files, ranks := coordinateLabels(fromPerspective)
+	for i := 0; i < 8; i++ {
+		x := board.Min.X + i*ss + ss/2
+		DrawText(dst, image.Pt(x, outer.Min.Y+m/2), files[i], size, AnchorCenter, MarginTextColor)
+		DrawText(dst, image.Pt(x, board.Max.Y+m/2), files[i], size, AnchorCenter, MarginTextColor)
+
+		y := board.Min.Y + i*ss + ss/2
+		DrawText(dst, image.Pt(outer.Min.X+m/2, y), ranks[i], size, AnchorCenter, MarginTextColor)
+		DrawText(dst, image.Pt(board.Max.X+m/2, y), ranks[i], size, AnchorCenter, MarginTextColor)
+	}
+}
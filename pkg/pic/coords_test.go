@@ -0,0 +1,105 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	for c := NoCoordinates; c <= MarginCoordinates; c++ {
+		got, err := ParseCoordinates(c.String())
+		if err != nil || got != c {
+			t.Errorf("%s: got %s, %v", c, got, err)
+		}
+	}
+	if _, err := ParseCoordinates("top"); err == nil {
+		t.Errorf("want error for unknown coordinates")
+	}
+}
+
+func TestCoordinateLabels(t *testing.T) {
+	files, ranks := coordinateLabels(chess.White)
+	if files[0] != "a" || files[7] != "h" || ranks[0] != "8" || ranks[7] != "1" {
+		t.Errorf("white: got %v and %v", files, ranks)
+	}
+	files, ranks = coordinateLabels(chess.Black)
+	if files[0] != "h" || files[7] != "a" || ranks[0] != "1" || ranks[7] != "8" {
+		t.Errorf("black: got %v and %v", files, ranks)
+	}
+}
+
+// checkerboard returns a board with light and dark squares of size ss.
+func checkerboard(ss int, light, dark color.Color) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, 8*ss, 8*ss))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			c := light
+			if (x+y)%2 == 1 {
+				c = dark
+			}
+			draw.Draw(dst, image.Rect(x*ss, y*ss, (x+1)*ss, (y+1)*ss), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+	return dst
+}
+
+func TestDrawCoordinates(t *testing.T) {
+	light := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	dark := color.RGBA{A: 0xff}
+	for _, from := range []chess.PieceColor{chess.White, chess.Black} {
+		t.Run(from.Name(), func(tt *testing.T) {
+			dst := checkerboard(80, light, dark)
+			DrawCoordinates(dst, dst.Bounds(), from)
+
+			// count returns the number of pixels in r that are not c
+			count := func(r image.Rectangle, c color.RGBA) int {
+				n := 0
+				for x := r.Min.X; x < r.Max.X; x++ {
+					for y := r.Min.Y; y < r.Max.Y; y++ {
+						if dst.RGBAAt(x, y) != c {
+							n++
+						}
+					}
+				}
+				return n
+			}
+			// the bottom-left square is dark (a1 or h8) and has both labels
+			if count(image.Rect(0, 560, 80, 640), dark) == 0 {
+				tt.Errorf("no labels in the corner square")
+			}
+			if count(image.Rect(240, 240, 320, 320), light) != 0 || count(image.Rect(320, 240, 400, 320), dark) != 0 {
+				tt.Errorf("labels must be drawn only in the edge squares")
+			}
+		})
+	}
+}
+
+func TestDrawCoordinatesMargin(t *testing.T) {
+	board := image.Rect(20, 20, 340, 340)
+	dst := image.NewRGBA(board.Inset(-CoordinatesMargin(board)))
+	if dst.Bounds() != image.Rect(0, 0, 360, 360) {
+		t.Fatalf("want margin of 20 pixels, got %v", dst.Bounds())
+	}
+	DrawCoordinatesMargin(dst, board, chess.White)
+	if got := dst.RGBAAt(1, 1); got != MarginColor {
+		t.Errorf("want margin color in the corner, got %v", got)
+	}
+	if got := dst.RGBAAt(180, 180); got != (color.RGBA{}) {
+		t.Errorf("board must be left intact, got %v", got)
+	}
+	text := 0
+	for x := 20; x < 60; x++ {
+		for y := 340; y < 360; y++ {
+			if dst.RGBAAt(x, y) != MarginColor {
+				text++
+			}
+		}
+	}
+	if text == 0 {
+		t.Errorf("no label under the first file")
+	}
+}
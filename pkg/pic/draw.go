@@ -2,15 +2,22 @@ package pic
 
 import (
 	"image"
+	"image/color"
 	"image/draw"
 
 	"github.com/xopoww/chess2pic/pkg/chess"
 )
 
+// Layer draws over the board drawn in the rectangle board of dst before the pieces are drawn
+// (e.g. square highlights that must not cover the pieces).
+type Layer func(dst draw.Image, board image.Rectangle)
+
 // DrawPosition creates a draw.Image from Position using Collection.
 // If Collection is a CanvasCollection, its Canvas() method is used to create resulting image,
-// otherwise image.NewRGBA() is used.
-func DrawPosition(col Collection, pos chess.Position, fromPerspective chess.PieceColor) draw.Image {
+// otherwise image.NewRGBA() is used. The canvas may be larger than the board (e.g. to leave room for
+// additional elements), in which case the board is drawn at its top-left corner.
+// The layers are drawn in order between the board and the pieces.
+func DrawPosition(col Collection, pos chess.Position, fromPerspective chess.PieceColor, layers ...Layer) draw.Image {
 	var dst draw.Image
 	if ccol, ok := col.(CanvasCollection); ok {
 		dst = ccol.Canvas()
@@ -18,13 +25,25 @@ func DrawPosition(col Collection, pos chess.Position, fromPerspective chess.Piec
 		dst = image.NewRGBA(col.Board(fromPerspective).Bounds())
 	}
 
-	if dst.Bounds() != col.Board(fromPerspective).Bounds() {
-		panic("invalid dst bounds")
+	bs := col.Board(fromPerspective).Bounds().Size()
+	if ds := dst.Bounds().Size(); ds.X < bs.X || ds.Y < bs.Y {
+		panic("canvas is smaller than the board")
+	}
+	DrawPositionOn(dst, dst.Bounds().Min, col, pos, fromPerspective, layers...)
+	return dst
+}
+
+// DrawPositionOn draws Position on dst with the top-left corner of the board at pt.
+// The rest of dst is left intact. The layers are drawn in order between the board and the pieces.
+func DrawPositionOn(dst draw.Image, pt image.Point, col Collection, pos chess.Position, fromPerspective chess.PieceColor, layers ...Layer) {
+	board := col.Board(fromPerspective)
+	br := board.Bounds().Sub(board.Bounds().Min).Add(pt)
+	draw.Draw(dst, br, board, board.Bounds().Min, draw.Over)
+	for _, layer := range layers {
+		layer(dst, br)
 	}
-	draw.Draw(dst, dst.Bounds(), col.Board(fromPerspective), image.Pt(0, 0), draw.Over)
 
-	bs := dst.Bounds().Dx()
-	ss := bs / 8
+	ss := br.Dx() / 8
 	ps := col.Piece(chess.Piece{Color: chess.White, Kind: chess.Pawn}).Bounds().Dx()
 	off := (ss - ps) / 2
 
@@ -37,19 +56,28 @@ func DrawPosition(col Collection, pos chess.Position, fromPerspective chess.Piec
 			}
 
 			img := col.Piece(p)
-			var (
-				x int
-				y int
-			)
-			if fromPerspective == chess.White {
-				x = file*ss + off
-				y = (7-rank)*ss + off
-			} else {
-				x = (7-file)*ss + off
-				y = rank*ss + off
-			}
-			draw.Draw(dst, image.Rect(x, y, x+ps, y+ps), img, image.Pt(0, 0), draw.Over)
+			min := SquareRect(br, sq, fromPerspective).Min.Add(image.Pt(off, off))
+			draw.Draw(dst, image.Rectangle{Min: min, Max: min.Add(image.Pt(ps, ps))}, img, img.Bounds().Min, draw.Over)
 		}
 	}
-	return dst
+}
+
+// Tint blends a uniform color c over the whole dst. The alpha channel of c sets the strength of the effect.
+func Tint(dst draw.Image, c color.Color) {
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// LabelColor is the color of the plate under the text drawn by DrawLabel.
+var LabelColor = color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xc0}
+
+// DrawLabel draws a short text (e.g. "book") in white on a plate of LabelColor at the top-left corner
+// of the board drawn in the rectangle board. The label is drawn over the pieces.
+func DrawLabel(dst draw.Image, board image.Rectangle, text string) {
+	ss := board.Dx() / 8
+	size := float64(ss) / 4
+	w, h := textBounds(textFace(size), text)
+	pad := ss / 10
+	r := image.Rect(0, 0, w+2*pad, h+2*pad).Add(board.Min.Add(image.Pt(pad, pad)))
+	draw.Draw(dst, r, image.NewUniform(LabelColor), image.Point{}, draw.Over)
+	DrawText(dst, r.Min.Add(image.Pt(pad, pad)), text, size, AnchorTopLeft, color.White)
 }
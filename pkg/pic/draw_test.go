@@ -127,3 +127,42 @@ func TestDrawPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestDrawPositionOn(t *testing.T) {
+	col := mockCollection{}
+	pos := chess.Position{}.Set(chess.MustNewSquareFromString("a1"), chess.Piece{Kind: chess.King, Color: chess.White})
+
+	// the canvas is larger than the board and does not start at the origin
+	// (mock colors are stored exactly in RGBA64)
+	dst := image.NewRGBA64(image.Rect(-2, -1, 10, 8))
+	DrawPositionOn(dst, image.Pt(1, -1), col, pos, chess.White)
+
+	tcs := []struct {
+		x, y int
+		want uint32
+	}{
+		{0, 0, 0},                  // outside the board
+		{1, -1, 0xff},              // top-left corner of the board
+		{1, 6, uint32(chess.King)}, // a1 is the bottom-left square
+		{9, 6, 0},                  // outside the board
+	}
+	for _, tc := range tcs {
+		if r, _, _, _ := dst.At(tc.x, tc.y).RGBA(); r != tc.want {
+			t.Errorf("(%d, %d): want %#x, got %#x", tc.x, tc.y, tc.want, r)
+		}
+	}
+}
+
+func TestDrawLabel(t *testing.T) {
+	board := image.Rect(40, 40, 360, 360)
+	dst := image.NewRGBA(image.Rect(0, 0, 400, 400))
+	DrawLabel(dst, board, "book")
+	if got := dst.RGBAAt(board.Min.X+5, board.Min.Y+5); got.A == 0 {
+		t.Errorf("want the plate at the top-left corner of the board")
+	}
+	for _, pt := range []image.Point{{20, 20}, {board.Min.X + 200, board.Min.Y + 5}, {board.Min.X + 5, board.Min.Y + 200}} {
+		if got := dst.RGBAAt(pt.X, pt.Y); got.A != 0 {
+			t.Errorf("%v: want the rest left intact, got %v", pt, got)
+		}
+	}
+}
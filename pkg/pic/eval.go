@@ -0,0 +1,69 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+var (
+	evalWhite = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	evalBlack = color.RGBA{R: 0x40, G: 0x3d, B: 0x39, A: 0xff}
+	evalMid   = color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff}
+)
+
+// evalShare maps an evaluation to the share of white in [0, 1] (0.5 is equal position).
+// Centipawns are converted to winning chances, so that the scale is not dominated by large advantages.
+func evalShare(e chess.Eval) float64 {
+	return 0.5 + e.WinningChances()/2
+}
+
+// DrawEvalBar draws a vertical evaluation bar in the rectangle r of dst.
+// The white part of the bar is at the side of the board where white pieces are drawn.
+func DrawEvalBar(dst draw.Image, r image.Rectangle, e chess.Eval, fromPerspective chess.PieceColor) {
+	draw.Draw(dst, r, image.NewUniform(evalBlack), image.Point{}, draw.Src)
+
+	h := int(math.Round(evalShare(e) * float64(r.Dy())))
+	white := image.Rect(r.Min.X, r.Max.Y-h, r.Max.X, r.Max.Y)
+	if fromPerspective == chess.Black {
+		white = image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+h)
+	}
+	draw.Draw(dst, white, image.NewUniform(evalWhite), image.Point{}, draw.Src)
+
+	mid := r.Min.Y + r.Dy()/2
+	draw.Draw(dst, image.Rect(r.Min.X, mid, r.Max.X, mid+1), image.NewUniform(evalMid), image.Point{}, draw.Src)
+}
+
+// DrawEvalGraph draws a chart of the evaluation over the whole game: evals[i] is plotted at the i-th
+// of the points evenly distributed along the width, white advantage grows upwards.
+func DrawEvalGraph(evals []chess.Eval, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(evalBlack), image.Point{}, draw.Src)
+
+	if len(evals) > 0 {
+		for x := 0; x < width; x++ {
+			// linear interpolation between the neighbouring points
+			var share float64
+			if len(evals) == 1 || width == 1 {
+				share = evalShare(evals[0])
+			} else {
+				t := float64(x) / float64(width-1) * float64(len(evals)-1)
+				i := int(t)
+				if i >= len(evals)-1 {
+					i = len(evals) - 2
+				}
+				frac := t - float64(i)
+				share = evalShare(evals[i])*(1-frac) + evalShare(evals[i+1])*frac
+			}
+			h := int(math.Round(share * float64(height)))
+			draw.Draw(dst, image.Rect(x, height-h, x+1, height), image.NewUniform(evalWhite), image.Point{}, draw.Src)
+		}
+	}
+
+	mid := height / 2
+	draw.Draw(dst, image.Rect(0, mid, width, mid+1), image.NewUniform(evalMid), image.Point{}, draw.Src)
+	return dst
+}
@@ -0,0 +1,63 @@
+package pic
+
+import (
+	"image"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestDrawEvalBar(t *testing.T) {
+	tcs := []struct {
+		name      string
+		eval      chess.Eval
+		from      chess.PieceColor
+		wantWhite int // number of white pixels in the column
+		whiteLow  bool
+	}{
+		{name: "equal", eval: chess.Eval{}, from: chess.White, wantWhite: 50, whiteLow: true},
+		{name: "white mates", eval: chess.Eval{Mate: 2}, from: chess.White, wantWhite: 100, whiteLow: true},
+		{name: "black mates", eval: chess.Eval{Mate: -2}, from: chess.White, wantWhite: 0},
+		{name: "white is better", eval: chess.Eval{Centipawns: 300}, from: chess.Black, wantWhite: 75},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			dst := image.NewRGBA(image.Rect(0, 0, 10, 100))
+			DrawEvalBar(dst, image.Rect(2, 0, 4, 100), tc.eval, tc.from)
+
+			white := 0
+			for y := 0; y < 100; y++ {
+				if dst.RGBAAt(3, y) == evalWhite || (y == 50 && dst.RGBAAt(3, y) == evalMid && tc.wantWhite > 50) {
+					white++
+				}
+			}
+			// the middle line may hide a pixel of either color
+			if white < tc.wantWhite-1 || white > tc.wantWhite+1 {
+				tt.Errorf("want %d white pixels, got %d", tc.wantWhite, white)
+			}
+			if tc.whiteLow && dst.RGBAAt(3, 99) != evalWhite {
+				tt.Errorf("white part must be at the bottom")
+			}
+			if tc.from == chess.Black && dst.RGBAAt(3, 0) != evalWhite {
+				tt.Errorf("white part must be at the top")
+			}
+			if dst.RGBAAt(5, 10).A != 0 {
+				tt.Errorf("pixel outside the bar was drawn")
+			}
+		})
+	}
+}
+
+func TestDrawEvalGraph(t *testing.T) {
+	evals := []chess.Eval{{Mate: 1}, {}, {Mate: -1}}
+	img := DrawEvalGraph(evals, 21, 10)
+	if img.RGBAAt(0, 0) != evalWhite {
+		t.Errorf("white mates at the start: top-left pixel must be white")
+	}
+	if img.RGBAAt(20, 9) != evalBlack {
+		t.Errorf("black mates at the end: bottom-right pixel must be black")
+	}
+	if img.RGBAAt(10, 9) != evalWhite || img.RGBAAt(10, 0) != evalBlack {
+		t.Errorf("equal position in the middle must be split")
+	}
+}
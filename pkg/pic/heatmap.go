@@ -0,0 +1,64 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+var (
+	// HeatmapWhite is the tint of the squares controlled by white at the full strength.
+	HeatmapWhite = color.NRGBA{R: 0x20, G: 0x60, B: 0xf0, A: 0xc0}
+	// HeatmapBlack is the tint of the squares controlled by black at the full strength.
+	HeatmapBlack = color.NRGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xc0}
+)
+
+// heatmapSaturation is the difference in the number of attackers at which the tint reaches the full strength.
+const heatmapSaturation = 3
+
+// Control returns the difference between the numbers of white and black pieces attacking (or defending) sq.
+func Control(pos chess.Position, sq chess.Square) int {
+	return len(pos.Attackers(sq, chess.White)) - len(pos.Attackers(sq, chess.Black))
+}
+
+// HeatmapFills returns the fills of the squares controlled by either side: the more attackers a side has
+// over the other side, the stronger the tint.
+func HeatmapFills(pos chess.Position) []Mark {
+	var fills []Mark
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			sq := chess.MustNewSquare(file, rank)
+			control := Control(pos, sq)
+			c := HeatmapWhite
+			if control < 0 {
+				c, control = HeatmapBlack, -control
+			}
+			if control == 0 {
+				continue
+			}
+			if control > heatmapSaturation {
+				control = heatmapSaturation
+			}
+			c.A = uint8(int(c.A) * control / heatmapSaturation)
+			fills = append(fills, Mark{Square: sq, Color: c})
+		}
+	}
+	return fills
+}
+
+// DrawHeatmap tints every square of the board drawn in the rectangle board by the side that controls it
+// (see HeatmapFills). Use it as a Layer so that the pieces are not tinted.
+func DrawHeatmap(dst draw.Image, board image.Rectangle, pos chess.Position, fromPerspective chess.PieceColor) {
+	for _, m := range HeatmapFills(pos) {
+		FillSquare(dst, board, m.Square, fromPerspective, m.Color)
+	}
+}
+
+// HeatmapLayer returns a Layer that draws the heatmap of pos.
+func HeatmapLayer(pos chess.Position, fromPerspective chess.PieceColor) Layer {
+	return func(dst draw.Image, board image.Rectangle) {
+		DrawHeatmap(dst, board, pos, fromPerspective)
+	}
+}
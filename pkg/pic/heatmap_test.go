@@ -0,0 +1,45 @@
+package pic
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestDrawHeatmap(t *testing.T) {
+	st, err := chess.ParseFEN(strings.NewReader("4k3/8/8/8/8/8/8/R3K3 w - - 0 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sq := chess.MustNewSquareFromString
+	tcs := []struct {
+		sq   string
+		want int
+	}{
+		{"a8", 1},  // rook
+		{"d1", 2},  // rook and king
+		{"d8", -1}, // king
+		{"a1", 0},  // a piece does not attack itself
+		{"e4", 0},
+	}
+	for _, tc := range tcs {
+		if got := Control(st.Position, sq(tc.sq)); got != tc.want {
+			t.Errorf("%s: want control %d, got %d", tc.sq, tc.want, got)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, 80, 80))
+	DrawHeatmap(dst, dst.Bounds(), st.Position, chess.White)
+	d1, a8, d8, e4 := dst.RGBAAt(35, 75), dst.RGBAAt(5, 5), dst.RGBAAt(35, 5), dst.RGBAAt(45, 45)
+	if d1.B <= d1.R || d8.R <= d8.B {
+		t.Errorf("white squares must be blue and black squares red, got %v and %v", d1, d8)
+	}
+	if d1.A <= a8.A {
+		t.Errorf("more attackers must give a stronger tint, got %v and %v", d1, a8)
+	}
+	if e4.A != 0 {
+		t.Errorf("neutral square must not be tinted, got %v", e4)
+	}
+}
@@ -0,0 +1,117 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// Highlights are the colors of the square highlights.
+type Highlights struct {
+	// LastMove is blended over the squares of the last move.
+	LastMove color.Color
+	// Check is the color of the glow under the king in check.
+	Check color.Color
+}
+
+// DefaultHighlights are used for collections that are not HighlightCollections.
+var DefaultHighlights = Highlights{
+	LastMove: color.NRGBA{R: 0x9b, G: 0xc7, B: 0x00, A: 0x68},
+	Check:    color.NRGBA{R: 0xf0, G: 0x10, B: 0x10, A: 0xff},
+}
+
+// HighlightCollection is a Collection with its own colors of the highlights (e.g. to match the board).
+type HighlightCollection interface {
+	Collection
+	Highlights() Highlights
+}
+
+// CollectionHighlights returns the highlights of col if it is a HighlightCollection, and DefaultHighlights otherwise.
+func CollectionHighlights(col Collection) Highlights {
+	if hcol, ok := col.(HighlightCollection); ok {
+		return hcol.Highlights()
+	}
+	return DefaultHighlights
+}
+
+type highlightCollection struct {
+	Collection
+	highlights Highlights
+}
+
+func (col highlightCollection) Highlights() Highlights {
+	return col.highlights
+}
+
+func (col highlightCollection) Canvas() draw.Image {
+	if ccol, ok := col.Collection.(CanvasCollection); ok {
+		return ccol.Canvas()
+	}
+	return image.NewRGBA(col.Board(chess.White).Bounds())
+}
+
+// WithHighlights returns col with the colors of the highlights replaced by h.
+// The nil colors of h are taken from the highlights of col.
+func WithHighlights(col Collection, h Highlights) HighlightCollection {
+	base := CollectionHighlights(col)
+	if h.LastMove == nil {
+		h.LastMove = base.LastMove
+	}
+	if h.Check == nil {
+		h.Check = base.Check
+	}
+	if hcol, ok := col.(highlightCollection); ok {
+		col = hcol.Collection
+	}
+	return highlightCollection{Collection: col, highlights: h}
+}
+
+// DrawLastMove blends c over the from and to squares of mov on the board drawn in the rectangle board.
+func DrawLastMove(dst draw.Image, board image.Rectangle, mov chess.Move, fromPerspective chess.PieceColor, c color.Color) {
+	FillSquare(dst, board, mov.From, fromPerspective, c)
+	FillSquare(dst, board, mov.To, fromPerspective, c)
+}
+
+const (
+	// checkCore is the part of the radius of the check glow that has the full color.
+	checkCore = 0.25
+	// checkRadius is the radius of the check glow relative to the square size.
+	checkRadius = 0.65
+)
+
+// DrawCheck blends a round glow of c centered on sq (the square of the king in check) of the board
+// drawn in the rectangle board. The glow fades out towards the edges of the square.
+func DrawCheck(dst draw.Image, board image.Rectangle, sq chess.Square, fromPerspective chess.PieceColor, c color.Color) {
+	r := SquareRect(board, sq, fromPerspective)
+	mask := image.NewAlpha(r)
+	radius := float64(r.Dx()) * checkRadius
+	cx, cy := float64(r.Min.X+r.Max.X)/2, float64(r.Min.Y+r.Max.Y)/2
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			t := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) / radius
+			a := 1.0
+			if t > checkCore {
+				a = math.Max(0, 1-(t-checkCore)/(1-checkCore))
+			}
+			mask.SetAlpha(x, y, color.Alpha{A: uint8(math.Round(a * 0xff))})
+		}
+	}
+	draw.DrawMask(dst, r, image.NewUniform(c), image.Point{}, mask, r.Min, draw.Over)
+}
+
+// HighlightLayer returns a Layer that highlights the last move (if it is not the zero Move)
+// and the king of the side to move in st if it is in check.
+func HighlightLayer(h Highlights, st chess.State, last chess.Move, fromPerspective chess.PieceColor) Layer {
+	return func(dst draw.Image, board image.Rectangle) {
+		if last != (chess.Move{}) {
+			DrawLastMove(dst, board, last, fromPerspective, h.LastMove)
+		}
+		if st.InCheck() {
+			king, _ := st.Position.KingSquare(st.ToMove)
+			DrawCheck(dst, board, king, fromPerspective, h.Check)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestWithHighlights(t *testing.T) {
+	if got := CollectionHighlights(DefaultCollection); got != DefaultHighlights {
+		t.Errorf("want default highlights, got %v", got)
+	}
+
+	check := color.NRGBA{B: 0xff, A: 0xff}
+	col := WithHighlights(DefaultCollection, Highlights{Check: check})
+	want := Highlights{LastMove: DefaultHighlights.LastMove, Check: check}
+	if got := CollectionHighlights(col); got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if _, ok := Collection(col).(CanvasCollection); !ok {
+		t.Errorf("collection must still provide a canvas")
+	}
+
+	last := color.NRGBA{G: 0xff, A: 0xff}
+	col = WithHighlights(col, Highlights{LastMove: last})
+	want.LastMove = last
+	if got := CollectionHighlights(col); got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestHighlightLayer(t *testing.T) {
+	// 1. f3 e5 2. g4 Qh4#
+	st, err := chess.ParseFEN(strings.NewReader("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sq := chess.MustNewSquareFromString
+	last := chess.Move{From: sq("d8"), To: sq("h4")}
+	h := Highlights{LastMove: color.RGBA{G: 0xff, A: 0xff}, Check: color.RGBA{R: 0xff, A: 0xff}}
+
+	for _, from := range []chess.PieceColor{chess.White, chess.Black} {
+		t.Run(from.Name(), func(tt *testing.T) {
+			dst := image.NewRGBA(image.Rect(0, 0, 160, 160))
+			HighlightLayer(h, st, last, from)(dst, dst.Bounds())
+
+			at := func(s string, dx, dy int) color.RGBA {
+				r := SquareRect(dst.Bounds(), sq(s), from)
+				return dst.RGBAAt(r.Min.X+dx, r.Min.Y+dy)
+			}
+			if got := at("d8", 10, 10); got != h.LastMove {
+				tt.Errorf("d8: want %v, got %v", h.LastMove, got)
+			}
+			if got := at("h4", 0, 0); got != h.LastMove {
+				tt.Errorf("h4: want %v, got %v", h.LastMove, got)
+			}
+			if got := at("e1", 10, 10); got != h.Check {
+				tt.Errorf("e1 center: want %v, got %v", h.Check, got)
+			}
+			if got := at("e1", 0, 0); got.R >= 0x80 {
+				tt.Errorf("e1 corner: check glow must fade out, got %v", got)
+			}
+			if got := at("e8", 10, 10); got != (color.RGBA{}) {
+				tt.Errorf("e8: want no highlight, got %v", got)
+			}
+		})
+	}
+}
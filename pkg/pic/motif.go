@@ -0,0 +1,47 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+var (
+	// MotifColor is the color of the arrows from the attackers to the targets of tactical motifs.
+	MotifColor = color.NRGBA{R: 0xc8, G: 0x28, B: 0x28, A: 0xb0}
+	// MotifFrontColor is blended over the piece in front of the target of a pin, a skewer or a discovered attack.
+	MotifFrontColor = color.NRGBA{R: 0xf0, G: 0xc0, B: 0x20, A: 0x90}
+)
+
+// MotifAnnotations returns the marks of tactical motifs: the arrows from the attackers to the targets and the fills
+// of the pieces in front of the targets. An arrow shared by several motifs is included once.
+func MotifAnnotations(motifs []chess.Motif) Annotations {
+	var a Annotations
+	drawn := map[[2]chess.Square]bool{}
+	for _, m := range motifs {
+		switch m.Kind {
+		case chess.AbsolutePin, chess.RelativePin, chess.Skewer, chess.DiscoveredAttack:
+			a.Fills = append(a.Fills, Mark{Square: m.Front, Color: MotifFrontColor})
+		}
+		for _, target := range m.Targets {
+			if drawn[[2]chess.Square{m.Attacker, target}] {
+				continue
+			}
+			drawn[[2]chess.Square{m.Attacker, target}] = true
+			a.Arrows = append(a.Arrows, Arrow{From: m.Attacker, To: target, Color: MotifColor})
+		}
+	}
+	return a
+}
+
+// DrawMotifs marks tactical motifs on the board drawn in the rectangle board (see MotifAnnotations).
+// Unlike Annotations.Layer, the pieces in front of the targets are highlighted over the pieces.
+func DrawMotifs(dst draw.Image, board image.Rectangle, motifs []chess.Motif, fromPerspective chess.PieceColor) {
+	a := MotifAnnotations(motifs)
+	for _, m := range a.Fills {
+		FillSquare(dst, board, m.Square, fromPerspective, m.Color)
+	}
+	a.Draw(dst, board, fromPerspective)
+}
@@ -0,0 +1,35 @@
+package pic
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestDrawMotifs(t *testing.T) {
+	sq := chess.MustNewSquareFromString
+	motifs := []chess.Motif{
+		{Kind: chess.Fork, Attacker: sq("c7"), Targets: []chess.Square{sq("a8"), sq("e8")}},
+		{Kind: chess.HangingPiece, Attacker: sq("c7"), Targets: []chess.Square{sq("a8")}},
+		{Kind: chess.AbsolutePin, Attacker: sq("e1"), Targets: []chess.Square{sq("e8")}, Front: sq("e4")},
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, 160, 160))
+	DrawMotifs(dst, dst.Bounds(), motifs, chess.White)
+
+	// a single arrow over a transparent canvas keeps the color of the arrow
+	want := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	draw.Draw(want, want.Bounds(), image.NewUniform(MotifColor), image.Point{}, draw.Over)
+	// the middle of the long leg of the knight arrow from c7 to a8
+	if got := dst.RGBAAt(30, 30); got != want.RGBAAt(0, 0) {
+		t.Errorf("shared arrow must be drawn once: want %v, got %v", want.RGBAAt(0, 0), got)
+	}
+	// a corner of e4 is not covered by the arrow
+	if dst.RGBAAt(81, 81).A == 0 {
+		t.Errorf("pinned piece must be highlighted")
+	}
+	if dst.RGBAAt(150, 150).A != 0 {
+		t.Errorf("h1 must not be drawn over")
+	}
+}
@@ -0,0 +1,42 @@
+// Package pictest provides the image files for the tests of the packages that load collections.
+package pictest
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"path"
+	"testing/fstest"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// PNG returns a transparent PNG image of w x h pixels.
+func PNG(w, h int) []byte {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// CollectionFS returns a file system with the images of a collection with the board of size bs (no boards if it is 0)
+// and the pieces of size ps under dir. The sizes of the files in sizes (relative to dir) override them.
+func CollectionFS(dir string, bs, ps int, sizes map[string]int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	add := func(name string, size int) {
+		if s, ok := sizes[name]; ok {
+			size = s
+		}
+		fsys[path.Join(dir, name)] = &fstest.MapFile{Data: PNG(size, size)}
+	}
+	for color := chess.White; color <= chess.Black; color++ {
+		if bs > 0 {
+			add("board_"+color.Name()+".png", bs)
+		}
+		for kind := chess.Pawn; kind <= chess.King; kind++ {
+			add(path.Join(color.Name(), kind.Name()+".png"), ps)
+		}
+	}
+	return fsys
+}
@@ -0,0 +1,246 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/vector"
+)
+
+// point is a point on the image with sub-pixel precision.
+type point [2]float64
+
+// shape is a set of closed polygons. The polygons are filled by the non-zero rule, so overlapping polygons
+// of the same orientation are merged (a translucent color is blended once) and a polygon of the opposite
+// orientation cuts a hole.
+type shape [][]point
+
+// fillShape blends c over dst inside s. The edges are anti-aliased.
+func fillShape(dst draw.Image, s shape, c color.Color) {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, poly := range s {
+		for _, pt := range poly {
+			minX, maxX = math.Min(minX, pt[0]), math.Max(maxX, pt[0])
+			minY, maxY = math.Min(minY, pt[1]), math.Max(maxY, pt[1])
+		}
+	}
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).
+		Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	for _, poly := range s {
+		if len(poly) < 3 {
+			continue
+		}
+		// the rasterizer has its own origin
+		at := func(pt point) (float32, float32) {
+			return float32(pt[0] - float64(bounds.Min.X)), float32(pt[1] - float64(bounds.Min.Y))
+		}
+		z.MoveTo(at(poly[0]))
+		for _, pt := range poly[1:] {
+			z.LineTo(at(pt))
+		}
+		z.ClosePath()
+	}
+	z.Draw(dst, bounds, image.NewUniform(c), image.Point{})
+}
+
+// circleSegments is the number of sides of the polygons approximating circles.
+const circleSegments = 64
+
+// circle returns a polygon approximating the circle. If reverse is set, the orientation is opposite
+// (to cut a hole in another shape).
+func circle(center point, radius float64, reverse bool) []point {
+	poly := make([]point, circleSegments)
+	for i := range poly {
+		a := 2 * math.Pi * float64(i) / circleSegments
+		if reverse {
+			a = -a
+		}
+		poly[i] = point{center[0] + radius*math.Cos(a), center[1] + radius*math.Sin(a)}
+	}
+	return poly
+}
+
+// area returns the signed area of the polygon, positive if it goes clockwise on the image (with y down).
+func area(poly []point) float64 {
+	a := 0.0
+	for i, p := range poly {
+		q := poly[(i+1)%len(poly)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return a / 2
+}
+
+// clockwise returns poly going clockwise on the image, so that polygons merge when filled together.
+func clockwise(poly []point) []point {
+	if area(poly) >= 0 {
+		return poly
+	}
+	rev := make([]point, len(poly))
+	for i, p := range poly {
+		rev[len(poly)-1-i] = p
+	}
+	return rev
+}
+
+// strokeSegments is the number of sides of the polygons approximating the round joins of strokes.
+const strokeSegments = 16
+
+// stroke returns the outline of the polylines of the width with round joins and caps. If closed is set,
+// the last point of every polyline is joined with the first one.
+func stroke(lines [][]point, width float64, closed bool) shape {
+	var s shape
+	hw := width / 2
+	for _, line := range lines {
+		n := len(line) - 1
+		if closed {
+			n = len(line)
+		}
+		for i := 0; i < n; i++ {
+			p, q := line[i], line[(i+1)%len(line)]
+			dx, dy := q[0]-p[0], q[1]-p[1]
+			l := math.Hypot(dx, dy)
+			if l == 0 {
+				continue
+			}
+			nx, ny := -dy/l*hw, dx/l*hw
+			s = append(s, clockwise([]point{
+				{p[0] + nx, p[1] + ny}, {q[0] + nx, q[1] + ny},
+				{q[0] - nx, q[1] - ny}, {p[0] - nx, p[1] - ny},
+			}))
+		}
+		for _, p := range line {
+			join := make([]point, strokeSegments)
+			for i := range join {
+				a := 2 * math.Pi * float64(i) / strokeSegments
+				join[i] = point{p[0] + hw*math.Cos(a), p[1] + hw*math.Sin(a)}
+			}
+			s = append(s, join)
+		}
+	}
+	return s
+}
+
+// curveSegments is the number of lines approximating a Bézier curve of a path.
+const curveSegments = 12
+
+// parsePath parses a path in a subset of the SVG path syntax: the absolute commands M, L, Q, C and Z
+// with the coordinates separated by commas or spaces (e.g. "M 10,10 L 20,10 Q 25,15 20,20 Z").
+// Every subpath is a polygon.
+func parsePath(d string) (shape, error) {
+	var s shape
+	var poly []point
+	fields := strings.FieldsFunc(d, func(r rune) bool { return r == ' ' || r == ',' || r == '\n' || r == '\t' })
+	cmd := ""
+	args := func(n int) ([]float64, error) {
+		if len(fields) < n {
+			return nil, fmt.Errorf("%s: %d numbers are required", cmd, n)
+		}
+		vs := make([]float64, n)
+		for i := range vs {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", cmd, err)
+			}
+			vs[i] = v
+		}
+		fields = fields[n:]
+		return vs, nil
+	}
+	flush := func() {
+		if len(poly) > 0 {
+			s = append(s, poly)
+		}
+		poly = nil
+	}
+	for len(fields) > 0 {
+		if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+			cmd, fields = fields[0], fields[1:]
+		} else if cmd == "" || cmd == "Z" {
+			return nil, fmt.Errorf("unexpected number %q", fields[0])
+		}
+		if cmd != "M" && cmd != "Z" && len(poly) == 0 {
+			return nil, fmt.Errorf("%s: no current point", cmd)
+		}
+		switch cmd {
+		case "M":
+			vs, err := args(2)
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			poly = []point{{vs[0], vs[1]}}
+			// the following pairs are lines, as in SVG
+			cmd = "L"
+		case "L":
+			vs, err := args(2)
+			if err != nil {
+				return nil, err
+			}
+			poly = append(poly, point{vs[0], vs[1]})
+		case "Q", "C":
+			n := 4
+			if cmd == "C" {
+				n = 6
+			}
+			vs, err := args(n)
+			if err != nil {
+				return nil, err
+			}
+			pts := []point{poly[len(poly)-1]}
+			for i := 0; i < n; i += 2 {
+				pts = append(pts, point{vs[i], vs[i+1]})
+			}
+			for i := 1; i <= curveSegments; i++ {
+				poly = append(poly, bezier(pts, float64(i)/curveSegments))
+			}
+		case "Z":
+			flush()
+		default:
+			return nil, fmt.Errorf("unknown command %q", cmd)
+		}
+	}
+	flush()
+	return s, nil
+}
+
+// mustParsePath is like parsePath but panics if d is invalid.
+func mustParsePath(d string) shape {
+	s, err := parsePath(d)
+	if err != nil {
+		panic(fmt.Sprintf("path %q: %s", d, err))
+	}
+	return s
+}
+
+// bezier returns the point of the Bézier curve of the control points pts at t (de Casteljau's algorithm).
+func bezier(pts []point, t float64) point {
+	pts = append([]point(nil), pts...)
+	for n := len(pts) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			pts[i] = point{pts[i][0] + (pts[i+1][0]-pts[i][0])*t, pts[i][1] + (pts[i+1][1]-pts[i][1])*t}
+		}
+	}
+	return pts[0]
+}
+
+// transform returns s scaled by k and moved by d.
+func (s shape) transform(k float64, d point) shape {
+	res := make(shape, len(s))
+	for i, poly := range s {
+		res[i] = make([]point, len(poly))
+		for j, p := range poly {
+			res[i][j] = point{p[0]*k + d[0], p[1]*k + d[1]}
+		}
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"reflect"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+	xdraw "golang.org/x/image/draw"
+)
+
+// MinBoardSize is the smallest size of the board of a scaled collection.
+const MinBoardSize = 64
+
+type scaledCollection struct {
+	collection
+	highlights Highlights
+}
+
+func (col scaledCollection) Highlights() Highlights {
+	return col.highlights
+}
+
+type scaleKey struct {
+	col  Collection
+	size int
+}
+
+// scaledCollections are the recently scaled collections, at most 256 MB of them.
+var scaledCollections = newImageCache(256 << 20)
+
+// ScaleCollection returns col with the board scaled to size pixels (rounded down to a multiple of 8,
+// so that the squares are aligned) and the pieces scaled by the same factor. The images are resampled
+// with the Catmull-Rom filter. The pieces must still fit in the squares after scaling.
+// The recently scaled collections are cached, so scaling the same collection to the same size again is cheap.
+// The highlights of col are kept, and the boards of themes (see Theme.Apply) and the vector pieces
+// (see VectorPieces) are drawn at the size.
+func ScaleCollection(col Collection, size int) (Collection, error) {
+	size -= size % 8
+	if size < MinBoardSize {
+		return nil, fmt.Errorf("board size %d is too small (at least %d)", size, MinBoardSize)
+	}
+	if col.Board(chess.White).Bounds().Dx() == size && col.Board(chess.Black).Bounds().Dx() == size {
+		return col, nil
+	}
+	if tcol, ok := col.(themeCollection); ok {
+		// the board of a theme is drawn at the size instead of resampled
+		scaled, err := ScaleCollection(tcol.Collection, size)
+		if err != nil {
+			return nil, err
+		}
+		return tcol.theme.Apply(scaled), nil
+	}
+	if vcol, ok := col.(vectorCollection); ok {
+		// the vector pieces are drawn at the size instead of resampled
+		scaled, err := ScaleCollection(vcol.Collection, size)
+		if err != nil {
+			return nil, err
+		}
+		return VectorPieces(scaled), nil
+	}
+
+	// collections that cannot be map keys are scaled every time
+	if !reflect.TypeOf(col).Comparable() {
+		scaled, _, err := scaleCollection(col, size)
+		return scaled, err
+	}
+	scaled, err := scaledCollections.get(scaleKey{col: col, size: size}, func() (interface{}, int, error) {
+		return scaleCollection(col, size)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scaled.(Collection), nil
+}
+
+// scaleCollection resamples the images of col (see ScaleCollection) and returns the result with its size in bytes.
+func scaleCollection(col Collection, size int) (Collection, int, error) {
+	scaled := scaledCollection{highlights: CollectionHighlights(col)}
+	// the board images of both perspectives may differ in size, the pieces are scaled by the white one
+	bs := col.Board(chess.White).Bounds().Dx()
+	ps0 := col.Piece(chess.Piece{Color: chess.White, Kind: chess.Pawn}).Bounds().Dx()
+	ps := int(math.Round(float64(ps0) * float64(size) / float64(bs)))
+	if ps0*8 <= bs && ps*8 > size {
+		// the pieces fit before scaling, the rounding must not break it
+		ps = size / 8
+	}
+	if err := checkPieceSize(ps, size); err != nil {
+		return nil, 0, err
+	}
+	for color := chess.White; color <= chess.Black; color++ {
+		scaled.images[color] = scaleImage(col.Board(color), size)
+		for kind := chess.Pawn; kind <= chess.King; kind++ {
+			scaled.images[2+int(color)*6+int(kind-chess.Pawn)] = scaleImage(col.Piece(chess.Piece{Color: color, Kind: kind}), ps)
+		}
+	}
+
+	bytes := 0
+	for _, img := range scaled.images {
+		if img != nil {
+			bytes += imageBytes(img)
+		}
+	}
+	return scaled, bytes, nil
+}
+
+// scaleImage resamples the square image img to size x size pixels.
+func scaleImage(img Image, size int) Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
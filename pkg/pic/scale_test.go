@@ -0,0 +1,125 @@
+package pic
+
+import (
+	"image"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// testCollection returns a collection with the board of size bs and the pieces of size ps.
+func testCollection(bs, ps int) collection {
+	col := collection{}
+	for i := range col.images {
+		size := ps
+		if i < 2 {
+			size = bs
+		}
+		col.images[i] = image.NewRGBA(image.Rect(0, 0, size, size))
+	}
+	return col
+}
+
+func TestScaleCollection(t *testing.T) {
+	tcs := []struct {
+		name      string
+		col       Collection
+		size      int
+		wantBoard int
+		wantPiece int
+		wantErr   bool
+	}{
+		{
+			name:      "default",
+			col:       DefaultCollection,
+			size:      800,
+			wantBoard: 800,
+			wantPiece: 100,
+		},
+		{
+			name:      "rounded",
+			col:       testCollection(80, 10),
+			size:      103,
+			wantBoard: 96,
+			wantPiece: 12,
+		},
+		{
+			name:      "pieces still fit",
+			col:       testCollection(88, 11),
+			size:      64,
+			wantBoard: 64,
+			wantPiece: 8,
+		},
+		{
+			name:    "too small",
+			col:     testCollection(80, 10),
+			size:    60,
+			wantErr: true,
+		},
+		{
+			name:    "pieces too big",
+			col:     testCollection(80, 12),
+			size:    160,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			col, err := ScaleCollection(tc.col, tc.size)
+			if tc.wantErr {
+				if err == nil {
+					tt.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			for color := chess.White; color <= chess.Black; color++ {
+				if got := col.Board(color).Bounds().Dx(); got != tc.wantBoard {
+					tt.Errorf("board: want %d, got %d", tc.wantBoard, got)
+				}
+				for kind := chess.Pawn; kind <= chess.King; kind++ {
+					if got := col.Piece(chess.Piece{Color: color, Kind: kind}).Bounds().Dx(); got != tc.wantPiece {
+						tt.Errorf("%s %s: want %d, got %d", color.Name(), kind.Name(), tc.wantPiece, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScaleCollectionCache(t *testing.T) {
+	a, err := ScaleCollection(DefaultCollection, 256)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := ScaleCollection(DefaultCollection, 256)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a.Board(chess.White) != b.Board(chess.White) {
+		t.Errorf("scaled collection must be cached")
+	}
+	if CollectionHighlights(a) != CollectionHighlights(DefaultCollection) {
+		t.Errorf("highlights must be kept")
+	}
+}
+
+func TestScaleCollectionCacheBounded(t *testing.T) {
+	defer scaledCollections.setMax(scaledCollections.maxBytes)
+	// a collection of 160px boards and 20px pieces takes 2*160*160*4 + 12*20*20*4 bytes, 4 of them fit
+	scaledCollections.setMax(4 * (2*160*160*4 + 12*20*20*4))
+	col := testCollection(80, 10)
+	for size := 80; size <= 160; size += 8 {
+		if _, err := ScaleCollection(col, size); err != nil {
+			t.Fatalf("%d: unexpected error: %s", size, err)
+		}
+		if _, bytes := scaledCollections.size(); bytes > scaledCollections.maxBytes {
+			t.Fatalf("%d: cache must stay bounded, got %d bytes", size, bytes)
+		}
+	}
+	if n, _ := scaledCollections.size(); n < 4 {
+		t.Errorf("want at least 4 cached collections, got %d", n)
+	}
+}
@@ -0,0 +1,115 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+	"unicode"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// SpriteLayout describes how the pieces are placed on a sprite sheet.
+type SpriteLayout struct {
+	// Columns is the number of the cells in a row of the sheet: 6 for a sheet of 2 rows or 2 for a sheet of 6 rows.
+	// If it is 0, it is chosen by the shape of the sheet.
+	Columns int
+	// Order is the pieces in the cells, row by row from the top-left one. DefaultSpriteOrder is used if it is nil.
+	Order []chess.Piece
+	// Board is the collection the boards (and the highlights) are taken from, DefaultCollection if it is nil.
+	// The pieces must fit in its squares (see OpenPieces).
+	Board Collection
+}
+
+// DefaultSpriteOrder is the most common order of the pieces on sprite sheets: white king, queen, bishop, knight,
+// rook and pawn in the first row and the black pieces in the same order in the second one.
+var DefaultSpriteOrder = MustParseSpriteOrder("KQBNRPkqbnrp")
+
+// ParseSpriteOrder parses the order of the pieces on a sprite sheet from their FEN letters, e.g. "KQBNRPkqbnrp".
+// Every piece must be present once.
+func ParseSpriteOrder(s string) ([]chess.Piece, error) {
+	var order []chess.Piece
+	seen := map[chess.Piece]bool{}
+	for _, c := range s {
+		i := strings.IndexRune("PRNBQK", unicode.ToUpper(c))
+		if i < 0 {
+			return nil, fmt.Errorf("invalid sprite order %q: unknown piece %q", s, c)
+		}
+		p := chess.Piece{Kind: chess.Pawn + chess.PieceKind(i), Color: chess.White}
+		if unicode.IsLower(c) {
+			p.Color = chess.Black
+		}
+		if seen[p] {
+			return nil, fmt.Errorf("invalid sprite order %q: duplicate piece %q", s, c)
+		}
+		seen[p] = true
+		order = append(order, p)
+	}
+	if len(order) != 12 {
+		return nil, fmt.Errorf("invalid sprite order %q: 12 pieces are required", s)
+	}
+	return order, nil
+}
+
+// MustParseSpriteOrder is like ParseSpriteOrder but panics if s is invalid.
+func MustParseSpriteOrder(s string) []chess.Piece {
+	order, err := ParseSpriteOrder(s)
+	if err != nil {
+		panic(err)
+	}
+	return order
+}
+
+// OpenSpriteCollection slices the sprite sheet img into the images of the pieces and returns them with the boards
+// of layout.Board. The sheet must consist of 12 square cells.
+func OpenSpriteCollection(img image.Image, layout SpriteLayout) (Collection, error) {
+	size := img.Bounds().Size()
+	cols := layout.Columns
+	if cols == 0 {
+		cols = 2
+		if size.X > size.Y {
+			cols = 6
+		}
+	}
+	if cols != 2 && cols != 6 {
+		return nil, fmt.Errorf("sprite sheet must have 2 or 6 columns, not %d", cols)
+	}
+	rows := 12 / cols
+	ps := size.X / cols
+	if size.X%cols != 0 || size.Y != ps*rows {
+		return nil, fmt.Errorf("sprite sheet of %dx%d cannot be divided into %dx%d square cells", size.X, size.Y, cols, rows)
+	}
+
+	order := layout.Order
+	if order == nil {
+		order = DefaultSpriteOrder
+	}
+	if len(order) != 12 {
+		return nil, fmt.Errorf("sprite order must have 12 pieces, not %d", len(order))
+	}
+
+	col := collection{}
+	for i, p := range order {
+		if p.Kind < chess.Pawn || p.Kind > chess.King || p.Color > chess.Black {
+			return nil, fmt.Errorf("invalid piece %v in sprite order", p)
+		}
+		// the cells are copied to images at the origin, as the images of the other collections are
+		cell := image.Rect(0, 0, ps, ps).Add(img.Bounds().Min).Add(image.Pt(i%cols*ps, i/cols*ps))
+		piece := image.NewRGBA(image.Rect(0, 0, ps, ps))
+		draw.Draw(piece, piece.Bounds(), img, cell.Min, draw.Src)
+		col.images[2+int(p.Color)*6+int(p.Kind-chess.Pawn)] = piece
+	}
+	for i, piece := range col.images[2:] {
+		if piece == nil {
+			p := chess.Piece{Color: chess.PieceColor(i / 6), Kind: chess.Pawn + chess.PieceKind(i%6)}
+			return nil, fmt.Errorf("no %s %s in sprite order", p.Color.Name(), p.Kind.Name())
+		}
+	}
+
+	board := layout.Board
+	if board == nil {
+		board = DefaultCollection
+	}
+	return withBoard(col, board)
+}
@@ -0,0 +1,128 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// testSheet returns a sprite sheet of cols x (12 / cols) cells of size ps. The cells are filled with the gray
+// of their index.
+func testSheet(cols, ps int) *image.RGBA {
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*ps, 12/cols*ps))
+	for i := 0; i < 12; i++ {
+		for y := 0; y < ps; y++ {
+			for x := 0; x < ps; x++ {
+				sheet.Set(i%cols*ps+x, i/cols*ps+y, color.Gray{Y: uint8(i)})
+			}
+		}
+	}
+	return sheet
+}
+
+// shifted returns img with the bounds moved by d.
+func shifted(img *image.RGBA, d image.Point) *image.RGBA {
+	return &image.RGBA{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect.Add(d)}
+}
+
+func TestOpenSpriteCollection(t *testing.T) {
+	order := MustParseSpriteOrder
+	tcs := []struct {
+		name   string
+		sheet  image.Image
+		layout SpriteLayout
+		// want are the indices of the cells of the pieces
+		want    map[chess.Piece]uint8
+		wantErr bool
+	}{
+		{
+			name:  "wide",
+			sheet: testSheet(6, 20),
+			want: map[chess.Piece]uint8{
+				{Kind: chess.King, Color: chess.White}:   0,
+				{Kind: chess.Pawn, Color: chess.White}:   5,
+				{Kind: chess.Queen, Color: chess.Black}:  7,
+				{Kind: chess.Knight, Color: chess.Black}: 9,
+			},
+		},
+		{
+			name:   "tall",
+			sheet:  testSheet(2, 20),
+			layout: SpriteLayout{Order: order("PpNnBbRrQqKk")},
+			want: map[chess.Piece]uint8{
+				{Kind: chess.Pawn, Color: chess.White}:  0,
+				{Kind: chess.Pawn, Color: chess.Black}:  1,
+				{Kind: chess.Rook, Color: chess.Black}:  7,
+				{Kind: chess.King, Color: chess.White}:  10,
+				{Kind: chess.Queen, Color: chess.Black}: 9,
+			},
+		},
+		{
+			name:  "offset sheet",
+			sheet: shifted(testSheet(6, 20), image.Pt(5, 7)),
+			want: map[chess.Piece]uint8{
+				{Kind: chess.King, Color: chess.White}: 0,
+				{Kind: chess.Pawn, Color: chess.Black}: 11,
+			},
+		},
+		{
+			name:    "not square cells",
+			sheet:   image.NewRGBA(image.Rect(0, 0, 120, 30)),
+			wantErr: true,
+		},
+		{
+			name:    "wrong columns",
+			sheet:   testSheet(6, 20),
+			layout:  SpriteLayout{Columns: 4},
+			wantErr: true,
+		},
+		{
+			name:    "missing piece",
+			sheet:   testSheet(6, 20),
+			layout:  SpriteLayout{Order: append(order("KQBNRPkqbnrp")[:11:11], chess.Piece{Kind: chess.King})},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			col, err := OpenSpriteCollection(tc.sheet, tc.layout)
+			if tc.wantErr {
+				if err == nil {
+					tt.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if got := col.Board(chess.White); got != DefaultCollection.Board(chess.White) {
+				tt.Errorf("board of DefaultCollection is expected")
+			}
+			for p, want := range tc.want {
+				img := col.Piece(p)
+				if img.Bounds() != image.Rect(0, 0, 20, 20) {
+					tt.Errorf("%s %s: want 20x20 image at the origin, got %v", p.Color.Name(), p.Kind.Name(), img.Bounds())
+					continue
+				}
+				if got := color.GrayModel.Convert(img.At(10, 10)).(color.Gray).Y; got != want {
+					tt.Errorf("%s %s: want cell %d, got %d", p.Color.Name(), p.Kind.Name(), want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSpriteOrder(t *testing.T) {
+	for _, s := range []string{"KQBNRPkqbnrp", "pnbrqkPNBRQK"} {
+		if _, err := ParseSpriteOrder(s); err != nil {
+			t.Errorf("%q: unexpected error: %s", s, err)
+		}
+	}
+	for _, s := range []string{"", "KQBNRPkqbnr", "KQBNRPkqbnrr", "KQBNRPkqbnrx"} {
+		if _, err := ParseSpriteOrder(s); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package pic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// SVGDiagram describes an SVG document of a position.
+type SVGDiagram struct {
+	// State is the position to draw. Only the position is used unless Highlight is set.
+	State chess.State
+	From  chess.PieceColor
+
+	Coordinates Coordinates
+	// Highlight marks the squares of LastMove and the king of the side to move if it is in check
+	// (see HighlightLayer).
+	Highlight bool
+	LastMove  chess.Move
+	// Heatmap tints the squares by the side that controls them (see HeatmapFills).
+	Heatmap     bool
+	Annotations Annotations
+	// Motifs are marked over the pieces as by DrawMotifs.
+	Motifs []chess.Motif
+
+	// PieceURL returns the URL of the image of a piece (e.g. "pieces/white-king.svg", see PieceURLTemplate).
+	// If it is nil, the vector pieces (see VectorPieces) are written as paths and the images of other
+	// collections are embedded as PNG data.
+	PieceURL func(chess.Piece) string
+}
+
+// PieceURLTemplate returns a function for SVGDiagram.PieceURL that replaces "{color}" ("white" or "black"),
+// "{kind}" ("pawn", "knight", ..., "king") and "{code}" (e.g. "wK" or "bN", as the files of many piece sets
+// are named) in tmpl with the values of the piece.
+func PieceURLTemplate(tmpl string) (func(chess.Piece) string, error) {
+	if !strings.Contains(tmpl, "{color}") && !strings.Contains(tmpl, "{kind}") && !strings.Contains(tmpl, "{code}") {
+		return nil, fmt.Errorf("piece URL template %q has none of {color}, {kind} and {code}", tmpl)
+	}
+	return func(p chess.Piece) string {
+		code := p.Color.String() + string("PRNBQK"[p.Kind-chess.Pawn])
+		return strings.NewReplacer("{color}", p.Color.Name(), "{kind}", p.Kind.Name(), "{code}", code).Replace(tmpl)
+	}, nil
+}
+
+// WriteSVG writes an SVG document of the diagram to w. The document has the same size and layout as the images
+// drawn by DrawPosition, but the squares are filled with the colors of the centers of a1 and h1 of the board image
+// (or the colors of the theme), so textures of the board are lost.
+func WriteSVG(w io.Writer, col Collection, d SVGDiagram) error {
+	bw := bufio.NewWriter(w)
+	e := &svgWriter{w: bw}
+
+	boardImg := col.Board(d.From)
+	br := boardImg.Bounds()
+	br = br.Sub(br.Min)
+	margin := 0
+	if d.Coordinates == MarginCoordinates {
+		margin = CoordinatesMargin(br)
+	}
+	br = br.Add(image.Pt(margin, margin))
+	size := br.Inset(-margin).Size()
+	ss := br.Dx() / 8
+
+	e.printf(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" `+
+		`width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size.X, size.Y, size.X, size.Y)
+
+	// the pieces are defined once and used on every square
+	e.printf("<defs>\n")
+	defined := map[chess.Piece]bool{}
+	ps := col.Piece(chess.Piece{Color: chess.White, Kind: chess.Pawn}).Bounds().Dx()
+	vector := d.PieceURL == nil && hasVectorPieces(col)
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			p := d.State.Position.Get(chess.MustNewSquare(file, rank))
+			if p.Kind == chess.None || defined[p] {
+				continue
+			}
+			defined[p] = true
+			if vector {
+				e.vectorPiece(p, ps)
+				continue
+			}
+			href := ""
+			if d.PieceURL != nil {
+				href = d.PieceURL(p)
+			} else {
+				buf := &bytes.Buffer{}
+				if err := png.Encode(buf, col.Piece(p)); err != nil {
+					return err
+				}
+				href = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+			}
+			e.printf(`<image id="%s" width="%d" height="%d" xlink:href="%s"/>`+"\n", svgPieceID(p), ps, ps, html.EscapeString(href))
+		}
+	}
+	e.printf("</defs>\n")
+
+	if margin > 0 {
+		e.printf(`<rect width="%d" height="%d" %s/>`+"\n", size.X, size.Y, svgFill(MarginColor))
+	}
+
+	// a1 is a dark square and h1 is a light one
+	colorAt := func(sq chess.Square) color.Color {
+		r := SquareRect(boardImg.Bounds(), sq, d.From)
+		center := r.Min.Add(r.Max).Div(2)
+		return boardImg.At(center.X, center.Y)
+	}
+	dark, light := colorAt(chess.MustNewSquare(0, 0)), colorAt(chess.MustNewSquare(7, 0))
+	if tcol, ok := col.(themeCollection); ok {
+		// the center of a textured square is not its color
+		dark, light = tcol.theme.Dark, tcol.theme.Light
+	}
+	e.rect(br, light)
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			if (file+rank)%2 == 0 {
+				e.rect(SquareRect(br, chess.MustNewSquare(file, rank), d.From), dark)
+			}
+		}
+	}
+
+	files, ranks := coordinateLabels(d.From)
+	switch d.Coordinates {
+	case InsideCoordinates:
+		pad := ss / 16
+		fontSize := float64(ss) * 3 / 10
+		for i := 0; i < 8; i++ {
+			sq := squareAt(br, image.Pt(br.Min.X+i*ss, br.Max.Y-1), d.From)
+			c := light
+			if (sq.File()+sq.Rank())%2 != 0 {
+				c = dark
+			}
+			e.text(point{float64(br.Min.X + (i+1)*ss - pad), float64(br.Max.Y - 2*pad)}, files[i], fontSize, "end", "auto", c)
+
+			sq = squareAt(br, image.Pt(br.Min.X, br.Min.Y+i*ss), d.From)
+			c = light
+			if (sq.File()+sq.Rank())%2 != 0 {
+				c = dark
+			}
+			e.text(point{float64(br.Min.X + pad), float64(br.Min.Y + i*ss + pad)}, ranks[i], fontSize, "start", "hanging", c)
+		}
+	case MarginCoordinates:
+		fontSize := float64(margin) * 3 / 4
+		for i := 0; i < 8; i++ {
+			x := float64(br.Min.X + i*ss + ss/2)
+			e.text(point{x, float64(margin) / 2}, files[i], fontSize, "middle", "central", MarginTextColor)
+			e.text(point{x, float64(br.Max.Y) + float64(margin)/2}, files[i], fontSize, "middle", "central", MarginTextColor)
+
+			y := float64(br.Min.Y + i*ss + ss/2)
+			e.text(point{float64(margin) / 2, y}, ranks[i], fontSize, "middle", "central", MarginTextColor)
+			e.text(point{float64(br.Max.X) + float64(margin)/2, y}, ranks[i], fontSize, "middle", "central", MarginTextColor)
+		}
+	}
+
+	// the same order of the layers as in the raster images
+	if d.Highlight {
+		h := CollectionHighlights(col)
+		if d.LastMove != (chess.Move{}) {
+			e.rect(SquareRect(br, d.LastMove.From, d.From), h.LastMove)
+			e.rect(SquareRect(br, d.LastMove.To, d.From), h.LastMove)
+		}
+		if d.State.InCheck() {
+			king, _ := d.State.Position.KingSquare(d.State.ToMove)
+			e.check(SquareRect(br, king, d.From), h.Check)
+		}
+	}
+	if d.Heatmap {
+		for _, m := range HeatmapFills(d.State.Position) {
+			e.rect(SquareRect(br, m.Square, d.From), m.Color)
+		}
+	}
+	for _, m := range d.Annotations.Fills {
+		e.rect(SquareRect(br, m.Square, d.From), m.Color)
+	}
+
+	off := (ss - ps) / 2
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			sq := chess.MustNewSquare(file, rank)
+			p := d.State.Position.Get(sq)
+			if p.Kind == chess.None {
+				continue
+			}
+			min := SquareRect(br, sq, d.From).Min.Add(image.Pt(off, off))
+			e.printf(`<use xlink:href="#%s" x="%d" y="%d"/>`+"\n", svgPieceID(p), min.X, min.Y)
+		}
+	}
+
+	// the motifs and the annotations are drawn in the same order as by HandleFEN
+	motifs := MotifAnnotations(d.Motifs)
+	for _, m := range motifs.Fills {
+		e.rect(SquareRect(br, m.Square, d.From), m.Color)
+	}
+	for _, a := range motifs.Arrows {
+		e.arrow(br, a, d.From)
+	}
+	for _, m := range d.Annotations.Circles {
+		r := SquareRect(br, m.Square, d.From)
+		width := float64(r.Dx()) / 14
+		e.printf(`<circle cx="%s" cy="%s" r="%s" fill="none" %s stroke-width="%s"/>`+"\n",
+			svgNum(float64(r.Min.X+r.Max.X)/2), svgNum(float64(r.Min.Y+r.Max.Y)/2), svgNum(float64(r.Dx())/2-width),
+			svgPaint("stroke", m.Color), svgNum(width))
+	}
+	for _, a := range d.Annotations.Arrows {
+		e.arrow(br, a, d.From)
+	}
+	for _, b := range d.Annotations.Badges {
+		r := SquareRect(br, b.Square, d.From)
+		radius := float64(r.Dx()) / 5
+		center := point{float64(r.Max.X) - radius, float64(r.Min.Y) + radius}
+		e.printf(`<circle cx="%s" cy="%s" r="%s" %s/>`+"\n", svgNum(center[0]), svgNum(center[1]), svgNum(radius), svgFill(b.Color))
+		e.text(center, b.Text, badgeTextSize(radius, b.Text), "middle", "central", badgeTextColor(b.Color))
+	}
+
+	e.printf("</svg>\n")
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+func svgPieceID(p chess.Piece) string {
+	return p.Color.Name() + "-" + p.Kind.Name()
+}
+
+// svgPaint returns the attributes of c for the paint attr ("fill", "stroke" or "stop-color") and its opacity.
+func svgPaint(attr string, c color.Color) string {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	s := fmt.Sprintf(`%s="#%02x%02x%02x"`, attr, nc.R, nc.G, nc.B)
+	if nc.A != 0xff {
+		s += fmt.Sprintf(` %s-opacity="%.3g"`, strings.TrimSuffix(attr, "-color"), float64(nc.A)/0xff)
+	}
+	return s
+}
+
+// svgNum formats a coordinate with the precision of a hundredth of a pixel.
+func svgNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+func svgFill(c color.Color) string {
+	return svgPaint("fill", c)
+}
+
+// svgWriter writes the elements of an SVG document and keeps the first error.
+type svgWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *svgWriter) printf(format string, a ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, a...)
+}
+
+func (e *svgWriter) rect(r image.Rectangle, c color.Color) {
+	e.printf(`<rect x="%d" y="%d" width="%d" height="%d" %s/>`+"\n", r.Min.X, r.Min.Y, r.Dx(), r.Dy(), svgFill(c))
+}
+
+// text writes s with the anchor ("start", "middle" or "end") and the baseline at pt.
+func (e *svgWriter) text(pt point, s string, size float64, anchor, baseline string, c color.Color) {
+	e.printf(`<text x="%s" y="%s" font-family="sans-serif" font-weight="500" font-size="%s" `+
+		`text-anchor="%s" dominant-baseline="%s" %s>%s</text>`+"\n",
+		svgNum(pt[0]), svgNum(pt[1]), svgNum(size), anchor, baseline, svgFill(c), html.EscapeString(s))
+}
+
+// shape writes s as a path filled by the non-zero rule.
+func (e *svgWriter) shape(s shape, c color.Color) {
+	d := &strings.Builder{}
+	for _, poly := range s {
+		for i, pt := range poly {
+			cmd := "L"
+			if i == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(d, "%s%s %s ", cmd, svgNum(pt[0]), svgNum(pt[1]))
+		}
+		d.WriteString("Z ")
+	}
+	e.printf(`<path d="%s" fill-rule="nonzero" %s/>`+"\n", strings.TrimSpace(d.String()), svgFill(c))
+}
+
+// arrow writes the arrow a on the board drawn in the rectangle board (see DrawArrow).
+func (e *svgWriter) arrow(board image.Rectangle, a Arrow, fromPerspective chess.PieceColor) {
+	if a.From == a.To {
+		return
+	}
+	e.shape(arrowShape(arrowPoints(board, a.From, a.To, fromPerspective), float64(board.Dx()/8)), a.Color)
+}
+
+// vectorPiece writes the definition of the vector piece p of size x size pixels (see drawVectorPiece).
+func (e *svgWriter) vectorPiece(p chess.Piece, size int) {
+	c := vectorColors[p.Color]
+	e.printf(`<g id="%s" transform="scale(%g)" stroke-width="%g" stroke-linejoin="round" stroke-linecap="round">`+"\n",
+		svgPieceID(p), float64(size)/pieceUnits, pieceStroke)
+	for _, part := range vectorPieces[p.Kind] {
+		switch part.kind {
+		case bodyPart:
+			e.printf("<%s %s %s/>\n", part.svg, svgFill(c.body), svgPaint("stroke", c.outline))
+		case linePart:
+			e.printf(`<%s fill="none" %s/>`+"\n", part.svg, svgPaint("stroke", c.outline))
+		case detailPart:
+			e.printf(`<%s fill="none" %s/>`+"\n", part.svg, svgPaint("stroke", c.detail))
+		}
+	}
+	e.printf("</g>\n")
+}
+
+// check writes the glow of the king in check in the square r (see DrawCheck).
+func (e *svgWriter) check(r image.Rectangle, c color.Color) {
+	// there is at most one king in check
+	e.printf(`<radialGradient id="check" r="%g"><stop offset="%g" %s/><stop offset="1" %s/></radialGradient>`+"\n",
+		checkRadius, checkCore, svgPaint("stop-color", c), svgPaint("stop-color", color.NRGBA{}))
+	e.printf(`<rect x="%d" y="%d" width="%d" height="%d" fill="url(#check)"/>`+"\n", r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+}
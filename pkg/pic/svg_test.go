@@ -0,0 +1,146 @@
+package pic
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// svgElements returns the number of the elements of each name in the SVG document.
+func svgElements(t *testing.T, doc []byte) map[string]int {
+	count := map[string]int{}
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return count
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %s", err)
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			count[el.Name.Local]++
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	// 1. f3 e5 2. g4 Qh4#
+	st, err := chess.ParseFEN(strings.NewReader("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sq := chess.MustNewSquareFromString
+	d := SVGDiagram{
+		State:     st,
+		From:      chess.Black,
+		Highlight: true,
+		LastMove:  chess.Move{From: sq("d8"), To: sq("h4")},
+		Annotations: Annotations{
+			Arrows:  []Arrow{{From: sq("g1"), To: sq("f3"), Color: AnnotationColors["green"]}},
+			Circles: []Mark{{Square: sq("e1"), Color: AnnotationColors["red"]}},
+			Badges:  []Badge{{Square: sq("h4"), Text: "#", Color: AnnotationColors["blue"]}},
+		},
+		PieceURL: func(p chess.Piece) string {
+			return "pieces/" + p.Color.Name() + "-" + p.Kind.Name() + ".svg"
+		},
+	}
+
+	t.Run("inside", func(tt *testing.T) {
+		d.Coordinates = InsideCoordinates
+		buf := &bytes.Buffer{}
+		if err := WriteSVG(buf, DefaultCollection, d); err != nil {
+			tt.Fatal(err)
+		}
+		got := svgElements(tt, buf.Bytes())
+		want := map[string]int{
+			"svg":            1,
+			"image":          12, // every kind of piece in both colors is defined once
+			"use":            32,
+			"rect":           1 + 32 + 2 + 1, // the board, the dark squares, the last move and the check
+			"radialGradient": 1,
+			"text":           16 + 1,
+			"circle":         2,
+			"path":           1,
+		}
+		for name, n := range want {
+			if got[name] != n {
+				tt.Errorf("want %d <%s>, got %d", n, name, got[name])
+			}
+		}
+		if !strings.Contains(buf.String(), `xlink:href="pieces/black-queen.svg"`) {
+			tt.Errorf("piece images must be referenced by PieceURL")
+		}
+	})
+	t.Run("margin", func(tt *testing.T) {
+		d.Coordinates = MarginCoordinates
+		d.PieceURL = nil
+		buf := &bytes.Buffer{}
+		if err := WriteSVG(buf, DefaultCollection, d); err != nil {
+			tt.Fatal(err)
+		}
+		got := svgElements(tt, buf.Bytes())
+		if got["text"] != 32+1 || got["rect"] != 1+1+32+2+1 {
+			tt.Errorf("want labels on four sides and the margin, got %v", got)
+		}
+		if !strings.Contains(buf.String(), `xlink:href="data:image/png;base64,`) {
+			tt.Errorf("piece images must be embedded")
+		}
+	})
+	t.Run("vector", func(tt *testing.T) {
+		d.Coordinates = NoCoordinates
+		d.PieceURL = nil
+		buf := &bytes.Buffer{}
+		if err := WriteSVG(buf, Themes["blue"].Apply(VectorPieces(DefaultCollection)), d); err != nil {
+			tt.Fatal(err)
+		}
+		got := svgElements(tt, buf.Bytes())
+		if got["image"] != 0 || got["g"] != 12 {
+			tt.Errorf("want 12 pieces defined by paths, got %v", got)
+		}
+	})
+	t.Run("motifs", func(tt *testing.T) {
+		d.Highlight = false
+		d.Annotations = Annotations{}
+		// the bishop on b5 pins the knight on c6 to the king on e8
+		st, err := chess.ParseFEN(strings.NewReader("4k3/8/2n5/1B6/8/8/8/4K3 w - - 0 1"))
+		if err != nil {
+			tt.Fatal(err)
+		}
+		d.State = st
+		d.Motifs = st.Position.Motifs(chess.White)
+		buf := &bytes.Buffer{}
+		if err := WriteSVG(buf, DefaultCollection, d); err != nil {
+			tt.Fatal(err)
+		}
+		// the fills of the pieces in front of the targets are over the pieces as in DrawMotifs
+		doc := buf.String()
+		fill := svgPaint("fill", MotifFrontColor)
+		if !strings.Contains(doc, fill) {
+			tt.Fatalf("no fill of the pinned piece")
+		}
+		if strings.Index(doc, fill) < strings.LastIndex(doc, "<use ") {
+			tt.Errorf("motif fills must be drawn over the pieces")
+		}
+	})
+}
+
+func TestPieceURLTemplate(t *testing.T) {
+	url, err := PieceURLTemplate("/pieces/{code}.svg?{color}&{kind}")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := url(chess.Piece{Color: chess.Black, Kind: chess.Knight}), "/pieces/bN.svg?black&knight"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := url(chess.Piece{Color: chess.White, Kind: chess.Rook}), "/pieces/wR.svg?white&rook"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if _, err := PieceURLTemplate("/pieces/king.svg"); err == nil {
+		t.Errorf("want error for a template without placeholders")
+	}
+}
@@ -0,0 +1,65 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// textFont is the parsed embedded font. Unlike its faces, it may be shared by goroutines.
+var textFont *opentype.Font
+
+func init() {
+	var err error
+	textFont, err = opentype.Parse(gomedium.TTF)
+	if err != nil {
+		panic("parse embedded font: " + err.Error())
+	}
+}
+
+// textFace returns a new face of the embedded font with the size in pixels.
+// Faces are not safe for concurrent use, so a face must not be shared between goroutines.
+func textFace(size float64) font.Face {
+	face, err := opentype.NewFace(textFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		// only invalid options cause an error
+		panic("create font face: " + err.Error())
+	}
+	return face
+}
+
+// TextAnchor selects the point of the text that is placed at the given position.
+type TextAnchor int
+
+const (
+	AnchorTopLeft TextAnchor = iota
+	AnchorCenter
+	AnchorBottomRight
+)
+
+// textBounds returns the size of s in the face: the advance and the height of capital letters.
+func textBounds(face font.Face, s string) (width, height int) {
+	return font.MeasureString(face, s).Ceil(), face.Metrics().CapHeight.Ceil()
+}
+
+// DrawText draws s with the embedded font of the size in pixels so that the anchor of the text is at pt.
+// Only the capital letters and the digits are taken into account when aligning vertically.
+func DrawText(dst draw.Image, pt image.Point, s string, size float64, anchor TextAnchor, c color.Color) {
+	face := textFace(size)
+	w, h := textBounds(face, s)
+	// dot is at the baseline
+	dot := pt.Add(image.Pt(0, h))
+	switch anchor {
+	case AnchorCenter:
+		dot = dot.Sub(image.Pt(w/2, h/2))
+	case AnchorBottomRight:
+		dot = dot.Sub(image.Pt(w, h))
+	}
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face, Dot: fixed.P(dot.X, dot.Y)}
+	d.DrawString(s)
+}
@@ -0,0 +1,48 @@
+package pic
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+)
+
+func TestDrawText(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 100, 40))
+	DrawText(dst, image.Pt(50, 20), "e4", 20, AnchorCenter, color.White)
+	minX, maxX := dst.Bounds().Max.X, -1
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 100; x++ {
+			if dst.RGBAAt(x, y).A != 0 {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+			}
+		}
+	}
+	if maxX < 0 {
+		t.Fatalf("no text drawn")
+	}
+	if mid := (minX + maxX) / 2; mid < 45 || mid > 55 {
+		t.Errorf("text must be centered at 50, got columns %d-%d", minX, maxX)
+	}
+}
+
+// TestDrawTextConcurrent is meant to be run with -race: the server draws text in many goroutines.
+func TestDrawTextConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dst := image.NewRGBA(image.Rect(0, 0, 100, 40))
+			for j := 0; j < 20; j++ {
+				DrawText(dst, image.Pt(50, 20), "Rxe6+", 16, AnchorCenter, color.White)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,220 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// Theme describes a board that is drawn from the colors of the squares instead of loaded from images,
+// so it is sharp at any size.
+type Theme struct {
+	Light, Dark color.NRGBA
+	// Border is the color of the frame along the edges of the board. There is no frame if it is transparent.
+	Border color.NRGBA
+	// Texture is the strength of the wood-like grain of the squares, from 0 (flat colors) to 1.
+	Texture float64
+	// LastMove and Check are the colors of the highlights that match the board (see Highlights).
+	// The transparent ones are taken from the collection the theme is applied to.
+	LastMove, Check color.NRGBA
+}
+
+// Themes are the built-in themes by their names.
+var Themes = map[string]Theme{
+	"brown": {
+		Light:    color.NRGBA{R: 0xf0, G: 0xd9, B: 0xb5, A: 0xff},
+		Dark:     color.NRGBA{R: 0xb5, G: 0x88, B: 0x63, A: 0xff},
+		Texture:  0.5,
+		LastMove: color.NRGBA{R: 0xcd, G: 0xd2, B: 0x3a, A: 0x80},
+		Check:    color.NRGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xff},
+	},
+	"blue": {
+		Light:    color.NRGBA{R: 0xde, G: 0xe3, B: 0xe6, A: 0xff},
+		Dark:     color.NRGBA{R: 0x8c, G: 0xa2, B: 0xad, A: 0xff},
+		LastMove: color.NRGBA{R: 0x1e, G: 0x8c, B: 0xd2, A: 0x60},
+		Check:    color.NRGBA{R: 0xf0, G: 0x10, B: 0x10, A: 0xff},
+	},
+	"green": {
+		Light:    color.NRGBA{R: 0xee, G: 0xee, B: 0xd2, A: 0xff},
+		Dark:     color.NRGBA{R: 0x76, G: 0x96, B: 0x56, A: 0xff},
+		LastMove: color.NRGBA{R: 0xf6, G: 0xf6, B: 0x50, A: 0x90},
+		Check:    color.NRGBA{R: 0xe8, G: 0x3a, B: 0x2c, A: 0xff},
+	},
+	"grey": {
+		Light:    color.NRGBA{R: 0xdc, G: 0xdc, B: 0xdc, A: 0xff},
+		Dark:     color.NRGBA{R: 0xab, G: 0xab, B: 0xab, A: 0xff},
+		LastMove: color.NRGBA{R: 0x3c, G: 0x8c, B: 0xdc, A: 0x70},
+		Check:    color.NRGBA{R: 0xf0, G: 0x10, B: 0x10, A: 0xff},
+	},
+	"high-contrast": {
+		Light:    color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		Dark:     color.NRGBA{R: 0x6b, G: 0x6b, B: 0x6b, A: 0xff},
+		Border:   color.NRGBA{A: 0xff},
+		LastMove: color.NRGBA{R: 0xff, G: 0xc8, B: 0x00, A: 0xc0},
+		Check:    color.NRGBA{R: 0xff, A: 0xff},
+	},
+}
+
+// ThemeNames returns the sorted names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseTheme returns a built-in theme by its name.
+func ParseTheme(s string) (Theme, error) {
+	if t, ok := Themes[s]; ok {
+		return t, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q", s)
+}
+
+type themeKey struct {
+	theme Theme
+	size  int
+}
+
+// themeBoards are the recently drawn boards of themes, at most 128 MB of them.
+var themeBoards = newImageCache(128 << 20)
+
+// Board returns the board image of the theme of size x size pixels. The board looks the same from both
+// perspectives (a1 and h8 are dark). The recently drawn images are cached.
+func (t Theme) Board(size int) Image {
+	// the highlights do not change the board
+	t.LastMove, t.Check = color.NRGBA{}, color.NRGBA{}
+	img, _ := themeBoards.get(themeKey{theme: t, size: size}, func() (interface{}, int, error) {
+		img := t.drawBoard(size)
+		return img, imageBytes(img), nil
+	})
+	return img.(Image)
+}
+
+// drawBoard draws the board image of the theme of size x size pixels (see Board).
+func (t Theme) drawBoard(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	board := img.Bounds()
+	for file := 0; file < 8; file++ {
+		for rank := 0; rank < 8; rank++ {
+			c := t.Light
+			if (file+rank)%2 == 0 {
+				c = t.Dark
+			}
+			r := SquareRect(board, chess.MustNewSquare(file, rank), chess.White)
+			if t.Texture == 0 {
+				draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+				continue
+			}
+			// every square is a separate piece of wood
+			seed := uint32(file*8 + rank)
+			ss := float64(r.Dx())
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				row := img.Pix[img.PixOffset(r.Min.X, y):img.PixOffset(r.Max.X, y)]
+				for x := r.Min.X; x < r.Max.X; x++ {
+					// the grain runs along the rows: the noise is stretched horizontally
+					n := valueNoise(float64(x-r.Min.X)*2/ss, float64(y-r.Min.Y)*14/ss, seed)
+					// the pixels of image.RGBA are premultiplied by alpha
+					cr, cg, cb, ca := shade(c, 1+t.Texture*0.16*(n-0.5)).RGBA()
+					i := (x - r.Min.X) * 4
+					row[i], row[i+1], row[i+2], row[i+3] = uint8(cr>>8), uint8(cg>>8), uint8(cb>>8), uint8(ca>>8)
+				}
+			}
+		}
+	}
+	if t.Border.A != 0 {
+		width := size / 128
+		if width < 1 {
+			width = 1
+		}
+		frame := image.NewUniform(t.Border)
+		for _, r := range []image.Rectangle{
+			image.Rect(0, 0, size, width),
+			image.Rect(0, size-width, size, size),
+			image.Rect(0, 0, width, size),
+			image.Rect(size-width, 0, size, size),
+		} {
+			draw.Draw(img, r, frame, image.Point{}, draw.Over)
+		}
+	}
+	return img
+}
+
+// shade returns c with the brightness multiplied by f.
+func shade(c color.NRGBA, f float64) color.NRGBA {
+	scale := func(v uint8) uint8 {
+		return uint8(math.Max(0, math.Min(0xff, math.Round(float64(v)*f))))
+	}
+	return color.NRGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: c.A}
+}
+
+// valueNoise returns a smooth pseudo-random value from 0 to 1 at (x, y). The values are random at the integer points
+// and interpolated between them.
+func valueNoise(x, y float64, seed uint32) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := smoothstep(x-x0), smoothstep(y-y0)
+	at := func(dx, dy int) float64 {
+		return hashNoise(int(x0)+dx, int(y0)+dy, seed)
+	}
+	top := at(0, 0) + (at(1, 0)-at(0, 0))*fx
+	bottom := at(0, 1) + (at(1, 1)-at(0, 1))*fx
+	return top + (bottom-top)*fy
+}
+
+func smoothstep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// hashNoise returns a pseudo-random value from 0 to 1 for the integer point (x, y).
+func hashNoise(x, y int, seed uint32) float64 {
+	h := uint32(x)*0x8da6b343 ^ uint32(y)*0xd8163841 ^ seed*0xcb1ab31f
+	h ^= h >> 13
+	h *= 0x5bd1e995
+	h ^= h >> 15
+	return float64(h) / math.MaxUint32
+}
+
+type themeCollection struct {
+	Collection
+	theme Theme
+	board Image
+}
+
+func (col themeCollection) Board(chess.PieceColor) Image {
+	return col.board
+}
+
+func (col themeCollection) Highlights() Highlights {
+	h := CollectionHighlights(col.Collection)
+	if col.theme.LastMove.A != 0 {
+		h.LastMove = col.theme.LastMove
+	}
+	if col.theme.Check.A != 0 {
+		h.Check = col.theme.Check
+	}
+	return h
+}
+
+func (col themeCollection) Canvas() draw.Image {
+	return image.NewRGBA(col.board.Bounds())
+}
+
+// Apply returns col with the board replaced by the board of the theme of the same size
+// and the highlights replaced by the ones of the theme.
+func (t Theme) Apply(col Collection) Collection {
+	if tcol, ok := col.(themeCollection); ok {
+		col = tcol.Collection
+	}
+	return themeCollection{
+		Collection: col,
+		theme:      t,
+		board:      t.Board(col.Board(chess.White).Bounds().Dx()),
+	}
+}
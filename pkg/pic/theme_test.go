@@ -0,0 +1,128 @@
+package pic
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestThemeBoard(t *testing.T) {
+	sq := chess.MustNewSquareFromString
+	for _, name := range ThemeNames() {
+		t.Run(name, func(tt *testing.T) {
+			theme := Themes[name]
+			img := theme.Board(200)
+			if img.Bounds().Dx() != 200 || img.Bounds().Dy() != 200 {
+				tt.Fatalf("want 200x200 board, got %v", img.Bounds())
+			}
+			if theme.Board(200) != img {
+				tt.Errorf("board must be cached")
+			}
+			near := func(c color.Color, want color.NRGBA) bool {
+				got := color.NRGBAModel.Convert(c).(color.NRGBA)
+				d := func(a, b uint8) int { return abs(int(a) - int(b)) }
+				return d(got.R, want.R) < 0x10 && d(got.G, want.G) < 0x10 && d(got.B, want.B) < 0x10
+			}
+			for _, tc := range []struct {
+				sq   chess.Square
+				want color.NRGBA
+			}{
+				{sq("a1"), theme.Dark},
+				{sq("h1"), theme.Light},
+				{sq("a8"), theme.Light},
+				{sq("e4"), theme.Light},
+			} {
+				r := SquareRect(img.Bounds(), tc.sq, chess.White)
+				center := r.Min.Add(r.Max).Div(2)
+				if got := img.At(center.X, center.Y); !near(got, tc.want) {
+					tt.Errorf("%s: want %v, got %v", tc.sq, tc.want, got)
+				}
+			}
+			if theme.Border.A != 0 {
+				if got := color.NRGBAModel.Convert(img.At(100, 0)); got != theme.Border {
+					tt.Errorf("border: want %v, got %v", theme.Border, got)
+				}
+			}
+		})
+	}
+}
+
+func TestThemeApply(t *testing.T) {
+	theme := Themes["green"]
+	col := theme.Apply(DefaultCollection)
+	bs := DefaultCollection.Board(chess.White).Bounds().Dx()
+	if got := col.Board(chess.Black).Bounds().Dx(); got != bs {
+		t.Errorf("board size: want %d, got %d", bs, got)
+	}
+	p := chess.Piece{Color: chess.Black, Kind: chess.Queen}
+	if col.Piece(p) != DefaultCollection.Piece(p) {
+		t.Errorf("pieces must be kept")
+	}
+
+	scaled, err := ScaleCollection(col, 512)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if scaled.Board(chess.White) != theme.Board(512) {
+		t.Errorf("scaled board must be drawn by the theme")
+	}
+	if got := scaled.Piece(p).Bounds().Dx(); got != 64 {
+		t.Errorf("scaled piece: want 64, got %d", got)
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	if _, err := ParseTheme("blue"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := ParseTheme("purple"); err == nil {
+		t.Errorf("want error")
+	}
+}
+
+func TestThemeBoardCacheBounded(t *testing.T) {
+	defer themeBoards.setMax(themeBoards.maxBytes)
+	themeBoards.setMax(3 * 128 * 128 * 4)
+	theme := Themes["brown"]
+	for size := 64; size <= 128; size += 8 {
+		theme.Board(size)
+		if _, bytes := themeBoards.size(); bytes > themeBoards.maxBytes {
+			t.Fatalf("%d: cache must stay bounded, got %d bytes", size, bytes)
+		}
+	}
+	if theme.Board(128) != theme.Board(128) {
+		t.Errorf("the last board must be cached")
+	}
+}
+
+func TestThemeHighlights(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme := Themes[name]
+		if theme.LastMove.A == 0 || theme.Check.A == 0 {
+			t.Errorf("%s: want highlight colors", name)
+		}
+		h := CollectionHighlights(theme.Apply(DefaultCollection))
+		if h.LastMove != theme.LastMove || h.Check != theme.Check {
+			t.Errorf("%s: want the highlights of the theme, got %v", name, h)
+		}
+	}
+
+	// the transparent colors are taken from the collection
+	theme := Themes["blue"]
+	theme.Check = color.NRGBA{}
+	h := CollectionHighlights(theme.Apply(DefaultCollection))
+	if h.LastMove != theme.LastMove || h.Check != DefaultHighlights.Check {
+		t.Errorf("want the check color of the collection, got %v", h)
+	}
+	if theme.Board(64) != Themes["blue"].Board(64) {
+		t.Errorf("the highlights must not change the board")
+	}
+
+	// the highlights given explicitly replace the ones of the theme
+	check := color.NRGBA{B: 0xff, A: 0xff}
+	h = CollectionHighlights(WithHighlights(Themes["blue"].Apply(DefaultCollection), Highlights{Check: check}))
+	if h.LastMove != Themes["blue"].LastMove || h.Check != check {
+		t.Errorf("want the explicit check color, got %v", h)
+	}
+}
@@ -0,0 +1,231 @@
+package pic
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+// pieceUnits is the size of the square the vector pieces are defined in.
+const pieceUnits = 45
+
+// pieceStroke is the width of the outlines of the vector pieces in units.
+const pieceStroke = 1.5
+
+type partKind int
+
+const (
+	// bodyPart is filled with the color of the piece and outlined.
+	bodyPart partKind = iota
+	// linePart is lines of the color of the outline.
+	linePart
+	// detailPart is lines over the body: of the color of the outline on white pieces and white on black ones.
+	detailPart
+)
+
+// piecePart is a part of a vector piece. The lines of a part are open polylines.
+type piecePart struct {
+	kind  partKind
+	shape shape
+	// svg is the SVG element of the part without the paint (e.g. `path d="..."`).
+	svg string
+}
+
+func body(d string) piecePart {
+	return piecePart{kind: bodyPart, shape: mustParsePath(d), svg: fmt.Sprintf("path d=%q", d)}
+}
+
+func ball(x, y, r float64) piecePart {
+	return piecePart{
+		kind:  bodyPart,
+		shape: shape{circle(point{x, y}, r, false)},
+		svg:   fmt.Sprintf(`circle cx="%g" cy="%g" r="%g"`, x, y, r),
+	}
+}
+
+func line(d string) piecePart {
+	return piecePart{kind: linePart, shape: mustParsePath(d), svg: fmt.Sprintf("path d=%q", d)}
+}
+
+func detail(d string) piecePart {
+	return piecePart{kind: detailPart, shape: mustParsePath(d), svg: fmt.Sprintf("path d=%q", d)}
+}
+
+// vectorPieces are the drawings of the pieces, the parts are drawn in order. The pieces stand on the line y = 39.
+//
+// The paths are taken from the chess pieces by Colin M.L. Burnett (cburnett) published on Wikimedia Commons
+// (https://commons.wikimedia.org/wiki/Category:SVG_chess_pieces) and are used under CC BY-SA 3.0
+// (https://creativecommons.org/licenses/by-sa/3.0/legalcode), one of the licenses the author released them under.
+var vectorPieces = map[chess.PieceKind][]piecePart{
+	chess.Pawn: {
+		body("M 12.5,39 L 32.5,39 C 32.5,33 27.5,29 26,23 L 19,23 C 17.5,29 12.5,33 12.5,39 Z"),
+		body("M 17,23.5 C 17,21 19,20 22.5,20 C 26,20 28,21 28,23.5 Z"),
+		ball(22.5, 15, 5.5),
+	},
+	chess.Rook: {
+		body("M 9,39 L 36,39 L 36,35.5 L 9,35.5 Z"),
+		body("M 12,35.5 L 33,35.5 L 33,32 L 12,32 Z"),
+		body("M 14,32 L 31,32 L 30,17 L 15,17 Z"),
+		body("M 11,17 L 34,17 L 34,9 L 30,9 L 30,12 L 25,12 L 25,9 L 20,9 L 20,12 L 15,12 L 15,9 L 11,9 Z"),
+		detail("M 14.5,14.5 L 30.5,14.5"),
+	},
+	chess.Knight: {
+		body("M 22,10 C 32.5,11 38.5,18 38,39 L 15,39 C 15,30 25,32.5 23,18 " +
+			"C 21.5,21.5 18,23.5 14.5,25.5 C 12,27 10.5,26.5 10,24.5 C 8.5,25 7.5,23 8.5,21.5 " +
+			"C 10.5,18 12.5,15.5 15,13 L 16,9 L 18.5,11 L 20,8 Z"),
+		detail("M 24,18 C 23.5,20 22,22 20.5,23"),
+		detail("M 9.5,25.5 L 10.5,24"),
+		detail("M 15,15.5 L 15.2,15.5"),
+		detail("M 24.5,11 C 31,13.5 35,20 35,36"),
+	},
+	chess.Bishop: {
+		body("M 9,39 C 12.5,38 19,38.5 22.5,36.5 C 26,38.5 32.5,38 36,39 L 35,37 C 31.5,36 26,36.5 22.5,34.5 " +
+			"C 19,36.5 13.5,36 10,37 Z"),
+		body("M 15,34.5 C 17.5,35.5 27.5,35.5 30,34.5 L 30,31.5 C 27.5,30.5 17.5,30.5 15,31.5 Z"),
+		body("M 16,31 C 12.5,27 13,20 22.5,12.5 C 32,20 32.5,27 29,31 C 25.5,30 19.5,30 16,31 Z"),
+		ball(22.5, 10, 2.5),
+		detail("M 22.5,17 L 22.5,25 M 18.5,21 L 26.5,21"),
+	},
+	chess.Queen: {
+		body("M 9.5,25 L 10,14 L 15,24 L 16.5,11 L 20.5,23.5 L 22.5,9.5 L 24.5,23.5 L 28.5,11 L 30,24 L 35,14 L 35.5,25 " +
+			"C 33,28 32,31 32.5,33.5 C 29.5,36.5 26.5,37.5 22.5,37.5 C 18.5,37.5 15.5,36.5 12.5,33.5 " +
+			"C 13,31 12,28 9.5,25 Z"),
+		body("M 12.5,33.5 C 15.5,36 18.5,37 22.5,37 C 26.5,37 29.5,36 32.5,33.5 L 33.5,39 L 11.5,39 Z"),
+		ball(10, 12.5, 2),
+		ball(16.5, 9.5, 2),
+		ball(22.5, 8, 2),
+		ball(28.5, 9.5, 2),
+		ball(35, 12.5, 2),
+		detail("M 12,29.5 C 18.5,27.5 26.5,27.5 33,29.5"),
+	},
+	chess.King: {
+		line("M 22.5,5.5 L 22.5,12 M 19.5,8 L 25.5,8"),
+		body("M 22.5,24.5 C 20,19.5 20.5,16 22.5,13.5 C 24.5,16 25,19.5 22.5,24.5 Z"),
+		body("M 11.5,37 C 6,32.5 5.5,25.5 10,22.5 C 14,20 19.5,21.5 22.5,25.5 C 25.5,21.5 31,20 35,22.5 " +
+			"C 39.5,25.5 39,32.5 33.5,37 Z"),
+		body("M 11.5,37 C 17.5,35 27.5,35 33.5,37 L 33.5,39.5 L 11.5,39.5 Z"),
+		detail("M 11.5,30.5 C 17.5,28 27.5,28 33.5,30.5"),
+		detail("M 22.5,26 L 22.5,35.5"),
+	},
+}
+
+// vectorColors are the colors of the bodies, the outlines and the details of the vector pieces.
+var vectorColors = [2]struct{ body, outline, detail color.Color }{
+	chess.White: {body: color.White, outline: color.Black, detail: color.Black},
+	chess.Black: {body: color.Black, outline: color.Black, detail: color.White},
+}
+
+// drawVectorPiece returns the image of size x size pixels of the vector piece p.
+func drawVectorPiece(p chess.Piece, size int) Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	k := float64(size) / pieceUnits
+	width := pieceStroke * k
+	c := vectorColors[p.Color]
+	for _, part := range vectorPieces[p.Kind] {
+		s := part.shape.transform(k, point{})
+		switch part.kind {
+		case bodyPart:
+			fillShape(img, s, c.body)
+			fillShape(img, stroke(s, width, true), c.outline)
+		case linePart:
+			fillShape(img, stroke(s, width, false), c.outline)
+		case detailPart:
+			fillShape(img, stroke(s, width, false), c.detail)
+		}
+	}
+	return img
+}
+
+// vectorPieceSets are the recently drawn sets of the vector pieces, at most 64 MB of them.
+var vectorPieceSets = newImageCache(64 << 20)
+
+// vectorPieceSet returns the images of the vector pieces of the size in the order of collection.images[2:].
+// The recently drawn sets are cached.
+func vectorPieceSet(size int) [12]Image {
+	set, _ := vectorPieceSets.get(size, func() (interface{}, int, error) {
+		var set [12]Image
+		for color := chess.White; color <= chess.Black; color++ {
+			for kind := chess.Pawn; kind <= chess.King; kind++ {
+				set[int(color)*6+int(kind-chess.Pawn)] = drawVectorPiece(chess.Piece{Color: color, Kind: kind}, size)
+			}
+		}
+		return set, 12 * size * size * 4, nil
+	})
+	return set.([12]Image)
+}
+
+type vectorCollection struct {
+	Collection
+	pieces [12]Image
+}
+
+func (col vectorCollection) Piece(p chess.Piece) Image {
+	if p.Kind == chess.None {
+		return nil
+	}
+	return col.pieces[int(p.Color)*6+int(p.Kind-chess.Pawn)]
+}
+
+func (col vectorCollection) Highlights() Highlights {
+	return CollectionHighlights(col.Collection)
+}
+
+func (col vectorCollection) Canvas() draw.Image {
+	if ccol, ok := col.Collection.(CanvasCollection); ok {
+		return ccol.Canvas()
+	}
+	return image.NewRGBA(col.Board(chess.White).Bounds())
+}
+
+// hasVectorPieces reports whether the pieces of col are the vector pieces (see VectorPieces).
+func hasVectorPieces(col Collection) bool {
+	for {
+		switch c := col.(type) {
+		case vectorCollection:
+			return true
+		case themeCollection:
+			col = c.Collection
+		case highlightCollection:
+			col = c.Collection
+		default:
+			return false
+		}
+	}
+}
+
+// VectorPieces returns board with the pieces replaced by the built-in vector pieces, which are drawn to fill
+// the squares of board. The pieces are sharp at any size: scaling the collection (see ScaleCollection)
+// draws them again instead of resampling.
+func VectorPieces(board Collection) Collection {
+	if vcol, ok := board.(vectorCollection); ok {
+		board = vcol.Collection
+	}
+	return vectorCollection{
+		Collection: board,
+		pieces:     vectorPieceSet(board.Board(chess.White).Bounds().Dx() / 8),
+	}
+}
+
+// PieceSetNames are the names of the built-in piece sets: "default" (the images of DefaultCollection)
+// and "vector" (see VectorPieces).
+var PieceSetNames = []string{"default", "vector"}
+
+// WithPieceSet returns board with the pieces replaced by the built-in piece set by its name.
+func WithPieceSet(board Collection, name string) (Collection, error) {
+	switch name {
+	case "default":
+		col := collection{}
+		for color := chess.White; color <= chess.Black; color++ {
+			for kind := chess.Pawn; kind <= chess.King; kind++ {
+				col.images[2+int(color)*6+int(kind-chess.Pawn)] = DefaultCollection.Piece(chess.Piece{Color: color, Kind: kind})
+			}
+		}
+		return withBoard(col, board)
+	case "vector":
+		return VectorPieces(board), nil
+	}
+	return nil, fmt.Errorf("unknown piece set %q", name)
+}
@@ -0,0 +1,108 @@
+package pic
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+)
+
+func TestParsePath(t *testing.T) {
+	tcs := []struct {
+		name    string
+		d       string
+		want    []int
+		wantErr bool
+	}{
+		{name: "lines", d: "M 0,0 L 10,0 L 10,10 Z", want: []int{3}},
+		{name: "implicit lines", d: "M 0,0 10,0 10,10 Z", want: []int{3}},
+		{name: "curves", d: "M 0,0 Q 5,5 10,0 C 10,5 5,10 0,10 Z", want: []int{1 + 2*curveSegments}},
+		{name: "subpaths", d: "M 0,0 L 1,1 M 2,2 L 3,3 L 4,4", want: []int{2, 3}},
+		{name: "no current point", d: "L 1,1", wantErr: true},
+		{name: "odd numbers", d: "M 0,0 L 1", wantErr: true},
+		{name: "unknown command", d: "M 0,0 A 1,1", wantErr: true},
+		{name: "not a number", d: "M 0,x", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			s, err := parsePath(tc.d)
+			if tc.wantErr {
+				if err == nil {
+					tt.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if len(s) != len(tc.want) {
+				tt.Fatalf("want %d polygons, got %d", len(tc.want), len(s))
+			}
+			for i, n := range tc.want {
+				if len(s[i]) != n {
+					tt.Errorf("polygon %d: want %d points, got %d", i, n, len(s[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestVectorPieces(t *testing.T) {
+	col := VectorPieces(DefaultCollection)
+	ss := DefaultCollection.Board(chess.White).Bounds().Dx() / 8
+	for color := chess.White; color <= chess.Black; color++ {
+		for kind := chess.Pawn; kind <= chess.King; kind++ {
+			img := col.Piece(chess.Piece{Color: color, Kind: kind})
+			if img.Bounds().Dx() != ss || img.Bounds().Dy() != ss {
+				t.Errorf("%s %s: want %dx%d image, got %v", color.Name(), kind.Name(), ss, ss, img.Bounds())
+			}
+		}
+	}
+	if col.Board(chess.White) != DefaultCollection.Board(chess.White) {
+		t.Errorf("board must be kept")
+	}
+
+	// the bodies are filled with the color of the piece inside the outline
+	pawn := func(c chess.PieceColor, size int) color.Color {
+		k := float64(size) / pieceUnits
+		return vectorPieceSet(size)[int(c)*6].At(int(22.5*k), int(30*k))
+	}
+	for _, size := range []int{45, 200} {
+		if got := color.GrayModel.Convert(pawn(chess.White, size)).(color.Gray).Y; got != 0xff {
+			t.Errorf("white pawn of %d: want white body, got %d", size, got)
+		}
+		if r, g, b, a := pawn(chess.Black, size).RGBA(); r != 0 || g != 0 || b != 0 || a != 0xffff {
+			t.Errorf("black pawn of %d: want black body, got %v", size, pawn(chess.Black, size))
+		}
+	}
+
+	// scaled pieces are drawn again instead of resampled
+	scaled, err := ScaleCollection(col, 640)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	p := chess.Piece{Color: chess.Black, Kind: chess.Knight}
+	if scaled.Piece(p) != vectorPieceSet(80)[6+int(chess.Knight-chess.Pawn)] {
+		t.Errorf("scaled pieces must be drawn at the size")
+	}
+}
+
+func TestWithPieceSet(t *testing.T) {
+	board := Themes["grey"].Apply(DefaultCollection)
+	p := chess.Piece{Color: chess.White, Kind: chess.Queen}
+	for _, name := range PieceSetNames {
+		col, err := WithPieceSet(board, name)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if col.Board(chess.White) != board.Board(chess.White) {
+			t.Errorf("%s: board must be kept", name)
+		}
+		if name == "default" && col.Piece(p) != DefaultCollection.Piece(p) {
+			t.Errorf("default: pieces of DefaultCollection are expected")
+		}
+	}
+	if _, err := WithPieceSet(board, "unknown"); err == nil {
+		t.Errorf("want error")
+	}
+}
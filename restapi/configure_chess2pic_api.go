@@ -5,6 +5,8 @@ package restapi
 import (
 	"bytes"
 	"crypto/tls"
+	stderrors "errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -14,18 +16,106 @@ import (
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 
 	"github.com/xopoww/chess2pic/internal/chess2pic"
 	"github.com/xopoww/chess2pic/models"
 	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/chess/engine"
 	"github.com/xopoww/chess2pic/pkg/pic"
 	"github.com/xopoww/chess2pic/restapi/operations"
 )
 
 //go:generate swagger generate server --target ../../chess2pic --name Chess2picAPI --spec ../api/chess2pic-api.yaml --principal interface{}
 
+// maxHintTime limits the search of the built-in engine in a hint request.
+const maxHintTime = 5 * time.Second
+
+// maxAnalysisTime limits the analysis of an annotated game by the built-in engine.
+const maxAnalysisTime = 10 * time.Second
+
+// maxBoardSize limits the size of the images drawn by the server.
+const maxBoardSize = 2048
+
+// collectionOptions are the collections of images preloaded at startup, requests select them by name.
+var collectionOptions struct {
+	Collections []string `long:"collection" description:"preload a collection of images as NAME=PATH, where PATH is a directory or a zip archive with the board and the piece images (may be repeated)" env:"COLLECTIONS" env-delim:","`
+	Pieces      []string `long:"pieces" description:"preload a piece set as NAME=PATH, where PATH is a directory or a zip archive with the piece images or a PNG sprite sheet of the pieces drawn on the default board (may be repeated)" env:"PIECES" env-delim:","`
+	FitBoard    bool     `long:"fit-board" description:"scale the board up if the pieces of a piece set do not fit in its squares (an error otherwise)" env:"FIT_BOARD"`
+}
+
+// defaultCollectionName is the name of the embedded collection.
+const defaultCollectionName = "default"
+
+// collections are the preloaded collections by their names.
+var collections = map[string]pic.Collection{}
+
 func configureFlags(api *operations.Chess2picAPIAPI) {
-	// api.CommandLineOptionsGroups = []swag.CommandLineOptionsGroup{ ... }
+	api.CommandLineOptionsGroups = []swag.CommandLineOptionsGroup{
+		{
+			ShortDescription: "Collections",
+			LongDescription:  "Custom images of the board and the pieces",
+			Options:          &collectionOptions,
+		},
+	}
+}
+
+// loadCollections preloads the collections of the options and exits if any of them is invalid.
+func loadCollections() {
+	cols, err := preloadCollections(collectionOptions.Collections, collectionOptions.Pieces, collectionOptions.FitBoard)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	collections = cols
+}
+
+// preloadCollections loads the collections (see chess2pic.LoadCollection) and the piece sets drawn on the default
+// board (see chess2pic.LoadPieces) given as NAME=PATH. The result also has the default collection.
+// The default board is scaled up for the pieces that do not fit in its squares only if fit is set.
+func preloadCollections(cols, pieces []string, fit bool) (map[string]pic.Collection, error) {
+	res := map[string]pic.Collection{defaultCollectionName: pic.DefaultCollection}
+	load := func(opt string, f func(path string) (pic.Collection, error)) error {
+		i := strings.IndexByte(opt, '=')
+		if i <= 0 {
+			return fmt.Errorf("invalid collection %q: NAME=PATH is required", opt)
+		}
+		name, path := opt[:i], opt[i+1:]
+		if _, ok := res[name]; ok {
+			return fmt.Errorf("duplicate collection name %q", name)
+		}
+		col, err := f(path)
+		if err != nil {
+			return fmt.Errorf("failed to load collection %q: %w", name, err)
+		}
+		res[name] = col
+		log.Printf("loaded collection %q from %s", name, path)
+		return nil
+	}
+	for _, opt := range cols {
+		if err := load(opt, chess2pic.LoadCollection); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range pieces {
+		err := load(opt, func(path string) (pic.Collection, error) {
+			return fitPieces(pic.DefaultCollection, fit, func(board pic.Collection) (pic.Collection, error) {
+				return chess2pic.LoadPieces(path, board)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// fitPieces returns the pieces returned by open with board, which is scaled up if they do not fit and fit is set
+// (see chess2pic.FitPieces).
+func fitPieces(board pic.Collection, fit bool, open func(board pic.Collection) (pic.Collection, error)) (pic.Collection, error) {
+	if fit {
+		return chess2pic.FitPieces(board, open)
+	}
+	return open(board)
 }
 
 func configureAPI(api *operations.Chess2picAPIAPI) http.Handler {
@@ -46,6 +136,11 @@ func configureAPI(api *operations.Chess2picAPIAPI) http.Handler {
 
 	api.JSONProducer = runtime.JSONProducer()
 
+	loadCollections()
+
+	api.GetThemesHandler = operations.GetThemesHandlerFunc(func(params operations.GetThemesParams) middleware.Responder {
+		return operations.NewGetThemesOK().WithPayload(pic.ThemeNames())
+	})
 	api.PostFenHandler = operations.PostFenHandlerFunc(func(params operations.PostFenParams) middleware.Responder {
 		var from chess.PieceColor
 		if *params.Body.FromWhite {
@@ -54,35 +149,174 @@ func configureAPI(api *operations.Chess2picAPIAPI) http.Handler {
 			from = chess.Black
 		}
 
+		coords, err := parseCoordinates(params.Body.Coordinates)
+		if err != nil {
+			return operations.NewPostFenOK().WithPayload(errorResult(err))
+		}
+
+		format := chess2pic.PNG
+		if params.Body.Format != "" {
+			if format, err = chess2pic.ParseFormat(params.Body.Format); err != nil {
+				return operations.NewPostFenOK().WithPayload(errorResult(err))
+			}
+		}
+
+		col, err := requestCollection(params.Body.Collection, params.Body.PieceSet, params.Body.Size, params.Body.Theme)
+		if err != nil {
+			return operations.NewPostFenOK().WithPayload(errorResult(err))
+		}
+		if params.Body.HighlightColor != "" || params.Body.CheckColor != "" {
+			h, err := chess2pic.ParseHighlights(params.Body.HighlightColor, params.Body.CheckColor)
+			if err != nil {
+				return operations.NewPostFenOK().WithPayload(errorResult(err))
+			}
+			col = pic.WithHighlights(col, h)
+		}
+
 		buf := &bytes.Buffer{}
-		err := chess2pic.HandleFEN(strings.NewReader(*params.Body.Notation), buf, pic.DefaultCollection, from)
+		alt := &strings.Builder{}
+		opts := chess2pic.FENOptions{
+			Format:      format,
+			Motifs:      params.Body.Motifs,
+			Heatmap:     params.Body.Heatmap,
+			AltText:     alt,
+			Coordinates: coords,
+			Highlight:   params.Body.Highlight || params.Body.LastMove != "",
+		}
+		if params.Body.PieceURL != "" {
+			if format != chess2pic.SVG {
+				return operations.NewPostFenOK().WithPayload(errorResult(stderrors.New("piece-url requires svg format")))
+			}
+			if opts.PieceURL, err = pic.PieceURLTemplate(params.Body.PieceURL); err != nil {
+				return operations.NewPostFenOK().WithPayload(errorResult(err))
+			}
+		}
+		if params.Body.LastMove != "" {
+			if opts.LastMove, err = chess2pic.ParseLastMove(params.Body.LastMove); err != nil {
+				return operations.NewPostFenOK().WithPayload(errorResult(err))
+			}
+		}
+		opts.Annotations, err = chess2pic.ParseAnnotations(params.Body.Arrows, params.Body.Marks, params.Body.Fills, params.Body.Badges)
+		if err != nil {
+			return operations.NewPostFenOK().WithPayload(errorResult(err))
+		}
+		err = chess2pic.HandleFEN(strings.NewReader(*params.Body.Notation), buf, col, from, opts)
 
 		ok := err == nil
 		result := &models.APIResult{Ok: &ok}
 		if err != nil {
 			result.Error = err.Error()
+			result.ParseError = parseErrorModel(err)
 		} else {
 			result.Result = strfmt.Base64(buf.Bytes())
+			result.AltText = alt.String()
 		}
 		return operations.NewPostFenOK().WithPayload(result)
 	})
-	api.PostPgnHandler = operations.PostPgnHandlerFunc(func(params operations.PostPgnParams) middleware.Responder {
+	api.PostHintHandler = operations.PostHintHandlerFunc(func(params operations.PostHintParams) middleware.Responder {
 		var from chess.PieceColor
 		if *params.Body.FromWhite {
 			from = chess.White
 		} else {
 			from = chess.Black
 		}
-		
+
+		// the search must not hold the server for long
+		limits := engine.Limits{Depth: int(params.Body.Depth), MoveTime: time.Duration(params.Body.Movetime) * time.Millisecond}
+		if limits.MoveTime <= 0 {
+			limits.MoveTime = engine.DefaultMoveTime
+		}
+		if limits.MoveTime > maxHintTime {
+			limits.MoveTime = maxHintTime
+		}
+
+		col, err := requestCollection(params.Body.Collection, params.Body.PieceSet, params.Body.Size, params.Body.Theme)
+		if err != nil {
+			return operations.NewPostHintOK().WithPayload(errorResult(err))
+		}
+
 		buf := &bytes.Buffer{}
-		err := chess2pic.HandlePGN(strings.NewReader(*params.Body.Notation), buf, pic.DefaultCollection, from)
+		hint, err := chess2pic.HandleHint(params.HTTPRequest.Context(), strings.NewReader(*params.Body.Notation), buf, col, from, limits)
 
 		ok := err == nil
 		result := &models.APIResult{Ok: &ok}
 		if err != nil {
 			result.Error = err.Error()
+			result.ParseError = parseErrorModel(err)
 		} else {
 			result.Result = strfmt.Base64(buf.Bytes())
+			result.Move = hint.SAN
+			result.Eval = hint.Eval.String()
+			result.AltText = hint.AltText
+		}
+		return operations.NewPostHintOK().WithPayload(result)
+	})
+	api.PostPgnHandler = operations.PostPgnHandlerFunc(func(params operations.PostPgnParams) middleware.Responder {
+		var from chess.PieceColor
+		if *params.Body.FromWhite {
+			from = chess.White
+		} else {
+			from = chess.Black
+		}
+		
+		coords, err := parseCoordinates(params.Body.Coordinates)
+		if err != nil {
+			return operations.NewPostPgnOK().WithPayload(errorResult(err))
+		}
+
+		col, err := requestCollection(params.Body.Collection, params.Body.PieceSet, params.Body.Size, params.Body.Theme)
+		if err != nil {
+			return operations.NewPostPgnOK().WithPayload(errorResult(err))
+		}
+		if params.Body.HighlightColor != "" || params.Body.CheckColor != "" {
+			h, err := chess2pic.ParseHighlights(params.Body.HighlightColor, params.Body.CheckColor)
+			if err != nil {
+				return operations.NewPostPgnOK().WithPayload(errorResult(err))
+			}
+			col = pic.WithHighlights(col, h)
+		}
+
+		buf := &bytes.Buffer{}
+		alt := &strings.Builder{}
+		opts := chess2pic.PGNOptions{
+			EvalBar:     params.Body.EvalBar,
+			Heatmap:     params.Body.Heatmap,
+			AltText:     alt,
+			Coordinates: coords,
+			Highlight:   params.Body.Highlight,
+			Header:      params.Body.Header,
+			Caption:     params.Body.Caption,
+			// the analysis is stopped if the client disconnects
+			Context:     params.HTTPRequest.Context(),
+		}
+		var game chess.PGNResult
+		if params.Body.Annotate {
+			opts.Annotate = true
+			opts.AnalysisTime = maxAnalysisTime
+			opts.Result = &game
+		}
+		graph := &bytes.Buffer{}
+		if params.Body.EvalGraph {
+			opts.EvalGraph = graph
+		}
+		err = chess2pic.HandlePGN(strings.NewReader(*params.Body.Notation), buf, col, from, opts)
+
+		var partial chess2pic.PartialError
+		isPartial := stderrors.As(err, &partial)
+
+		ok := err == nil || isPartial
+		result := &models.APIResult{Ok: &ok, Partial: isPartial}
+		if err != nil {
+			result.Error = err.Error()
+			result.ParseError = parseErrorModel(err)
+		}
+		if ok {
+			result.Result = strfmt.Base64(buf.Bytes())
+			result.AltText = alt.String()
+			if graph.Len() > 0 {
+				result.EvalGraph = strfmt.Base64(graph.Bytes())
+			}
+			result.Annotations = annotationModels(game)
 		}
 		return operations.NewPostPgnOK().WithPayload(result)
 	})
@@ -94,6 +328,90 @@ func configureAPI(api *operations.Chess2picAPIAPI) http.Handler {
 	return setupGlobalMiddleware(api.Serve(setupMiddlewares))
 }
 
+// parseCoordinates returns the placement of the coordinates by its name, no coordinates by default.
+func parseCoordinates(s string) (pic.Coordinates, error) {
+	if s == "" {
+		return pic.NoCoordinates, nil
+	}
+	return pic.ParseCoordinates(s)
+}
+
+// requestCollection returns the preloaded collection by its name (the default one if it is empty)
+// with the pieces of the piece set and the board of the theme (if they are not empty)
+// scaled to the board size (if it is not 0).
+func requestCollection(name, pieceSet string, size int64, theme string) (pic.Collection, error) {
+	if name == "" {
+		name = defaultCollectionName
+	}
+	col, ok := collections[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown collection %q", name)
+	}
+	if pieceSet != "" {
+		var err error
+		col, err = fitPieces(col, collectionOptions.FitBoard, func(board pic.Collection) (pic.Collection, error) {
+			return pic.WithPieceSet(board, pieceSet)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if theme != "" {
+		t, err := pic.ParseTheme(theme)
+		if err != nil {
+			return nil, err
+		}
+		col = t.Apply(col)
+	}
+	if size == 0 {
+		return col, nil
+	}
+	if size > maxBoardSize {
+		return nil, fmt.Errorf("board size %d is too big (at most %d)", size, maxBoardSize)
+	}
+	return pic.ScaleCollection(col, int(size))
+}
+
+// errorResult returns the result of a request that failed before anything was drawn.
+func errorResult(err error) *models.APIResult {
+	ok := false
+	return &models.APIResult{Ok: &ok, Error: err.Error()}
+}
+
+// parseErrorModel returns the location of err in the input if err is a chess.ParseError, and nil otherwise.
+func parseErrorModel(err error) *models.ParseError {
+	var perr chess.ParseError
+	if !stderrors.As(err, &perr) {
+		return nil
+	}
+	return &models.ParseError{
+		Line:    int64(perr.Line),
+		Column:  int64(perr.Column),
+		Offset:  int64(perr.Offset),
+		Snippet: perr.Snippet,
+		Message: perr.Err.Error(),
+	}
+}
+
+// annotationModels returns the marked moves of an annotated game (see chess.PGNResult.NAGs).
+func annotationModels(res chess.PGNResult) []*models.MoveAnnotation {
+	var anns []*models.MoveAnnotation
+	st := res.StartState
+	for i, nag := range res.NAGs {
+		mov := res.Moves[i]
+		if nag != chess.NoNAG {
+			anns = append(anns, &models.MoveAnnotation{
+				Ply:  int64(i + 1),
+				Move: st.MoveNumber() + " " + st.SAN(mov),
+				Nag:  nag.String(),
+				Best: st.SAN(res.BestMoves[i]),
+			})
+		}
+		st = st.Apply(mov)
+	}
+	return anns
+}
+
 // The TLS configuration before HTTPS server starts.
 func configureTLS(tlsConfig *tls.Config) {
 	// Make all necessary changes to the TLS configuration here.
@@ -0,0 +1,136 @@
+package restapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xopoww/chess2pic/pkg/chess"
+	"github.com/xopoww/chess2pic/pkg/pic/pictest"
+)
+
+// writeSprite writes a sprite sheet of pieces of size ps into the directory dir in a temporary directory
+// and returns its path.
+func writeSprite(t *testing.T, dir string, ps int) string {
+	name := filepath.Join(t.TempDir(), dir, "sprite.png")
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, pictest.PNG(6*ps, 2*ps), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestPreloadCollections(t *testing.T) {
+	sprite := writeSprite(t, ".", 30)
+	equals := writeSprite(t, "a=b", 30)
+	big := writeSprite(t, "big", 60)
+	tcs := []struct {
+		name      string
+		pieces    []string
+		fit       bool
+		wantNames []string
+		wantErr   string
+	}{
+		{
+			name:      "none",
+			wantNames: []string{defaultCollectionName},
+		},
+		{
+			name:      "pieces",
+			pieces:    []string{"sprite=" + sprite},
+			wantNames: []string{defaultCollectionName, "sprite"},
+		},
+		{
+			name:      "several",
+			pieces:    []string{"sprite=" + sprite, "other=" + sprite},
+			wantNames: []string{defaultCollectionName, "sprite", "other"},
+		},
+		{
+			name:      "equals sign in path",
+			pieces:    []string{"equals=" + equals},
+			wantNames: []string{defaultCollectionName, "equals"},
+		},
+		{
+			name:    "no name",
+			pieces:  []string{"=" + sprite},
+			wantErr: "NAME=PATH is required",
+		},
+		{
+			name:    "no separator",
+			pieces:  []string{sprite},
+			wantErr: "NAME=PATH is required",
+		},
+		{
+			name:    "duplicate name",
+			pieces:  []string{"sprite=" + sprite, "sprite=" + sprite},
+			wantErr: `duplicate collection name "sprite"`,
+		},
+		{
+			name:    "default name",
+			pieces:  []string{defaultCollectionName + "=" + sprite},
+			wantErr: `duplicate collection name "default"`,
+		},
+		{
+			name:    "pieces too big",
+			pieces:  []string{"big=" + big},
+			wantErr: "piece image too big for the board",
+		},
+		{
+			name:      "board scaled up",
+			pieces:    []string{"big=" + big},
+			fit:       true,
+			wantNames: []string{defaultCollectionName, "big"},
+		},
+		{
+			name:    "missing file",
+			pieces:  []string{"missing=" + sprite + ".missing"},
+			wantErr: `failed to load collection "missing"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			cols, err := preloadCollections(nil, tc.pieces, tc.fit)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					tt.Fatalf("want error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %s", err)
+			}
+			if len(cols) != len(tc.wantNames) {
+				tt.Errorf("want %d collections, got %d", len(tc.wantNames), len(cols))
+			}
+			for _, name := range tc.wantNames {
+				if cols[name] == nil {
+					tt.Errorf("collection %q is missing", name)
+				}
+			}
+		})
+	}
+}
+
+func TestAnnotationModels(t *testing.T) {
+	res, err := chess.ParsePGN(strings.NewReader("1. e4 e5 2. Qh5 Ke7 3. Qxe5#"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := annotationModels(res); got != nil {
+		t.Errorf("not annotated: want nil, got %v", got)
+	}
+
+	res.NAGs = []chess.NAG{chess.NoNAG, chess.NoNAG, chess.NoNAG, chess.Blunder, chess.NoNAG}
+	res.BestMoves = make([]chess.Move, len(res.Moves))
+	res.BestMoves[3] = chess.Move{From: chess.MustNewSquareFromString("g8"), To: chess.MustNewSquareFromString("f6")}
+	got := annotationModels(res)
+	if len(got) != 1 {
+		t.Fatalf("want 1 annotation, got %d", len(got))
+	}
+	if a := *got[0]; a.Ply != 4 || a.Move != "2... Ke7" || a.Nag != "??" || a.Best != "Nf6" {
+		t.Errorf("want 2... Ke7?? (best is Nf6), got %+v", a)
+	}
+}
@@ -50,13 +50,93 @@ func init() {
                 "from-white"
               ],
               "properties": {
+                "arrows": {
+                  "description": "arrows to draw (e.g. \"e2e4\" or \"g1f3:red\"), the color is a name (green, red, blue or yellow) or \"#rrggbb[aa]\"",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "badges": {
+                  "description": "text badges on squares (e.g. \"e4:!!:blue\")",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "check-color": {
+                  "description": "color of the king in check highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
+                "collection": {
+                  "description": "name of a collection of images preloaded by the server (\"default\" by default)",
+                  "type": "string"
+                },
+                "coordinates": {
+                  "description": "draw the file letters and the rank numbers (\"none\", \"inside\" the edge squares or in a \"margin\" around the board)",
+                  "type": "string"
+                },
+                "fills": {
+                  "description": "squares to fill (e.g. \"d5:yellow\")",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "format": {
+                  "description": "format of the image (\"png\" by default or \"svg\")",
+                  "type": "string"
+                },
                 "from-white": {
                   "description": "visualize form white's persective",
                   "type": "boolean"
                 },
+                "heatmap": {
+                  "description": "tint the squares by the side that controls them",
+                  "type": "boolean"
+                },
+                "highlight": {
+                  "description": "highlight the last move and the king in check",
+                  "type": "boolean"
+                },
+                "highlight-color": {
+                  "description": "color of the last move highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
+                "last-move": {
+                  "description": "squares of the move that led to the position (e.g. \"e2e4\"), highlighted as with highlight",
+                  "type": "string"
+                },
+                "marks": {
+                  "description": "squares to circle (e.g. \"d5:red\")",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "motifs": {
+                  "description": "mark tactical motifs of the side to move (pins, forks, skewers, discovered attacks and hanging pieces) with arrows",
+                  "type": "boolean"
+                },
                 "notation": {
                   "description": "Chess position in FEN notation",
                   "type": "string"
+                },
+                "piece-set": {
+                  "description": "built-in piece set (\"default\" or \"vector\") drawn instead of the pieces of the collection",
+                  "type": "string"
+                },
+                "piece-url": {
+                  "description": "URL template of the piece images referenced from SVG images instead of embedding them, {color}, {kind} and {code} are replaced with e.g. \"white\", \"king\" and \"wK\"",
+                  "type": "string"
+                },
+                "size": {
+                  "description": "size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default",
+                  "type": "integer"
+                },
+                "theme": {
+                  "description": "board theme drawn instead of the board image (see /themes)",
+                  "type": "string"
                 }
               },
               "example": {
@@ -76,6 +156,72 @@ func init() {
         }
       }
     },
+    "/hint": {
+      "post": {
+        "summary": "Suggest a move in FEN position with the built-in engine",
+        "parameters": [
+          {
+            "description": "Move suggestion request",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "object",
+              "required": [
+                "notation",
+                "from-white"
+              ],
+              "properties": {
+                "collection": {
+                  "description": "name of a collection of images preloaded by the server (\"default\" by default)",
+                  "type": "string"
+                },
+                "depth": {
+                  "description": "Maximum search depth in plies",
+                  "type": "integer"
+                },
+                "from-white": {
+                  "description": "visualize form white's persective",
+                  "type": "boolean"
+                },
+                "movetime": {
+                  "description": "Maximum search time in milliseconds (1000 by default, at most 5000)",
+                  "type": "integer"
+                },
+                "notation": {
+                  "description": "Chess position in FEN notation",
+                  "type": "string"
+                },
+                "piece-set": {
+                  "description": "built-in piece set (\"default\" or \"vector\") drawn instead of the pieces of the collection",
+                  "type": "string"
+                },
+                "size": {
+                  "description": "size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default",
+                  "type": "integer"
+                },
+                "theme": {
+                  "description": "board theme drawn instead of the board image (see /themes)",
+                  "type": "string"
+                }
+              },
+              "example": {
+                "from-white": true,
+                "notation": "r1bqkbnr/pppp1ppp/2n5/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 2 3"
+              }
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "API call result",
+            "schema": {
+              "$ref": "#/definitions/ApiResult"
+            }
+          }
+        }
+      }
+    },
     "/pgn": {
       "post": {
         "summary": "Convert PGN game to GIF animation",
@@ -92,13 +238,69 @@ func init() {
                 "from-white"
               ],
               "properties": {
+                "annotate": {
+                  "description": "analyse the game with the built-in engine, mark inaccuracies, mistakes and blunders with the best moves and return them in annotations",
+                  "type": "boolean"
+                },
+                "caption": {
+                  "description": "draw a band under the board with the last move in SAN (e.g. \"23. Rxe6+\")",
+                  "type": "boolean"
+                },
+                "check-color": {
+                  "description": "color of the king in check highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
+                "collection": {
+                  "description": "name of a collection of images preloaded by the server (\"default\" by default)",
+                  "type": "string"
+                },
+                "coordinates": {
+                  "description": "draw the file letters and the rank numbers (\"none\", \"inside\" the edge squares or in a \"margin\" around the board)",
+                  "type": "string"
+                },
+                "eval-bar": {
+                  "description": "draw an evaluation bar beside the board (evaluations are taken from [%eval] comments)",
+                  "type": "boolean"
+                },
+                "eval-graph": {
+                  "description": "return a chart of the evaluation over the game in eval-graph",
+                  "type": "boolean"
+                },
                 "from-white": {
                   "description": "visualize form white's persective",
                   "type": "boolean"
                 },
+                "header": {
+                  "description": "draw a band over the board with the players, their ratings, the event and the date from the tags, and the opening (from the tags or classified by the moves)",
+                  "type": "boolean"
+                },
+                "heatmap": {
+                  "description": "tint the squares by the side that controls them",
+                  "type": "boolean"
+                },
+                "highlight": {
+                  "description": "highlight the last move and the king in check in every frame",
+                  "type": "boolean"
+                },
+                "highlight-color": {
+                  "description": "color of the last move highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
                 "notation": {
                   "description": "Chess game in PGN notation",
                   "type": "string"
+                },
+                "piece-set": {
+                  "description": "built-in piece set (\"default\" or \"vector\") drawn instead of the pieces of the collection",
+                  "type": "string"
+                },
+                "size": {
+                  "description": "size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default",
+                  "type": "integer"
+                },
+                "theme": {
+                  "description": "board theme drawn instead of the board image (see /themes)",
+                  "type": "string"
                 }
               },
               "example": {
@@ -117,6 +319,22 @@ func init() {
           }
         }
       }
+    },
+    "/themes": {
+      "get": {
+        "summary": "List the names of the built-in board themes",
+        "responses": {
+          "200": {
+            "description": "Names of the themes",
+            "schema": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            }
+          }
+        }
+      }
     }
   },
   "definitions": {
@@ -126,20 +344,100 @@ func init() {
         "ok"
       ],
       "properties": {
+        "alt-text": {
+          "description": "Description of the image in English for screen readers",
+          "type": "string"
+        },
+        "annotations": {
+          "description": "Inaccuracies, mistakes and blunders of the game (for annotated PGN requests)",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/MoveAnnotation"
+          },
+          "x-omitempty": true
+        },
         "error": {
           "description": "Human-readable description of an error",
           "type": "string"
         },
+        "eval": {
+          "description": "Evaluation of the position from white's point of view, e.g. 0.35 or",
+          "type": "string"
+        },
+        "eval-graph": {
+          "description": "PNG chart of the evaluation over the game in base64 encoding (if requested and available)",
+          "type": "string",
+          "format": "byte"
+        },
+        "move": {
+          "description": "Suggested move in SAN (for hint requests)",
+          "type": "string"
+        },
         "ok": {
           "description": "If ok is true, result is not empty, otherwise error is not empty",
           "type": "boolean"
         },
+        "parse-error": {
+          "$ref": "#/definitions/ParseError"
+        },
+        "partial": {
+          "description": "If partial is true, the game was rendered only up to an error in the notation (error is not empty)",
+          "type": "boolean"
+        },
         "result": {
           "description": "Result image in base64 encoding",
           "type": "string",
           "format": "byte"
         }
       }
+    },
+    "MoveAnnotation": {
+      "description": "Judgement of a move of an annotated game",
+      "type": "object",
+      "properties": {
+        "best": {
+          "description": "Best move in the position before the move in SAN",
+          "type": "string"
+        },
+        "move": {
+          "description": "Move in SAN with its number, e.g. 23... Rxe6",
+          "type": "string"
+        },
+        "nag": {
+          "description": "Judgement of the move: ?! (inaccuracy), ? (mistake) or ?? (blunder)",
+          "type": "string"
+        },
+        "ply": {
+          "description": "Index of the move in the game (starting from 1)",
+          "type": "integer"
+        }
+      }
+    },
+    "ParseError": {
+      "description": "Location of an error in the input notation",
+      "type": "object",
+      "properties": {
+        "column": {
+          "description": "Column number in characters (starting from 1)",
+          "type": "integer"
+        },
+        "line": {
+          "description": "Line number (starting from 1)",
+          "type": "integer"
+        },
+        "message": {
+          "description": "Description of the error without the location",
+          "type": "string"
+        },
+        "offset": {
+          "description": "Number of characters preceding the error in the input",
+          "type": "integer"
+        },
+        "snippet": {
+          "description": "Full line of the input that contains the error",
+          "type": "string"
+        }
+      }
     }
   }
 }`))
@@ -176,13 +474,93 @@ func init() {
                 "from-white"
               ],
               "properties": {
+                "arrows": {
+                  "description": "arrows to draw (e.g. \"e2e4\" or \"g1f3:red\"), the color is a name (green, red, blue or yellow) or \"#rrggbb[aa]\"",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "badges": {
+                  "description": "text badges on squares (e.g. \"e4:!!:blue\")",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "check-color": {
+                  "description": "color of the king in check highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
+                "collection": {
+                  "description": "name of a collection of images preloaded by the server (\"default\" by default)",
+                  "type": "string"
+                },
+                "coordinates": {
+                  "description": "draw the file letters and the rank numbers (\"none\", \"inside\" the edge squares or in a \"margin\" around the board)",
+                  "type": "string"
+                },
+                "fills": {
+                  "description": "squares to fill (e.g. \"d5:yellow\")",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "format": {
+                  "description": "format of the image (\"png\" by default or \"svg\")",
+                  "type": "string"
+                },
                 "from-white": {
                   "description": "visualize form white's persective",
                   "type": "boolean"
                 },
+                "heatmap": {
+                  "description": "tint the squares by the side that controls them",
+                  "type": "boolean"
+                },
+                "highlight": {
+                  "description": "highlight the last move and the king in check",
+                  "type": "boolean"
+                },
+                "highlight-color": {
+                  "description": "color of the last move highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
+                "last-move": {
+                  "description": "squares of the move that led to the position (e.g. \"e2e4\"), highlighted as with highlight",
+                  "type": "string"
+                },
+                "marks": {
+                  "description": "squares to circle (e.g. \"d5:red\")",
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                },
+                "motifs": {
+                  "description": "mark tactical motifs of the side to move (pins, forks, skewers, discovered attacks and hanging pieces) with arrows",
+                  "type": "boolean"
+                },
                 "notation": {
                   "description": "Chess position in FEN notation",
                   "type": "string"
+                },
+                "piece-set": {
+                  "description": "built-in piece set (\"default\" or \"vector\") drawn instead of the pieces of the collection",
+                  "type": "string"
+                },
+                "piece-url": {
+                  "description": "URL template of the piece images referenced from SVG images instead of embedding them, {color}, {kind} and {code} are replaced with e.g. \"white\", \"king\" and \"wK\"",
+                  "type": "string"
+                },
+                "size": {
+                  "description": "size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default",
+                  "type": "integer"
+                },
+                "theme": {
+                  "description": "board theme drawn instead of the board image (see /themes)",
+                  "type": "string"
                 }
               },
               "example": {
@@ -202,6 +580,72 @@ func init() {
         }
       }
     },
+    "/hint": {
+      "post": {
+        "summary": "Suggest a move in FEN position with the built-in engine",
+        "parameters": [
+          {
+            "description": "Move suggestion request",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "object",
+              "required": [
+                "notation",
+                "from-white"
+              ],
+              "properties": {
+                "collection": {
+                  "description": "name of a collection of images preloaded by the server (\"default\" by default)",
+                  "type": "string"
+                },
+                "depth": {
+                  "description": "Maximum search depth in plies",
+                  "type": "integer"
+                },
+                "from-white": {
+                  "description": "visualize form white's persective",
+                  "type": "boolean"
+                },
+                "movetime": {
+                  "description": "Maximum search time in milliseconds (1000 by default, at most 5000)",
+                  "type": "integer"
+                },
+                "notation": {
+                  "description": "Chess position in FEN notation",
+                  "type": "string"
+                },
+                "piece-set": {
+                  "description": "built-in piece set (\"default\" or \"vector\") drawn instead of the pieces of the collection",
+                  "type": "string"
+                },
+                "size": {
+                  "description": "size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default",
+                  "type": "integer"
+                },
+                "theme": {
+                  "description": "board theme drawn instead of the board image (see /themes)",
+                  "type": "string"
+                }
+              },
+              "example": {
+                "from-white": true,
+                "notation": "r1bqkbnr/pppp1ppp/2n5/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 2 3"
+              }
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "API call result",
+            "schema": {
+              "$ref": "#/definitions/ApiResult"
+            }
+          }
+        }
+      }
+    },
     "/pgn": {
       "post": {
         "summary": "Convert PGN game to GIF animation",
@@ -218,13 +662,69 @@ func init() {
                 "from-white"
               ],
               "properties": {
+                "annotate": {
+                  "description": "analyse the game with the built-in engine, mark inaccuracies, mistakes and blunders with the best moves and return them in annotations",
+                  "type": "boolean"
+                },
+                "caption": {
+                  "description": "draw a band under the board with the last move in SAN (e.g. \"23. Rxe6+\")",
+                  "type": "boolean"
+                },
+                "check-color": {
+                  "description": "color of the king in check highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
+                "collection": {
+                  "description": "name of a collection of images preloaded by the server (\"default\" by default)",
+                  "type": "string"
+                },
+                "coordinates": {
+                  "description": "draw the file letters and the rank numbers (\"none\", \"inside\" the edge squares or in a \"margin\" around the board)",
+                  "type": "string"
+                },
+                "eval-bar": {
+                  "description": "draw an evaluation bar beside the board (evaluations are taken from [%eval] comments)",
+                  "type": "boolean"
+                },
+                "eval-graph": {
+                  "description": "return a chart of the evaluation over the game in eval-graph",
+                  "type": "boolean"
+                },
                 "from-white": {
                   "description": "visualize form white's persective",
                   "type": "boolean"
                 },
+                "header": {
+                  "description": "draw a band over the board with the players, their ratings, the event and the date from the tags, and the opening (from the tags or classified by the moves)",
+                  "type": "boolean"
+                },
+                "heatmap": {
+                  "description": "tint the squares by the side that controls them",
+                  "type": "boolean"
+                },
+                "highlight": {
+                  "description": "highlight the last move and the king in check in every frame",
+                  "type": "boolean"
+                },
+                "highlight-color": {
+                  "description": "color of the last move highlight, a name (green, red, blue or yellow) or \"#rrggbb[aa]\" (by default the one of the theme)",
+                  "type": "string"
+                },
                 "notation": {
                   "description": "Chess game in PGN notation",
                   "type": "string"
+                },
+                "piece-set": {
+                  "description": "built-in piece set (\"default\" or \"vector\") drawn instead of the pieces of the collection",
+                  "type": "string"
+                },
+                "size": {
+                  "description": "size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default",
+                  "type": "integer"
+                },
+                "theme": {
+                  "description": "board theme drawn instead of the board image (see /themes)",
+                  "type": "string"
                 }
               },
               "example": {
@@ -243,6 +743,22 @@ func init() {
           }
         }
       }
+    },
+    "/themes": {
+      "get": {
+        "summary": "List the names of the built-in board themes",
+        "responses": {
+          "200": {
+            "description": "Names of the themes",
+            "schema": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            }
+          }
+        }
+      }
     }
   },
   "definitions": {
@@ -252,20 +768,100 @@ func init() {
         "ok"
       ],
       "properties": {
+        "alt-text": {
+          "description": "Description of the image in English for screen readers",
+          "type": "string"
+        },
+        "annotations": {
+          "description": "Inaccuracies, mistakes and blunders of the game (for annotated PGN requests)",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/MoveAnnotation"
+          },
+          "x-omitempty": true
+        },
         "error": {
           "description": "Human-readable description of an error",
           "type": "string"
         },
+        "eval": {
+          "description": "Evaluation of the position from white's point of view, e.g. 0.35 or",
+          "type": "string"
+        },
+        "eval-graph": {
+          "description": "PNG chart of the evaluation over the game in base64 encoding (if requested and available)",
+          "type": "string",
+          "format": "byte"
+        },
+        "move": {
+          "description": "Suggested move in SAN (for hint requests)",
+          "type": "string"
+        },
         "ok": {
           "description": "If ok is true, result is not empty, otherwise error is not empty",
           "type": "boolean"
         },
+        "parse-error": {
+          "$ref": "#/definitions/ParseError"
+        },
+        "partial": {
+          "description": "If partial is true, the game was rendered only up to an error in the notation (error is not empty)",
+          "type": "boolean"
+        },
         "result": {
           "description": "Result image in base64 encoding",
           "type": "string",
           "format": "byte"
         }
       }
+    },
+    "MoveAnnotation": {
+      "description": "Judgement of a move of an annotated game",
+      "type": "object",
+      "properties": {
+        "best": {
+          "description": "Best move in the position before the move in SAN",
+          "type": "string"
+        },
+        "move": {
+          "description": "Move in SAN with its number, e.g. 23... Rxe6",
+          "type": "string"
+        },
+        "nag": {
+          "description": "Judgement of the move: ?! (inaccuracy), ? (mistake) or ?? (blunder)",
+          "type": "string"
+        },
+        "ply": {
+          "description": "Index of the move in the game (starting from 1)",
+          "type": "integer"
+        }
+      }
+    },
+    "ParseError": {
+      "description": "Location of an error in the input notation",
+      "type": "object",
+      "properties": {
+        "column": {
+          "description": "Column number in characters (starting from 1)",
+          "type": "integer"
+        },
+        "line": {
+          "description": "Line number (starting from 1)",
+          "type": "integer"
+        },
+        "message": {
+          "description": "Description of the error without the location",
+          "type": "string"
+        },
+        "offset": {
+          "description": "Number of characters preceding the error in the input",
+          "type": "integer"
+        },
+        "snippet": {
+          "description": "Full line of the input that contains the error",
+          "type": "string"
+        }
+      }
     }
   }
 }`))
@@ -42,9 +42,15 @@ func NewChess2picAPIAPI(spec *loads.Document) *Chess2picAPIAPI {
 
 		JSONProducer: runtime.JSONProducer(),
 
+		GetThemesHandler: GetThemesHandlerFunc(func(params GetThemesParams) middleware.Responder {
+			return middleware.NotImplemented("operation GetThemes has not yet been implemented")
+		}),
 		PostFenHandler: PostFenHandlerFunc(func(params PostFenParams) middleware.Responder {
 			return middleware.NotImplemented("operation PostFen has not yet been implemented")
 		}),
+		PostHintHandler: PostHintHandlerFunc(func(params PostHintParams) middleware.Responder {
+			return middleware.NotImplemented("operation PostHint has not yet been implemented")
+		}),
 		PostPgnHandler: PostPgnHandlerFunc(func(params PostPgnParams) middleware.Responder {
 			return middleware.NotImplemented("operation PostPgn has not yet been implemented")
 		}),
@@ -84,8 +90,12 @@ type Chess2picAPIAPI struct {
 	//   - application/json
 	JSONProducer runtime.Producer
 
+	// GetThemesHandler sets the operation handler for the get themes operation
+	GetThemesHandler GetThemesHandler
 	// PostFenHandler sets the operation handler for the post fen operation
 	PostFenHandler PostFenHandler
+	// PostHintHandler sets the operation handler for the post hint operation
+	PostHintHandler PostHintHandler
 	// PostPgnHandler sets the operation handler for the post pgn operation
 	PostPgnHandler PostPgnHandler
 
@@ -165,9 +175,15 @@ func (o *Chess2picAPIAPI) Validate() error {
 		unregistered = append(unregistered, "JSONProducer")
 	}
 
+	if o.GetThemesHandler == nil {
+		unregistered = append(unregistered, "GetThemesHandler")
+	}
 	if o.PostFenHandler == nil {
 		unregistered = append(unregistered, "PostFenHandler")
 	}
+	if o.PostHintHandler == nil {
+		unregistered = append(unregistered, "PostHintHandler")
+	}
 	if o.PostPgnHandler == nil {
 		unregistered = append(unregistered, "PostPgnHandler")
 	}
@@ -259,6 +275,10 @@ func (o *Chess2picAPIAPI) initHandlerCache() {
 		o.handlers = make(map[string]map[string]http.Handler)
 	}
 
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/themes"] = NewGetThemes(o.context, o.GetThemesHandler)
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
@@ -266,6 +286,10 @@ func (o *Chess2picAPIAPI) initHandlerCache() {
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
+	o.handlers["POST"]["/hint"] = NewPostHint(o.context, o.PostHintHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
 	o.handlers["POST"]["/pgn"] = NewPostPgn(o.context, o.PostPgnHandler)
 }
 
@@ -66,13 +66,64 @@ func (o *PostFen) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 // swagger:model PostFenBody
 type PostFenBody struct {
 
+	// arrows to draw (e.g. "e2e4" or "g1f3:red"), the color is a name (green, red, blue or yellow) or "#rrggbb[aa]"
+	Arrows []string `json:"arrows"`
+
+	// text badges on squares (e.g. "e4:!!:blue")
+	Badges []string `json:"badges"`
+
+	// color of the king in check highlight, a name (green, red, blue or yellow) or "#rrggbb[aa]" (by default the one of the theme)
+	CheckColor string `json:"check-color,omitempty"`
+
+	// name of a collection of images preloaded by the server ("default" by default)
+	Collection string `json:"collection,omitempty"`
+
+	// draw the file letters and the rank numbers ("none", "inside" the edge squares or in a "margin" around the board)
+	Coordinates string `json:"coordinates,omitempty"`
+
+	// squares to fill (e.g. "d5:yellow")
+	Fills []string `json:"fills"`
+
+	// format of the image ("png" by default or "svg")
+	Format string `json:"format,omitempty"`
+
 	// visualize form white's persective
 	// Required: true
 	FromWhite *bool `json:"from-white"`
 
+	// tint the squares by the side that controls them
+	Heatmap bool `json:"heatmap,omitempty"`
+
+	// highlight the last move and the king in check
+	Highlight bool `json:"highlight,omitempty"`
+
+	// color of the last move highlight, a name (green, red, blue or yellow) or "#rrggbb[aa]" (by default the one of the theme)
+	HighlightColor string `json:"highlight-color,omitempty"`
+
+	// squares of the move that led to the position (e.g. "e2e4"), highlighted as with highlight
+	LastMove string `json:"last-move,omitempty"`
+
+	// squares to circle (e.g. "d5:red")
+	Marks []string `json:"marks"`
+
+	// mark tactical motifs of the side to move (pins, forks, skewers, discovered attacks and hanging pieces) with arrows
+	Motifs bool `json:"motifs,omitempty"`
+
 	// Chess position in FEN notation
 	// Required: true
 	Notation *string `json:"notation"`
+
+	// built-in piece set ("default" or "vector") drawn instead of the pieces of the collection
+	PieceSet string `json:"piece-set,omitempty"`
+
+	// URL template of the piece images referenced from SVG images instead of embedding them, {color}, {kind} and {code} are replaced with e.g. "white", "king" and "wK"
+	PieceURL string `json:"piece-url,omitempty"`
+
+	// size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default
+	Size int64 `json:"size,omitempty"`
+
+	// board theme drawn instead of the board image (see /themes)
+	Theme string `json:"theme,omitempty"`
 }
 
 // Validate validates this post fen body
@@ -66,13 +66,55 @@ func (o *PostPgn) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 // swagger:model PostPgnBody
 type PostPgnBody struct {
 
+	// analyse the game with the built-in engine, mark inaccuracies, mistakes and blunders with the best moves and return them in annotations
+	Annotate bool `json:"annotate,omitempty"`
+
+	// draw a band under the board with the last move in SAN (e.g. "23. Rxe6+")
+	Caption bool `json:"caption,omitempty"`
+
+	// color of the king in check highlight, a name (green, red, blue or yellow) or "#rrggbb[aa]" (by default the one of the theme)
+	CheckColor string `json:"check-color,omitempty"`
+
+	// name of a collection of images preloaded by the server ("default" by default)
+	Collection string `json:"collection,omitempty"`
+
+	// draw the file letters and the rank numbers ("none", "inside" the edge squares or in a "margin" around the board)
+	Coordinates string `json:"coordinates,omitempty"`
+
+	// draw an evaluation bar beside the board (evaluations are taken from [%eval] comments)
+	EvalBar bool `json:"eval-bar,omitempty"`
+
+	// return a chart of the evaluation over the game in eval-graph
+	EvalGraph bool `json:"eval-graph,omitempty"`
+
 	// visualize form white's persective
 	// Required: true
 	FromWhite *bool `json:"from-white"`
 
+	// draw a band over the board with the players, their ratings, the event and the date from the tags, and the opening (from the tags or classified by the moves)
+	Header bool `json:"header,omitempty"`
+
+	// tint the squares by the side that controls them
+	Heatmap bool `json:"heatmap,omitempty"`
+
+	// highlight the last move and the king in check in every frame
+	Highlight bool `json:"highlight,omitempty"`
+
+	// color of the last move highlight, a name (green, red, blue or yellow) or "#rrggbb[aa]" (by default the one of the theme)
+	HighlightColor string `json:"highlight-color,omitempty"`
+
 	// Chess game in PGN notation
 	// Required: true
 	Notation *string `json:"notation"`
+
+	// built-in piece set ("default" or "vector") drawn instead of the pieces of the collection
+	PieceSet string `json:"piece-set,omitempty"`
+
+	// size of the board in pixels (rounded down to a multiple of 8, at most 2048), the size of the board image by default
+	Size int64 `json:"size,omitempty"`
+
+	// board theme drawn instead of the board image (see /themes)
+	Theme string `json:"theme,omitempty"`
 }
 
 // Validate validates this post pgn body